@@ -0,0 +1,150 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const minPasswordLength = 8
+
+// maxPasswordLength is the most bytes bcrypt hashes, longer passwords are rejected by it.
+const maxPasswordLength = 72
+
+var (
+	ErrInvalidUsername    = errors.New("username must be 3 to 20 letters, digits, dashes or underscores")
+	ErrPasswordTooShort   = errors.New("password is too short")
+	ErrPasswordTooLong    = errors.New("password must be at most 72 bytes long")
+	ErrUsernameTaken      = errors.New("username is taken")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+var usernamePattern = regexp.MustCompile("^[a-zA-Z0-9_-]{3,20}$")
+
+type Account struct {
+	id           string
+	username     string
+	passwordHash []byte
+	bot          bool
+	createTime   time.Time
+}
+
+func (a *Account) Id() string {
+	return a.id
+}
+
+func (a *Account) Username() string {
+	return a.username
+}
+
+// IsBot tells whether the account is played by a program through the bot api.
+func (a *Account) IsBot() bool {
+	return a.bot
+}
+
+func (a *Account) CreateTime() time.Time {
+	return a.createTime
+}
+
+type Store struct {
+	accounts   map[string]*Account
+	byUsername map[string]*Account
+	mutex      sync.RWMutex
+}
+
+func NewStore() *Store {
+	return &Store{
+		accounts:   make(map[string]*Account),
+		byUsername: make(map[string]*Account),
+	}
+}
+
+// Register creates an account. Usernames are unique regardless of case.
+func (s *Store) Register(username string, password string, bot bool) (*Account, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+
+	if len(password) < minPasswordLength {
+		return nil, ErrPasswordTooShort
+	}
+
+	if len(password) > maxPasswordLength {
+		return nil, ErrPasswordTooLong
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := strings.ToLower(username)
+	if _, ok := s.byUsername[key]; ok {
+		return nil, ErrUsernameTaken
+	}
+
+	account := &Account{
+		id:           uuid.NewString(),
+		username:     username,
+		passwordHash: passwordHash,
+		bot:          bot,
+		createTime:   time.Now(),
+	}
+
+	s.accounts[account.id] = account
+	s.byUsername[key] = account
+
+	return account, nil
+}
+
+func (s *Store) Authenticate(username string, password string) (*Account, error) {
+	s.mutex.RLock()
+	account, ok := s.byUsername[strings.ToLower(username)]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if bcrypt.CompareHashAndPassword(account.passwordHash, []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return account, nil
+}
+
+func (s *Store) GetAccount(id string) *Account {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.accounts[id]
+}
+
+// CheckGuestName tells why a guest can not play under the name: guest names follow the rules for usernames
+// and must not be the username of an account, regardless of case.
+func (s *Store) CheckGuestName(name string) error {
+	if !usernamePattern.MatchString(name) {
+		return ErrInvalidUsername
+	}
+
+	if s.GetAccountByUsername(name) != nil {
+		return ErrUsernameTaken
+	}
+
+	return nil
+}
+
+// GetAccountByUsername finds an account regardless of the case of the username.
+func (s *Store) GetAccountByUsername(username string) *Account {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.byUsername[strings.ToLower(username)]
+}
@@ -0,0 +1,256 @@
+package analysis
+
+import (
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sync"
+	"time"
+)
+
+var ErrGameNotOver = errors.New("only finished games can be analysed")
+
+const (
+	// evaluationCap limits the evaluations used for the centipawn loss, so missing a mate in a won position
+	// does not dominate the average
+	evaluationCap = 1000
+
+	mateCentipawns = 10000
+
+	inaccuracyLoss = 50
+	mistakeLoss    = 100
+	blunderLoss    = 300
+)
+
+// Evaluation is the engine score of a position from the view of white. Forced mates are scored as mateCentipawns
+// and their Mate is the number of moves to the mate, positive if white mates and 0 if the position is checkmate.
+type Evaluation struct {
+	centipawns int
+	mate       int
+	isMate     bool
+}
+
+func (e Evaluation) Centipawns() int {
+	return e.centipawns
+}
+
+func (e Evaluation) Mate() int {
+	return e.mate
+}
+
+func (e Evaluation) IsMate() bool {
+	return e.isMate
+}
+
+// Ply is an analysed move: the evaluation after it, what the engine would have played instead and how much worse
+// the move was.
+type Ply struct {
+	number         int
+	color          int
+	move           string
+	bestMove       string
+	evaluation     Evaluation
+	loss           int
+	classification int
+}
+
+func (p Ply) Number() int {
+	return p.number
+}
+
+func (p Ply) Color() int {
+	return p.color
+}
+
+func (p Ply) Move() string {
+	return p.move
+}
+
+func (p Ply) BestMove() string {
+	return p.bestMove
+}
+
+func (p Ply) Evaluation() Evaluation {
+	return p.evaluation
+}
+
+// Loss is the centipawn loss of the move compared to the best move.
+func (p Ply) Loss() int {
+	return p.loss
+}
+
+func (p Ply) Classification() int {
+	return p.classification
+}
+
+// Summary describes how accurately a player played.
+type Summary struct {
+	averageLoss  int
+	inaccuracies int
+	mistakes     int
+	blunders     int
+}
+
+func (s Summary) AverageLoss() int {
+	return s.averageLoss
+}
+
+func (s Summary) Inaccuracies() int {
+	return s.inaccuracies
+}
+
+func (s Summary) Mistakes() int {
+	return s.mistakes
+}
+
+func (s Summary) Blunders() int {
+	return s.blunders
+}
+
+type Analysis struct {
+	gameId     game.Id
+	status     int
+	totalPlies int
+	plies      []Ply
+	createTime time.Time
+
+	mutex   sync.RWMutex
+	manager *Manager
+}
+
+func (a *Analysis) GameId() game.Id {
+	return a.gameId
+}
+
+func (a *Analysis) CreateTime() time.Time {
+	return a.createTime
+}
+
+func (a *Analysis) Status() int {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status
+}
+
+func (a *Analysis) TotalPlies() int {
+	return a.totalPlies
+}
+
+func (a *Analysis) AnalyzedPlies() int {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return len(a.plies)
+}
+
+func (a *Analysis) Plies() []Ply {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return append([]Ply{}, a.plies...)
+}
+
+// LastPly returns the ply analysed most recently, or nil before the first one is done.
+func (a *Analysis) LastPly() *Ply {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if len(a.plies) == 0 {
+		return nil
+	}
+
+	ply := a.plies[len(a.plies)-1]
+
+	return &ply
+}
+
+// Summary sums up the plies of the given color that were analysed so far.
+func (a *Analysis) Summary(color int) Summary {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	var summary Summary
+	totalLoss := 0
+	count := 0
+
+	for _, ply := range a.plies {
+		if ply.color != color {
+			continue
+		}
+
+		totalLoss += ply.loss
+		count++
+
+		switch ply.classification {
+		case constants.Inaccuracy:
+			summary.inaccuracies++
+		case constants.Mistake:
+			summary.mistakes++
+		case constants.Blunder:
+			summary.blunders++
+		}
+	}
+
+	if count > 0 {
+		summary.averageLoss = (totalLoss + count/2) / count
+	}
+
+	return summary
+}
+
+func (a *Analysis) setStatus(status int) {
+	a.mutex.Lock()
+	a.status = status
+	a.mutex.Unlock()
+
+	a.notifyUpdated()
+}
+
+func (a *Analysis) addPly(ply Ply) {
+	a.mutex.Lock()
+	a.plies = append(a.plies, ply)
+	a.mutex.Unlock()
+
+	a.notifyUpdated()
+}
+
+// moveLoss compares the evaluations before and after a move from the view of the player who moved.
+func moveLoss(color int, before Evaluation, after Evaluation) int {
+	sign := 1
+	if color == constants.Black {
+		sign = -1
+	}
+
+	loss := capEvaluation(before.centipawns*sign) - capEvaluation(after.centipawns*sign)
+	if loss < 0 {
+		return 0
+	}
+
+	return loss
+}
+
+func capEvaluation(centipawns int) int {
+	if centipawns > evaluationCap {
+		return evaluationCap
+	}
+
+	if centipawns < -evaluationCap {
+		return -evaluationCap
+	}
+
+	return centipawns
+}
+
+func classify(loss int) int {
+	switch {
+	case loss >= blunderLoss:
+		return constants.Blunder
+	case loss >= mistakeLoss:
+		return constants.Mistake
+	case loss >= inaccuracyLoss:
+		return constants.Inaccuracy
+	}
+
+	return constants.GoodMove
+}
@@ -0,0 +1,15 @@
+package analysis
+
+type AnalysisListener func(analysis *Analysis)
+
+// OnAnalysisUpdated registers a listener that is called when an analysis starts, after every analysed ply and
+// when it is finished or failed. It is called without holding the lock of the analysis.
+func (m *Manager) OnAnalysisUpdated(listener AnalysisListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (a *Analysis) notifyUpdated() {
+	for _, listener := range a.manager.listeners {
+		listener(a)
+	}
+}
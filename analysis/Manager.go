@@ -0,0 +1,180 @@
+package analysis
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/engines"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/history"
+	"sync"
+	"time"
+)
+
+// limits are how long the engine thinks about every position of a game
+var limits = engines.Limits{
+	Skill:    engines.MaxSkill,
+	Depth:    12,
+	MoveTime: 300 * time.Millisecond,
+}
+
+// maxRunningAnalyses bounds how many games are analysed at the same time, the others wait in the queue.
+const maxRunningAnalyses = 2
+
+type Manager struct {
+	engine    *engines.NativeEngine
+	analyses  map[game.Id]*Analysis
+	listeners []AnalysisListener
+	workers   chan struct{}
+	mutex     sync.RWMutex
+}
+
+func NewManager(engine *engines.NativeEngine) *Manager {
+	return &Manager{
+		engine:   engine,
+		analyses: make(map[game.Id]*Analysis),
+		workers:  make(chan struct{}, maxRunningAnalyses),
+	}
+}
+
+func (m *Manager) GetAnalysis(id game.Id) *Analysis {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.analyses[id]
+}
+
+// Request queues the analysis of a finished game. A game is only analysed once, so if it was requested before
+// the existing analysis is returned and created is false.
+func (m *Manager) Request(g *game.Game) (analysis *Analysis, created bool, err error) {
+	if !g.IsOver() {
+		return nil, false, ErrGameNotOver
+	}
+
+	m.mutex.Lock()
+	if existing, ok := m.analyses[g.Id()]; ok {
+		m.mutex.Unlock()
+		return existing, false, nil
+	}
+
+	moves := g.History()
+
+	analysis = &Analysis{
+		gameId:     g.Id(),
+		status:     constants.AnalysisQueued,
+		totalPlies: len(moves),
+		createTime: time.Now(),
+		manager:    m,
+	}
+	m.analyses[g.Id()] = analysis
+	m.mutex.Unlock()
+
+	go m.run(analysis, g.Positions(), moves)
+
+	return analysis, true, nil
+}
+
+// Remove drops the analysis of a game that was removed.
+func (m *Manager) Remove(g *game.Game) {
+	m.mutex.Lock()
+	delete(m.analyses, g.Id())
+	m.mutex.Unlock()
+}
+
+// run evaluates every position of the game. Once the position after a move is evaluated, the move is compared
+// to the best move in the position before it.
+func (m *Manager) run(analysis *Analysis, positions []string, moves []game.Move) {
+	m.workers <- struct{}{}
+	defer func() { <-m.workers }()
+
+	if len(moves) == 0 {
+		analysis.setStatus(constants.AnalysisFinished)
+		return
+	}
+
+	analysis.setStatus(constants.AnalysisRunning)
+
+	var before Evaluation
+	var bestMove string
+
+	for i, position := range positions {
+		result, err := m.engine.Analyze(position, limits)
+
+		var evaluation Evaluation
+		switch {
+		case err == engines.ErrNoMove:
+			evaluation = terminalEvaluation(moves, i)
+		case err != nil:
+			analysis.setStatus(constants.AnalysisFailed)
+			return
+		default:
+			evaluation = asWhiteEvaluation(result, sideToMove(moves, i))
+		}
+
+		if i > 0 {
+			move := moves[i-1]
+			played := history.MoveAsCoordinates(move)
+
+			loss := 0
+			if played != bestMove {
+				loss = moveLoss(move.Color(), before, evaluation)
+			}
+
+			analysis.addPly(Ply{
+				number:         i,
+				color:          move.Color(),
+				move:           played,
+				bestMove:       bestMove,
+				evaluation:     evaluation,
+				loss:           loss,
+				classification: classify(loss),
+			})
+		}
+
+		before = evaluation
+		bestMove = ""
+		if err == nil {
+			bestMove = result.BestMove.Coordinates()
+		}
+	}
+
+	analysis.setStatus(constants.AnalysisFinished)
+}
+
+func sideToMove(moves []game.Move, position int) int {
+	if position < len(moves) {
+		return moves[position].Color()
+	}
+
+	return constants.GetOppositeColor(moves[len(moves)-1].Color())
+}
+
+// asWhiteEvaluation turns the score of the engine, which is from the view of the side to move, to the view of white.
+func asWhiteEvaluation(result engines.Analysis, color int) Evaluation {
+	sign := 1
+	if color == constants.Black {
+		sign = -1
+	}
+
+	if result.Mate != 0 {
+		centipawns := mateCentipawns
+		if result.Mate < 0 {
+			centipawns = -mateCentipawns
+		}
+
+		return Evaluation{centipawns: centipawns * sign, mate: result.Mate * sign, isMate: true}
+	}
+
+	return Evaluation{centipawns: result.Score * sign}
+}
+
+// terminalEvaluation scores a position without legal moves, which is either checkmate or stalemate.
+func terminalEvaluation(moves []game.Move, position int) Evaluation {
+	if position == 0 || moves[position-1].Status() != constants.IsCheckmate {
+		return Evaluation{}
+	}
+
+	if moves[position-1].Color() == constants.White {
+		return Evaluation{centipawns: mateCentipawns, isMate: true}
+	}
+
+	return Evaluation{centipawns: -mateCentipawns, isMate: true}
+}
@@ -0,0 +1,417 @@
+package arenas
+
+import (
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrArenaOver     = errors.New("the arena is already over")
+	ErrAlreadyJoined = errors.New("the player already joined")
+	ErrNotJoined     = errors.New("the player did not join")
+)
+
+const (
+	winPoints  = 2
+	drawPoints = 1
+
+	// a player who won the last two games is on fire and scores double until they do not win
+	fireStreak = 2
+
+	// a berserk player gets a bonus point for a win in which they played at least this many moves
+	berserkMoves = 7
+)
+
+type Id string
+
+// Settings describe an arena when it is created.
+type Settings struct {
+	Name        string
+	TimeControl game.TimeControl
+	Rated       bool
+	StartTime   time.Time
+	Duration    time.Duration
+	OwnerId     string
+}
+
+// Arena is a tournament of fixed duration. Players who are not playing are paired again right away and
+// score points for their results, with bonuses for streaks and berserk wins.
+type Arena struct {
+	id       Id
+	settings Settings
+
+	status     int
+	createTime time.Time
+
+	players []*Player
+	games   map[game.Id]*pairing
+
+	manager *Manager
+	mutex   sync.Mutex
+}
+
+// Player is a participant of an arena. A paused player keeps their score but is not paired anymore.
+type Player struct {
+	id     string
+	name   string
+	rating int
+	score  int
+	streak int
+	paused bool
+
+	results      []Result
+	gameId       game.Id
+	lastOpponent *Player
+	colorBalance int // white games minus black games
+}
+
+// Result is the outcome of one arena game from the view of a player.
+type Result struct {
+	Score   float64 // 1 for a win, 0.5 for a draw and 0 for a loss
+	Points  int
+	Fire    bool
+	Berserk bool // the player halved their clock, which is worth a point with a win
+}
+
+type pairing struct {
+	white *Player
+	black *Player
+}
+
+// Standing is the place of a player on the leaderboard.
+type Standing struct {
+	Rank     int
+	PlayerId string
+	Name     string
+	Rating   int
+	Score    int
+	Games    int
+	Wins     int
+	OnFire   bool
+	Paused   bool
+	GameId   game.Id // the game the player is playing right now, if any
+	Results  []Result
+}
+
+func (a *Arena) Id() Id {
+	return a.id
+}
+
+func (a *Arena) Name() string {
+	return a.settings.Name
+}
+
+func (a *Arena) TimeControl() game.TimeControl {
+	return a.settings.TimeControl
+}
+
+func (a *Arena) IsRated() bool {
+	return a.settings.Rated
+}
+
+func (a *Arena) OwnerId() string {
+	return a.settings.OwnerId
+}
+
+func (a *Arena) StartTime() time.Time {
+	return a.settings.StartTime
+}
+
+func (a *Arena) EndTime() time.Time {
+	return a.settings.StartTime.Add(a.settings.Duration)
+}
+
+func (a *Arena) CreateTime() time.Time {
+	return a.createTime
+}
+
+func (a *Arena) Status() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.status
+}
+
+func (a *Arena) PlayerCount() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return len(a.players)
+}
+
+// Join adds a player or resumes a paused one. Players can join until the arena is over.
+func (a *Arena) Join(playerId string, name string, rating int) error {
+	a.mutex.Lock()
+	err := a.join(playerId, name, rating)
+	a.mutex.Unlock()
+
+	if err == nil {
+		a.notifyUpdated()
+	}
+
+	return err
+}
+
+func (a *Arena) join(playerId string, name string, rating int) error {
+	if a.status == constants.Finished {
+		return ErrArenaOver
+	}
+
+	if player := a.player(playerId); player != nil {
+		if !player.paused {
+			return ErrAlreadyJoined
+		}
+
+		player.paused = false
+		return nil
+	}
+
+	a.players = append(a.players, &Player{
+		id:     playerId,
+		name:   name,
+		rating: rating,
+	})
+
+	return nil
+}
+
+// Withdraw pauses a player. A game the player is still playing is not affected.
+func (a *Arena) Withdraw(playerId string) error {
+	a.mutex.Lock()
+	err := a.withdraw(playerId)
+	a.mutex.Unlock()
+
+	if err == nil {
+		a.notifyUpdated()
+	}
+
+	return err
+}
+
+func (a *Arena) withdraw(playerId string) error {
+	player := a.player(playerId)
+	if player == nil || player.paused {
+		return ErrNotJoined
+	}
+
+	if a.status == constants.Finished {
+		return ErrArenaOver
+	}
+
+	player.paused = true
+
+	return nil
+}
+
+// Leaderboard ranks the players by score, then by rating.
+func (a *Arena) Leaderboard() []Standing {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	standings := make([]Standing, len(a.players))
+	for i, player := range a.players {
+		standings[i] = Standing{
+			PlayerId: player.id,
+			Name:     player.name,
+			Rating:   player.rating,
+			Score:    player.score,
+			Games:    len(player.results),
+			OnFire:   player.streak >= fireStreak,
+			Paused:   player.paused,
+			GameId:   player.gameId,
+			Results:  append([]Result(nil), player.results...),
+		}
+
+		for _, result := range player.results {
+			if result.Score == 1 {
+				standings[i].Wins++
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+
+		return standings[i].Rating > standings[j].Rating
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+
+	return standings
+}
+
+func (a *Arena) player(playerId string) *Player {
+	for _, player := range a.players {
+		if player.id == playerId {
+			return player
+		}
+	}
+
+	return nil
+}
+
+// update starts or finishes the arena when it is time and pairs the waiting players.
+// It reports whether anything changed.
+func (a *Arena) update(now time.Time) bool {
+	switch {
+	case a.status == constants.Registration && !now.Before(a.settings.StartTime):
+		a.status = constants.Running
+		a.pairWaiting()
+		return true
+	case a.status == constants.Running && !now.Before(a.EndTime()):
+		a.finish()
+		return true
+	case a.status == constants.Running:
+		return a.pairWaiting()
+	}
+
+	return false
+}
+
+// pairWaiting pairs the players who are not playing, those with similar scores first. Players do not meet
+// the opponent of their last game again, unless nobody else is there.
+func (a *Arena) pairWaiting() bool {
+	var waiting []*Player
+	active := 0
+
+	for _, player := range a.players {
+		if player.paused {
+			continue
+		}
+
+		active++
+		if player.gameId == "" {
+			waiting = append(waiting, player)
+		}
+	}
+
+	sort.SliceStable(waiting, func(i, j int) bool {
+		if waiting[i].score != waiting[j].score {
+			return waiting[i].score > waiting[j].score
+		}
+
+		return waiting[i].rating > waiting[j].rating
+	})
+
+	paired := false
+
+	for len(waiting) >= 2 {
+		first := waiting[0]
+
+		opponent := -1
+		for i := 1; i < len(waiting); i++ {
+			if active <= 2 || waiting[i] != first.lastOpponent {
+				opponent = i
+				break
+			}
+		}
+
+		if opponent == -1 {
+			waiting = waiting[1:]
+			continue
+		}
+
+		a.createGame(first, waiting[opponent])
+		paired = true
+
+		waiting = append(waiting[1:opponent], waiting[opponent+1:]...)
+	}
+
+	return paired
+}
+
+// createGame starts a game between two players. The player who had white less often gets white.
+func (a *Arena) createGame(first *Player, second *Player) {
+	white, black := first, second
+	if second.colorBalance < first.colorBalance {
+		white, black = second, first
+	}
+
+	arenaGame := a.manager.games.NewGame(game.Settings{
+		FirstPlayerColor: constants.White,
+		StartingColor:    constants.White,
+		TimeControl:      a.settings.TimeControl,
+		Rated:            a.settings.Rated,
+		AllowBerserk:     true,
+	})
+
+	// the first player gets FirstPlayerColor, the second one the other color
+	arenaGame.AddPlayer(white.name, white.id, "")
+	arenaGame.AddPlayer(black.name, black.id, "")
+
+	white.gameId, black.gameId = arenaGame.Id(), arenaGame.Id()
+	white.lastOpponent, black.lastOpponent = black, white
+	white.colorBalance++
+	black.colorBalance--
+
+	a.games[arenaGame.Id()] = &pairing{white: white, black: black}
+}
+
+// recordResult scores a finished game. Games that end after the arena are not scored.
+func (a *Arena) recordResult(pairing *pairing, g *game.Game) {
+	a.release(pairing, g.Id())
+
+	if a.status != constants.Running {
+		return
+	}
+
+	whiteScore := 0.5
+	switch g.Result() {
+	case constants.WhiteWon:
+		whiteScore = 1
+	case constants.BlackWon:
+		whiteScore = 0
+	}
+
+	scoreGame(pairing.white, g, constants.White, whiteScore)
+	scoreGame(pairing.black, g, constants.Black, 1-whiteScore)
+}
+
+// release frees the players of a game, so they are paired again.
+func (a *Arena) release(pairing *pairing, gameId game.Id) {
+	delete(a.games, gameId)
+	pairing.white.gameId, pairing.black.gameId = "", ""
+}
+
+// scoreGame adds the points of a game to the player. Wins and draws are worth double while the player is on fire.
+func scoreGame(player *Player, g *game.Game, color int, score float64) {
+	result := Result{
+		Score:   score,
+		Fire:    player.streak >= fireStreak,
+		Berserk: g.IsBerserk(color),
+	}
+
+	switch score {
+	case 1:
+		result.Points = winPoints
+		player.streak++
+	case 0.5:
+		result.Points = drawPoints
+		player.streak = 0
+	default:
+		player.streak = 0
+	}
+
+	if result.Fire {
+		result.Points *= 2
+	}
+
+	if result.Berserk && score == 1 && g.MoveCount(color) >= berserkMoves {
+		result.Points++
+	}
+
+	player.score += result.Points
+	player.results = append(player.results, result)
+}
+
+func (a *Arena) finish() {
+	a.status = constants.Finished
+}
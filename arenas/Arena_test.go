@@ -0,0 +1,228 @@
+package arenas
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"testing"
+	"time"
+)
+
+// newScoredGame returns a game in which each color played the given number of moves, shuffling a knight
+// back and forth. With berserk white halved their clock before the first move.
+func newScoredGame(t *testing.T, berserk bool, moves int) *game.Game {
+	t.Helper()
+
+	g := game.NewGameManager().NewGame(game.Settings{
+		FirstPlayerColor: constants.White,
+		StartingColor:    constants.White,
+		TimeControl:      game.NewTimeControl(3*time.Minute, 0),
+		AllowBerserk:     true,
+	})
+	g.AddPlayer("White", "white", "")
+	g.AddPlayer("Black", "black", "")
+
+	if berserk && !g.Berserk(constants.White) {
+		t.Fatal("berserk was rejected")
+	}
+
+	for i := 0; i < moves; i++ {
+		whiteFrom, whiteTo := [2]int{6, 0}, [2]int{5, 2}
+		blackFrom, blackTo := [2]int{1, 7}, [2]int{2, 5}
+		if i%2 == 1 {
+			whiteFrom, whiteTo = whiteTo, whiteFrom
+			blackFrom, blackTo = blackTo, blackFrom
+		}
+
+		if move, reason := g.Move(constants.White, whiteFrom[0], whiteFrom[1], whiteTo[0], whiteTo[1], nil); move == nil {
+			t.Fatalf("white move %d was rejected: %s", i+1, constants.MoveErrorAsString(reason))
+		}
+
+		if move, reason := g.Move(constants.Black, blackFrom[0], blackFrom[1], blackTo[0], blackTo[1], nil); move == nil {
+			t.Fatalf("black move %d was rejected: %s", i+1, constants.MoveErrorAsString(reason))
+		}
+	}
+
+	return g
+}
+
+func TestScoreGame(t *testing.T) {
+	type scoredGame struct {
+		score   float64
+		berserk bool
+		moves   int
+	}
+
+	win := scoredGame{score: 1}
+	draw := scoredGame{score: 0.5}
+	loss := scoredGame{score: 0}
+
+	tests := []struct {
+		name       string
+		games      []scoredGame
+		wantPoints []int
+		wantFire   []bool
+		wantStreak int
+	}{
+		{
+			name:       "wins, draws and losses",
+			games:      []scoredGame{win, loss, draw},
+			wantPoints: []int{2, 0, 1},
+			wantFire:   []bool{false, false, false},
+		},
+		{
+			name:       "two wins set the player on fire",
+			games:      []scoredGame{win, win, win, win},
+			wantPoints: []int{2, 2, 4, 4},
+			wantFire:   []bool{false, false, true, true},
+			wantStreak: 4,
+		},
+		{
+			name:       "a draw on fire is worth double and ends the streak",
+			games:      []scoredGame{win, win, draw, win},
+			wantPoints: []int{2, 2, 2, 2},
+			wantFire:   []bool{false, false, true, false},
+			wantStreak: 1,
+		},
+		{
+			name:       "a loss ends the streak",
+			games:      []scoredGame{win, win, loss, win, win, win},
+			wantPoints: []int{2, 2, 0, 2, 2, 4},
+			wantFire:   []bool{false, false, true, false, false, true},
+			wantStreak: 3,
+		},
+		{
+			name:       "draws do not start a streak",
+			games:      []scoredGame{draw, draw, draw},
+			wantPoints: []int{1, 1, 1},
+			wantFire:   []bool{false, false, false},
+		},
+		{
+			name:       "berserk win with enough moves",
+			games:      []scoredGame{{score: 1, berserk: true, moves: berserkMoves}},
+			wantPoints: []int{3},
+			wantFire:   []bool{false},
+			wantStreak: 1,
+		},
+		{
+			name:       "berserk win with too few moves",
+			games:      []scoredGame{{score: 1, berserk: true, moves: berserkMoves - 1}},
+			wantPoints: []int{2},
+			wantFire:   []bool{false},
+			wantStreak: 1,
+		},
+		{
+			name:       "berserk draw",
+			games:      []scoredGame{{score: 0.5, berserk: true, moves: berserkMoves}},
+			wantPoints: []int{1},
+			wantFire:   []bool{false},
+		},
+		{
+			name:       "berserk bonus is added after doubling",
+			games:      []scoredGame{win, win, {score: 1, berserk: true, moves: berserkMoves}},
+			wantPoints: []int{2, 2, 5},
+			wantFire:   []bool{false, false, true},
+			wantStreak: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			player := &Player{id: "white", name: "White"}
+
+			for _, scored := range test.games {
+				scoreGame(player, newScoredGame(t, scored.berserk, scored.moves), constants.White, scored.score)
+			}
+
+			if len(player.results) != len(test.games) {
+				t.Fatalf("%d results, want %d", len(player.results), len(test.games))
+			}
+
+			total := 0
+
+			for i, result := range player.results {
+				total += result.Points
+
+				if result.Points != test.wantPoints[i] {
+					t.Errorf("game %d: points = %d, want %d", i+1, result.Points, test.wantPoints[i])
+				}
+
+				if result.Fire != test.wantFire[i] {
+					t.Errorf("game %d: fire = %v, want %v", i+1, result.Fire, test.wantFire[i])
+				}
+			}
+
+			if player.score != total {
+				t.Errorf("score = %d, want the sum of the points %d", player.score, total)
+			}
+
+			if player.streak != test.wantStreak {
+				t.Errorf("streak = %d, want %d", player.streak, test.wantStreak)
+			}
+		})
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	arena := &Arena{players: []*Player{
+		{id: "a", name: "A", rating: 1500, score: 4, streak: 1},
+		{id: "b", name: "B", rating: 1700, score: 6, streak: fireStreak},
+		{id: "c", name: "C", rating: 1600, score: 4},
+	}}
+
+	tests := []struct {
+		rank   int
+		id     string
+		onFire bool
+	}{
+		{rank: 1, id: "b", onFire: true},
+		{rank: 2, id: "c", onFire: false},
+		{rank: 3, id: "a", onFire: false},
+	}
+
+	standings := arena.Leaderboard()
+	if len(standings) != len(tests) {
+		t.Fatalf("%d standings, want %d", len(standings), len(tests))
+	}
+
+	for i, test := range tests {
+		standing := standings[i]
+
+		if standing.Rank != test.rank || standing.PlayerId != test.id || standing.OnFire != test.onFire {
+			t.Errorf("standing %d = rank %d, %s, on fire %v, want rank %d, %s, on fire %v", i+1,
+				standing.Rank, standing.PlayerId, standing.OnFire, test.rank, test.id, test.onFire)
+		}
+	}
+}
+
+func TestRemovedGameReleasesPlayers(t *testing.T) {
+	games := game.NewGameManager()
+	m := NewManager(games)
+
+	arena := m.NewArena(Settings{
+		TimeControl: game.NewTimeControl(3*time.Minute, 0),
+		StartTime:   time.Now(),
+		Duration:    time.Hour,
+	})
+	arena.Join("a", "A", 1500)
+	arena.Join("b", "B", 1500)
+	m.Tick()
+
+	removed := arena.Leaderboard()[0].GameId
+	if removed == "" {
+		t.Fatal("the players were not paired")
+	}
+
+	m.HandleGameRemoved(games.GetGame(removed))
+
+	for _, standing := range arena.Leaderboard() {
+		if standing.GameId != "" || standing.Games != 0 {
+			t.Errorf("%s is in game %q with %d games, want a waiting player without games", standing.PlayerId, standing.GameId, standing.Games)
+		}
+	}
+
+	m.Tick()
+
+	if gameId := arena.Leaderboard()[0].GameId; gameId == "" || gameId == removed {
+		t.Error("the players were not paired again")
+	}
+}
@@ -0,0 +1,15 @@
+package arenas
+
+type ArenaListener func(arena *Arena)
+
+// OnArenaUpdated registers a listener that is called after the arena started or finished, players joined
+// or paused, games were paired or a result was scored. It is called without holding the lock of the arena.
+func (m *Manager) OnArenaUpdated(listener ArenaListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (a *Arena) notifyUpdated() {
+	for _, listener := range a.manager.listeners {
+		listener(a)
+	}
+}
@@ -0,0 +1,127 @@
+package arenas
+
+import (
+	"github.com/racccoooon/chess-be/game"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Manager struct {
+	games     *game.Manager
+	arenas    map[Id]*Arena
+	listeners []ArenaListener
+	mutex     sync.RWMutex
+}
+
+func NewManager(games *game.Manager) *Manager {
+	return &Manager{
+		games:  games,
+		arenas: make(map[Id]*Arena),
+	}
+}
+
+func (m *Manager) NewArena(settings Settings) *Arena {
+	arena := &Arena{
+		settings:   settings,
+		createTime: time.Now(),
+		games:      make(map[game.Id]*pairing),
+		manager:    m,
+	}
+
+	m.mutex.Lock()
+	arena.id = m.newArenaId()
+	m.arenas[arena.id] = arena
+	m.mutex.Unlock()
+
+	return arena
+}
+
+func (m *Manager) GetArena(id Id) *Arena {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.arenas[id]
+}
+
+// GetArenas returns all arenas, the ones that start first first.
+func (m *Manager) GetArenas() []*Arena {
+	m.mutex.RLock()
+	arenas := make([]*Arena, 0, len(m.arenas))
+	for _, arena := range m.arenas {
+		arenas = append(arenas, arena)
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(arenas, func(i, j int) bool {
+		return arenas[i].settings.StartTime.Before(arenas[j].settings.StartTime)
+	})
+
+	return arenas
+}
+
+// Tick starts and finishes arenas on time and pairs the players who are waiting for a game.
+// It is meant to be called regularly.
+func (m *Manager) Tick() {
+	now := time.Now()
+
+	for _, arena := range m.GetArenas() {
+		arena.mutex.Lock()
+		changed := arena.update(now)
+		arena.mutex.Unlock()
+
+		if changed {
+			arena.notifyUpdated()
+		}
+	}
+}
+
+// RecordResult scores the game if it belongs to an arena. It is meant to be registered as a game ended
+// listener of the game manager.
+func (m *Manager) RecordResult(g *game.Game) {
+	for _, arena := range m.GetArenas() {
+		arena.mutex.Lock()
+		pairing, ok := arena.games[g.Id()]
+		if ok {
+			arena.recordResult(pairing, g)
+		}
+		arena.mutex.Unlock()
+
+		if ok {
+			arena.notifyUpdated()
+		}
+	}
+}
+
+// HandleGameRemoved releases the players of a removed game that did not end without scoring it. It is meant
+// to be registered as a game removed listener of the game manager.
+func (m *Manager) HandleGameRemoved(g *game.Game) {
+	for _, arena := range m.GetArenas() {
+		arena.mutex.Lock()
+		pairing, ok := arena.games[g.Id()]
+		if ok {
+			arena.release(pairing, g.Id())
+		}
+		arena.mutex.Unlock()
+
+		if ok {
+			arena.notifyUpdated()
+		}
+	}
+}
+
+func (m *Manager) newArenaId() Id {
+	var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+	for {
+		b := make([]rune, 8)
+		for i := range b {
+			b[i] = letters[rand.Intn(len(letters))]
+		}
+
+		if _, ok := m.arenas[Id(b)]; !ok {
+			return Id(b)
+		}
+	}
+}
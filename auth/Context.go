@@ -0,0 +1,28 @@
+package auth
+
+import "context"
+
+type identityKey struct{}
+
+type tokenErrorKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the verified token the request or hub connection was made with.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+func WithTokenError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, tokenErrorKey{}, err)
+}
+
+// TokenErrorFromContext returns why the token of the request was rejected, or nil if there was no token
+// or it was valid.
+func TokenErrorFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(tokenErrorKey{}).(error)
+	return err
+}
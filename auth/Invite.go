@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"encoding/json"
+	"github.com/racccoooon/chess-be/constants"
+	"time"
+)
+
+// Invite grants access to a private game, either to take a seat or to watch it. Invites are signed like
+// identity tokens, but can not be used as one.
+type Invite struct {
+	GameId    string
+	Role      int
+	ExpiresAt time.Time
+}
+
+type inviteClaims struct {
+	Game      string `json:"game"`
+	Role      string `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (i *Issuer) IssueInvite(gameId string, role int, lifetime time.Duration) (string, time.Time) {
+	now := time.Now()
+	expiresAt := now.Add(lifetime)
+
+	return i.signClaims(inviteClaims{
+		Game:      gameId,
+		Role:      constants.InviteRoleAsString(role),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}), expiresAt
+}
+
+// VerifyInvite checks the signature and the expiry of the invite.
+func (i *Issuer) VerifyInvite(token string) (Invite, error) {
+	payload, err := i.payload(token)
+	if err != nil {
+		return Invite{}, err
+	}
+
+	var claims inviteClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Game == "" {
+		return Invite{}, ErrInvalidToken
+	}
+
+	role, ok := constants.InviteRoleFromString(claims.Role)
+	if !ok {
+		return Invite{}, ErrInvalidToken
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	if !time.Now().Before(expiresAt) {
+		return Invite{}, ErrExpiredToken
+	}
+
+	return Invite{
+		GameId:    claims.Game,
+		Role:      role,
+		ExpiresAt: expiresAt,
+	}, nil
+}
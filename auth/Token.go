@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("expired token")
+)
+
+// Identity is who a verified token belongs to. The subject is the stable id that is used as the player token in games.
+type Identity struct {
+	Subject   string
+	Name      string
+	Kind      int
+	ExpiresAt time.Time
+}
+
+// HasAccount tells whether the identity belongs to a registered account, which bot accounts are as well.
+func (i Identity) HasAccount() bool {
+	return i.Kind == constants.AccountIdentity || i.Kind == constants.BotIdentity
+}
+
+// Issuer signs and verifies tokens. Tokens are JWTs signed with HMAC-SHA256.
+type Issuer struct {
+	secret []byte
+}
+
+type claims struct {
+	Subject   string `json:"sub"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func NewIssuer(secret []byte) *Issuer {
+	return &Issuer{secret: secret}
+}
+
+// Issue creates a token for the identity that is valid for the given lifetime.
+func (i *Issuer) Issue(identity Identity, lifetime time.Duration) (string, time.Time) {
+	now := time.Now()
+	expiresAt := now.Add(lifetime)
+
+	return i.signClaims(claims{
+		Subject:   identity.Subject,
+		Name:      identity.Name,
+		Kind:      constants.IdentityKindAsString(identity.Kind),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}), expiresAt
+}
+
+// Verify checks the signature and the expiry of the token and returns its identity.
+func (i *Issuer) Verify(token string) (Identity, error) {
+	payload, err := i.payload(token)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	var tokenClaims claims
+	if err := json.Unmarshal(payload, &tokenClaims); err != nil || tokenClaims.Subject == "" {
+		return Identity{}, ErrInvalidToken
+	}
+
+	kind, ok := constants.IdentityKindFromString(tokenClaims.Kind)
+	if !ok {
+		return Identity{}, ErrInvalidToken
+	}
+
+	expiresAt := time.Unix(tokenClaims.ExpiresAt, 0)
+	if !time.Now().Before(expiresAt) {
+		return Identity{}, ErrExpiredToken
+	}
+
+	return Identity{
+		Subject:   tokenClaims.Subject,
+		Name:      tokenClaims.Name,
+		Kind:      kind,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (i *Issuer) signClaims(tokenClaims interface{}) string {
+	payload, err := json.Marshal(tokenClaims)
+	if err != nil {
+		panic(err)
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	return unsigned + "." + i.sign(unsigned)
+}
+
+// payload checks the signature of the token and returns its decoded claims.
+func (i *Issuer) payload(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return nil, ErrInvalidToken
+	}
+
+	expected := i.sign(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+func (i *Issuer) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(unsigned))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
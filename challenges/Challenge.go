@@ -0,0 +1,105 @@
+package challenges
+
+import (
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"time"
+)
+
+var (
+	ErrChallengeNotFound  = errors.New("the challenge does not exist")
+	ErrChallengeYourself  = errors.New("players can not challenge themselves")
+	ErrTooManyChallenges  = errors.New("the player has too many pending challenges")
+	ErrNotChallenged      = errors.New("only the challenged player can answer the challenge")
+	ErrNotChallenger      = errors.New("only the challenger can cancel the challenge")
+	ErrRatedNeedsAccounts = errors.New("rated games can only be played with an account")
+)
+
+type Id string
+
+// Settings describe a challenge when it is created. Color is the color of the challenger, or RandomColor.
+type Settings struct {
+	ChallengerId         string
+	ChallengerName       string
+	ChallengerHasAccount bool
+	DestinationId        string
+	DestinationName      string
+	Color                int
+	StartingPieces       []game.Piece
+	StartingColor        int
+	TimeControl          game.TimeControl
+	Rated                bool
+}
+
+// Challenge invites a specific player to a game. The game is created when the challenged player accepts.
+type Challenge struct {
+	id       Id
+	settings Settings
+
+	status     int
+	createTime time.Time
+	expireTime time.Time
+	gameId     game.Id
+}
+
+func (c *Challenge) Id() Id {
+	return c.id
+}
+
+func (c *Challenge) ChallengerId() string {
+	return c.settings.ChallengerId
+}
+
+func (c *Challenge) ChallengerName() string {
+	return c.settings.ChallengerName
+}
+
+func (c *Challenge) DestinationId() string {
+	return c.settings.DestinationId
+}
+
+func (c *Challenge) DestinationName() string {
+	return c.settings.DestinationName
+}
+
+func (c *Challenge) Color() int {
+	return c.settings.Color
+}
+
+func (c *Challenge) Variant() int {
+	if len(c.settings.StartingPieces) > 0 {
+		return constants.FromPosition
+	}
+
+	return constants.Standard
+}
+
+func (c *Challenge) TimeControl() game.TimeControl {
+	return c.settings.TimeControl
+}
+
+func (c *Challenge) IsRated() bool {
+	return c.settings.Rated
+}
+
+func (c *Challenge) Status() int {
+	return c.status
+}
+
+func (c *Challenge) CreateTime() time.Time {
+	return c.createTime
+}
+
+func (c *Challenge) ExpireTime() time.Time {
+	return c.expireTime
+}
+
+// GameId returns the game that was created when the challenge was accepted, or an empty id before.
+func (c *Challenge) GameId() game.Id {
+	return c.gameId
+}
+
+func (c *Challenge) involves(playerId string) bool {
+	return c.settings.ChallengerId == playerId || c.settings.DestinationId == playerId
+}
@@ -0,0 +1,15 @@
+package challenges
+
+type ChallengeListener func(challenge *Challenge)
+
+// OnChallengeUpdated registers a listener that is called when a challenge was created, accepted, declined,
+// canceled or expired. It is called without holding the lock of the manager.
+func (m *Manager) OnChallengeUpdated(listener ChallengeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (m *Manager) notifyUpdated(challenge *Challenge) {
+	for _, listener := range m.listeners {
+		listener(challenge)
+	}
+}
@@ -0,0 +1,216 @@
+package challenges
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// challengeLifetime is how long a challenge waits for an answer before it expires.
+const challengeLifetime = 5 * time.Minute
+
+const maxPendingChallenges = 10
+
+// Manager keeps the pending challenges. Challenges are removed once they are accepted, declined, canceled
+// or expired, after the listeners were told about it.
+type Manager struct {
+	games      *game.Manager
+	challenges map[Id]*Challenge
+	listeners  []ChallengeListener
+	mutex      sync.RWMutex
+}
+
+func NewManager(games *game.Manager) *Manager {
+	return &Manager{
+		games:      games,
+		challenges: make(map[Id]*Challenge),
+	}
+}
+
+func (m *Manager) Create(settings Settings) (*Challenge, error) {
+	if settings.ChallengerId == settings.DestinationId {
+		return nil, ErrChallengeYourself
+	}
+
+	if settings.Rated && !settings.ChallengerHasAccount {
+		return nil, ErrRatedNeedsAccounts
+	}
+
+	now := time.Now()
+	challenge := &Challenge{
+		settings:   settings,
+		status:     constants.ChallengePending,
+		createTime: now,
+		expireTime: now.Add(challengeLifetime),
+	}
+
+	m.mutex.Lock()
+	pending := 0
+	for _, other := range m.challenges {
+		if other.settings.ChallengerId == settings.ChallengerId {
+			pending++
+		}
+	}
+
+	if pending >= maxPendingChallenges {
+		m.mutex.Unlock()
+		return nil, ErrTooManyChallenges
+	}
+
+	challenge.id = m.newChallengeId()
+	m.challenges[challenge.id] = challenge
+	m.mutex.Unlock()
+
+	m.notifyUpdated(challenge)
+
+	return challenge, nil
+}
+
+func (m *Manager) GetChallenge(id Id) *Challenge {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.challenges[id]
+}
+
+// GetChallengesFor returns the pending challenges the player sent or received, oldest first.
+func (m *Manager) GetChallengesFor(playerId string) []*Challenge {
+	m.mutex.RLock()
+	var challenges []*Challenge
+	for _, challenge := range m.challenges {
+		if challenge.involves(playerId) {
+			challenges = append(challenges, challenge)
+		}
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].createTime.Before(challenges[j].createTime)
+	})
+
+	return challenges
+}
+
+// Accept creates the game of the challenge and seats both players.
+func (m *Manager) Accept(id Id, playerId string) (*Challenge, error) {
+	challenge, err := m.resolve(id, constants.ChallengeAccepted, func(challenge *Challenge) error {
+		if challenge.settings.DestinationId != playerId {
+			return ErrNotChallenged
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	challengeGame := m.games.NewGame(game.Settings{
+		FirstPlayerColor: challenge.settings.Color,
+		StartingPieces:   challenge.settings.StartingPieces,
+		StartingColor:    challenge.settings.StartingColor,
+		TimeControl:      challenge.settings.TimeControl,
+		Rated:            challenge.settings.Rated,
+	})
+
+	// the first player gets FirstPlayerColor, the second one the other color
+	challengeGame.AddPlayer(challenge.settings.ChallengerName, challenge.settings.ChallengerId, "")
+	challengeGame.AddPlayer(challenge.settings.DestinationName, challenge.settings.DestinationId, "")
+
+	challenge.gameId = challengeGame.Id()
+
+	m.notifyUpdated(challenge)
+
+	return challenge, nil
+}
+
+func (m *Manager) Decline(id Id, playerId string) (*Challenge, error) {
+	challenge, err := m.resolve(id, constants.ChallengeDeclined, func(challenge *Challenge) error {
+		if challenge.settings.DestinationId != playerId {
+			return ErrNotChallenged
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.notifyUpdated(challenge)
+
+	return challenge, nil
+}
+
+func (m *Manager) Cancel(id Id, playerId string) (*Challenge, error) {
+	challenge, err := m.resolve(id, constants.ChallengeCanceled, func(challenge *Challenge) error {
+		if challenge.settings.ChallengerId != playerId {
+			return ErrNotChallenger
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.notifyUpdated(challenge)
+
+	return challenge, nil
+}
+
+// ExpireChallenges expires the challenges that were not answered in time.
+func (m *Manager) ExpireChallenges() {
+	now := time.Now()
+
+	m.mutex.Lock()
+	var expired []*Challenge
+	for id, challenge := range m.challenges {
+		if now.After(challenge.expireTime) {
+			challenge.status = constants.ChallengeExpired
+			delete(m.challenges, id)
+			expired = append(expired, challenge)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, challenge := range expired {
+		m.notifyUpdated(challenge)
+	}
+}
+
+// resolve removes a pending challenge with the new status if the check allows it.
+func (m *Manager) resolve(id Id, status int, check func(challenge *Challenge) error) (*Challenge, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	challenge := m.challenges[id]
+	if challenge == nil {
+		return nil, ErrChallengeNotFound
+	}
+
+	if err := check(challenge); err != nil {
+		return nil, err
+	}
+
+	challenge.status = status
+	delete(m.challenges, id)
+
+	return challenge, nil
+}
+
+func (m *Manager) newChallengeId() Id {
+	var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+	for {
+		b := make([]rune, 8)
+		for i := range b {
+			b[i] = letters[rand.Intn(len(letters))]
+		}
+
+		if _, ok := m.challenges[Id(b)]; !ok {
+			return Id(b)
+		}
+	}
+}
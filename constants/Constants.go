@@ -21,6 +21,90 @@ const (
 	IsCheck     = 1
 	IsCheckmate = 2
 	IsStalemate = 3
+
+	MoveAllowed          = 0
+	NotYourTurn          = 1
+	NoPieceAtSquare      = 2
+	OutOfBounds          = 3
+	CannotCaptureOwn     = 4
+	InvalidPieceMovement = 5
+	PathBlocked          = 6
+	LeavesKingInCheck    = 7
+	CastlingThroughCheck = 8
+	PromotionMissing     = 9
+	PromotionInvalid     = 10
+	GameIsOver           = 11
+	NotYourPiece         = 12
+
+	InProgress = 0
+	WhiteWon   = 1
+	BlackWon   = 2
+	Drawn      = 3
+
+	NoTermination = 0
+	Checkmate     = 1
+	Stalemate     = 2
+	Resignation   = 3
+	DrawAgreement = 4
+	Timeout       = 5
+	Abandonment   = 6
+
+	Standard     = 0
+	FromPosition = 1
+
+	Unlimited      = 0
+	Bullet         = 1
+	Blitz          = 2
+	Rapid          = 3
+	Classical      = 4
+	Correspondence = 5
+
+	NewestFirst    = 0
+	OldestFirst    = 1
+	MostSpectators = 2
+
+	AccountIdentity = 0
+	GuestIdentity   = 1
+	BotIdentity     = 2
+
+	Swiss            = 0
+	RoundRobin       = 1
+	DoubleRoundRobin = 2
+	Knockout         = 3
+
+	Registration = 0
+	Running      = 1
+	Finished     = 2
+
+	AnalysisQueued   = 0
+	AnalysisRunning  = 1
+	AnalysisFinished = 2
+	AnalysisFailed   = 3
+
+	GoodMove   = 0
+	Inaccuracy = 1
+	Mistake    = 2
+	Blunder    = 3
+
+	ChallengePending  = 0
+	ChallengeAccepted = 1
+	ChallengeDeclined = 2
+	ChallengeCanceled = 3
+	ChallengeExpired  = 4
+
+	SeatInvite      = 0
+	SpectatorInvite = 1
+
+	SpectatorsAllowed    = 0
+	SpectatorsDisallowed = 1
+	SpectatorsAfterEnd   = 2
+
+	PlayerRoom    = 0
+	SpectatorRoom = 1
+
+	ChatAllowed    = 0
+	InvalidMessage = 1
+	RateLimited    = 2
 )
 
 func StatusAsString(status int) string {
@@ -53,6 +137,356 @@ func MoveKindAsString(kind int) string {
 	panic("invalid move kind")
 }
 
+func MoveErrorAsString(moveError int) string {
+	switch moveError {
+	case MoveAllowed:
+		return "moveAllowed"
+	case NotYourTurn:
+		return "notYourTurn"
+	case NoPieceAtSquare:
+		return "noPieceAtSquare"
+	case OutOfBounds:
+		return "outOfBounds"
+	case CannotCaptureOwn:
+		return "cannotCaptureOwn"
+	case InvalidPieceMovement:
+		return "invalidPieceMovement"
+	case PathBlocked:
+		return "pathBlocked"
+	case LeavesKingInCheck:
+		return "leavesKingInCheck"
+	case CastlingThroughCheck:
+		return "castlingThroughCheck"
+	case PromotionMissing:
+		return "promotionMissing"
+	case PromotionInvalid:
+		return "promotionInvalid"
+	case GameIsOver:
+		return "gameIsOver"
+	case NotYourPiece:
+		return "notYourPiece"
+	}
+
+	panic("invalid move error")
+}
+
+func ResultAsString(result int) string {
+	switch result {
+	case InProgress:
+		return "inProgress"
+	case WhiteWon:
+		return "whiteWon"
+	case BlackWon:
+		return "blackWon"
+	case Drawn:
+		return "drawn"
+	}
+
+	panic("invalid result")
+}
+
+func TerminationAsString(termination int) string {
+	switch termination {
+	case NoTermination:
+		return "noTermination"
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case Resignation:
+		return "resignation"
+	case DrawAgreement:
+		return "drawAgreement"
+	case Timeout:
+		return "timeout"
+	case Abandonment:
+		return "abandonment"
+	}
+
+	panic("invalid termination")
+}
+
+func VariantAsString(variant int) string {
+	switch variant {
+	case Standard:
+		return "standard"
+	case FromPosition:
+		return "fromPosition"
+	}
+
+	panic("invalid variant")
+}
+
+func VariantFromString(variant string) (int, bool) {
+	switch variant {
+	case "standard":
+		return Standard, true
+	case "fromPosition":
+		return FromPosition, true
+	}
+
+	return 0, false
+}
+
+func TimeControlCategoryAsString(category int) string {
+	switch category {
+	case Unlimited:
+		return "unlimited"
+	case Bullet:
+		return "bullet"
+	case Blitz:
+		return "blitz"
+	case Rapid:
+		return "rapid"
+	case Classical:
+		return "classical"
+	case Correspondence:
+		return "correspondence"
+	}
+
+	panic("invalid time control category")
+}
+
+func TimeControlCategoryFromString(category string) (int, bool) {
+	switch category {
+	case "unlimited":
+		return Unlimited, true
+	case "bullet":
+		return Bullet, true
+	case "blitz":
+		return Blitz, true
+	case "rapid":
+		return Rapid, true
+	case "classical":
+		return Classical, true
+	case "correspondence":
+		return Correspondence, true
+	}
+
+	return 0, false
+}
+
+func LobbySortFromString(sort string) (int, bool) {
+	switch sort {
+	case "newest":
+		return NewestFirst, true
+	case "oldest":
+		return OldestFirst, true
+	case "spectators":
+		return MostSpectators, true
+	}
+
+	return 0, false
+}
+
+func IdentityKindAsString(kind int) string {
+	switch kind {
+	case AccountIdentity:
+		return "account"
+	case GuestIdentity:
+		return "guest"
+	case BotIdentity:
+		return "bot"
+	}
+
+	panic("invalid identity kind")
+}
+
+func IdentityKindFromString(kind string) (int, bool) {
+	switch kind {
+	case "account":
+		return AccountIdentity, true
+	case "guest":
+		return GuestIdentity, true
+	case "bot":
+		return BotIdentity, true
+	}
+
+	return 0, false
+}
+
+func InviteRoleAsString(role int) string {
+	switch role {
+	case SeatInvite:
+		return "seat"
+	case SpectatorInvite:
+		return "spectator"
+	}
+
+	panic("invalid invite role")
+}
+
+func InviteRoleFromString(role string) (int, bool) {
+	switch role {
+	case "seat":
+		return SeatInvite, true
+	case "spectator":
+		return SpectatorInvite, true
+	}
+
+	return 0, false
+}
+
+func SpectatorPolicyAsString(policy int) string {
+	switch policy {
+	case SpectatorsAllowed:
+		return "allowed"
+	case SpectatorsDisallowed:
+		return "disallowed"
+	case SpectatorsAfterEnd:
+		return "afterEnd"
+	}
+
+	panic("invalid spectator policy")
+}
+
+func SpectatorPolicyFromString(policy string) (int, bool) {
+	switch policy {
+	case "allowed":
+		return SpectatorsAllowed, true
+	case "disallowed":
+		return SpectatorsDisallowed, true
+	case "afterEnd":
+		return SpectatorsAfterEnd, true
+	}
+
+	return 0, false
+}
+
+func ChatRoomAsString(room int) string {
+	switch room {
+	case PlayerRoom:
+		return "players"
+	case SpectatorRoom:
+		return "spectators"
+	}
+
+	panic("invalid chat room")
+}
+
+func ChatRoomFromString(room string) (int, bool) {
+	switch room {
+	case "players":
+		return PlayerRoom, true
+	case "spectators":
+		return SpectatorRoom, true
+	}
+
+	return 0, false
+}
+
+func ChatErrorAsString(chatError int) string {
+	switch chatError {
+	case ChatAllowed:
+		return "chatAllowed"
+	case InvalidMessage:
+		return "invalidMessage"
+	case RateLimited:
+		return "rateLimited"
+	}
+
+	panic("invalid chat error")
+}
+
+func TournamentFormatAsString(format int) string {
+	switch format {
+	case Swiss:
+		return "swiss"
+	case RoundRobin:
+		return "roundRobin"
+	case DoubleRoundRobin:
+		return "doubleRoundRobin"
+	case Knockout:
+		return "knockout"
+	}
+
+	panic("invalid tournament format")
+}
+
+func TournamentFormatFromString(format string) (int, bool) {
+	switch format {
+	case "swiss":
+		return Swiss, true
+	case "roundRobin":
+		return RoundRobin, true
+	case "doubleRoundRobin":
+		return DoubleRoundRobin, true
+	case "knockout":
+		return Knockout, true
+	}
+
+	return 0, false
+}
+
+func TournamentStatusAsString(status int) string {
+	switch status {
+	case Registration:
+		return "registration"
+	case Running:
+		return "running"
+	case Finished:
+		return "finished"
+	}
+
+	panic("invalid tournament status")
+}
+
+func AnalysisStatusAsString(status int) string {
+	switch status {
+	case AnalysisQueued:
+		return "queued"
+	case AnalysisRunning:
+		return "running"
+	case AnalysisFinished:
+		return "finished"
+	case AnalysisFailed:
+		return "failed"
+	}
+
+	panic("invalid analysis status")
+}
+
+func MoveClassificationAsString(classification int) string {
+	switch classification {
+	case GoodMove:
+		return "good"
+	case Inaccuracy:
+		return "inaccuracy"
+	case Mistake:
+		return "mistake"
+	case Blunder:
+		return "blunder"
+	}
+
+	panic("invalid move classification")
+}
+
+func ChallengeStatusAsString(status int) string {
+	switch status {
+	case ChallengePending:
+		return "pending"
+	case ChallengeAccepted:
+		return "accepted"
+	case ChallengeDeclined:
+		return "declined"
+	case ChallengeCanceled:
+		return "canceled"
+	case ChallengeExpired:
+		return "expired"
+	}
+
+	panic("invalid challenge status")
+}
+
+// WinFor returns the result of a game won by the given color.
+func WinFor(color int) int {
+	if color == White {
+		return WhiteWon
+	}
+
+	return BlackWon
+}
+
 func ColorFromString(color string) int {
 	switch color {
 	case "white":
@@ -72,6 +506,8 @@ func ColorAsString(color int) string {
 		return "white"
 	case Black:
 		return "black"
+	case RandomColor:
+		return "randomColor"
 	}
 
 	panic("invalid color")
@@ -92,6 +528,15 @@ func PromotionTypeFromString(promotionType string) int {
 	panic("invalid promotion type")
 }
 
+func IsPromotionType(promotionType string) bool {
+	switch promotionType {
+	case "queen", "rook", "knight", "bishop":
+		return true
+	}
+
+	return false
+}
+
 func TypeAsString(t int) string {
 	switch t {
 	case Pawn:
@@ -0,0 +1,172 @@
+// Package dtos describes a game the same way over REST and the hub.
+package dtos
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"time"
+)
+
+type TimeControl struct {
+	InitialSeconds   int    `json:"initialSeconds"`
+	IncrementSeconds int    `json:"incrementSeconds"`
+	DaysPerMove      int    `json:"daysPerMove"` // 0 unless the game is a correspondence game
+	VacationDays     int    `json:"vacationDays"`
+	Category         string `json:"category"`
+}
+
+type Clock struct {
+	WhiteMs int64 `json:"whiteMs"`
+	BlackMs int64 `json:"blackMs"`
+}
+
+type Delay struct {
+	Moves   int `json:"moves"`
+	Seconds int `json:"seconds"`
+}
+
+type BoardItem struct {
+	Color    string   `json:"color"`
+	Type     string   `json:"type"`
+	Position Position `json:"position"`
+}
+
+type MoveItem struct {
+	From          Position `json:"from"`
+	To            Position `json:"to"`
+	Color         string   `json:"color"`
+	Type          string   `json:"type"`
+	Kind          string   `json:"kind"`
+	Status        string   `json:"status"`
+	Captures      bool     `json:"captures"`
+	PromoteToType *string  `json:"promoteToType"`
+}
+
+type LobbyGame struct {
+	Id              string      `json:"id"`
+	Name            string      `json:"name"`
+	WhitePlayerName *string     `json:"whitePlayerName"`
+	BlackPlayerName *string     `json:"blackPlayerName"`
+	OpenSeats       int         `json:"openSeats"`
+	Variant         string      `json:"variant"`
+	TimeControl     TimeControl `json:"timeControl"`
+	CreatedAt       time.Time   `json:"createdAt"`
+	SpectatorCount  int         `json:"spectatorCount"`
+	Rated           bool        `json:"rated"`
+}
+
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TimeControlAsResponse(timeControl game.TimeControl) TimeControl {
+	return TimeControl{
+		InitialSeconds:   int(timeControl.Initial() / time.Second),
+		IncrementSeconds: int(timeControl.Increment() / time.Second),
+		DaysPerMove:      timeControl.DaysPerMove(),
+		VacationDays:     int(timeControl.Vacation() / (24 * time.Hour)),
+		Category:         constants.TimeControlCategoryAsString(timeControl.Category()),
+	}
+}
+
+func GameAsLobbyGame(g *game.Game) LobbyGame {
+	lobbyGame := LobbyGame{
+		Id:             string(g.Id()),
+		Name:           g.Name(),
+		OpenSeats:      g.OpenSeats(),
+		Variant:        constants.VariantAsString(g.Variant()),
+		TimeControl:    TimeControlAsResponse(g.TimeControl()),
+		CreatedAt:      g.CreateTime(),
+		SpectatorCount: g.SpectatorCount(),
+		Rated:          g.IsRated(),
+	}
+
+	if player := g.GetPlayerByColor(constants.White); player != nil {
+		name := player.Name()
+		lobbyGame.WhitePlayerName = &name
+	}
+
+	if player := g.GetPlayerByColor(constants.Black); player != nil {
+		name := player.Name()
+		lobbyGame.BlackPlayerName = &name
+	}
+
+	return lobbyGame
+}
+
+func GameAsClock(g *game.Game) Clock {
+	return Clock{
+		WhiteMs: g.TimeLeft(constants.White).Milliseconds(),
+		BlackMs: g.TimeLeft(constants.Black).Milliseconds(),
+	}
+}
+
+// DelayAsResponse returns nil for games without a broadcast delay.
+func DelayAsResponse(delay game.BroadcastDelay) *Delay {
+	if delay.IsZero() {
+		return nil
+	}
+
+	return &Delay{
+		Moves:   delay.Moves,
+		Seconds: int(delay.Duration / time.Second),
+	}
+}
+
+func PiecesAsBoardItems(pieces []game.Piece) []BoardItem {
+	boardItems := make([]BoardItem, len(pieces))
+
+	for i, piece := range pieces {
+		boardItems[i] = BoardItem{
+			Color: constants.ColorAsString(piece.Color()),
+			Type:  constants.TypeAsString(piece.Type()),
+			Position: Position{
+				X: piece.X(),
+				Y: piece.Y(),
+			},
+		}
+	}
+
+	return boardItems
+}
+
+func MovesAsMoveItems(moves []game.Move) []MoveItem {
+	moveItems := make([]MoveItem, len(moves))
+
+	for i, move := range moves {
+		moveItems[i] = MoveAsMoveItem(move)
+	}
+
+	return moveItems
+}
+
+func MoveAsMoveItem(move game.Move) MoveItem {
+	t := move.Type()
+	if move.Kind() == constants.Promotion {
+		t = constants.Pawn
+	}
+
+	var promotionType *string = nil
+	if move.Kind() == constants.Promotion {
+		temp := constants.TypeAsString(move.Type())
+		promotionType = &temp
+	}
+
+	return MoveItem{
+		From: Position{
+			X: move.FromX(),
+			Y: move.FromY(),
+		},
+		To: Position{
+			X: move.ToX(),
+			Y: move.ToY(),
+		},
+		Color:         constants.ColorAsString(move.Color()),
+		Type:          constants.TypeAsString(t),
+		Kind:          constants.MoveKindAsString(move.Kind()),
+		Status:        constants.StatusAsString(move.Status()),
+		Captures:      move.Captures(),
+		PromoteToType: promotionType,
+	}
+}
@@ -0,0 +1,365 @@
+package engines
+
+import (
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPosition = errors.New("the position is invalid")
+
+// pieces on the board of the native engine are a kind and, for black pieces, the black flag
+const (
+	noPiece = 0
+	pawn    = 1
+	knight  = 2
+	bishop  = 3
+	rook    = 4
+	queen   = 5
+	king    = 6
+
+	blackPiece = 8
+)
+
+const (
+	whiteKingside = 1 << iota
+	whiteQueenside
+	blackKingside
+	blackQueenside
+)
+
+const noSquare = -1
+
+// board is the position the native engine searches. Squares are numbered from a1 to h8, rank by rank.
+type board struct {
+	squares  [64]int
+	side     int
+	castling int
+	ep       int
+	halfmove int
+	hash     uint64
+	kings    [2]int
+	history  []undo
+}
+
+// undo keeps what is needed to take back a move.
+type undo struct {
+	move     move
+	captured int
+	castling int
+	ep       int
+	halfmove int
+	hash     uint64
+}
+
+func pieceKind(piece int) int {
+	return piece & 7
+}
+
+func pieceColor(piece int) int {
+	if piece&blackPiece != 0 {
+		return constants.Black
+	}
+
+	return constants.White
+}
+
+func makePiece(kind int, color int) int {
+	if color == constants.Black {
+		return kind | blackPiece
+	}
+
+	return kind
+}
+
+var (
+	zobristPieces   [16][64]uint64
+	zobristSide     uint64
+	zobristCastling [16]uint64
+	zobristEp       [8]uint64
+
+	// castlingMasks are the castling rights lost when a piece moves from or to a square
+	castlingMasks [64]int
+)
+
+func init() {
+	random := rand.New(rand.NewSource(20240601))
+
+	for piece := range zobristPieces {
+		for square := range zobristPieces[piece] {
+			zobristPieces[piece][square] = random.Uint64()
+		}
+	}
+
+	zobristSide = random.Uint64()
+
+	for i := range zobristCastling {
+		zobristCastling[i] = random.Uint64()
+	}
+
+	for i := range zobristEp {
+		zobristEp[i] = random.Uint64()
+	}
+
+	castlingMasks[0] = whiteQueenside
+	castlingMasks[4] = whiteKingside | whiteQueenside
+	castlingMasks[7] = whiteKingside
+	castlingMasks[56] = blackQueenside
+	castlingMasks[60] = blackKingside | blackQueenside
+	castlingMasks[63] = blackKingside
+}
+
+// parseFen sets up a board from a position in Forsyth-Edwards Notation.
+func parseFen(fen string) (*board, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return nil, ErrInvalidPosition
+	}
+
+	b := &board{ep: noSquare, kings: [2]int{noSquare, noSquare}}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return nil, ErrInvalidPosition
+	}
+
+	for i, rank := range ranks {
+		y := 7 - i
+		x := 0
+
+		for _, letter := range rank {
+			if letter >= '1' && letter <= '8' {
+				x += int(letter - '0')
+				continue
+			}
+
+			kind := strings.IndexRune("pnbrqk", letter|0x20) + 1
+			if kind == 0 || x > 7 || kind == pawn && (y == 0 || y == 7) {
+				return nil, ErrInvalidPosition
+			}
+
+			color := constants.White
+			if letter >= 'a' {
+				color = constants.Black
+			}
+
+			b.squares[y*8+x] = makePiece(kind, color)
+			if kind == king {
+				b.kings[color] = y*8 + x
+			}
+
+			x++
+		}
+
+		if x != 8 {
+			return nil, ErrInvalidPosition
+		}
+	}
+
+	if b.kings[constants.White] == noSquare || b.kings[constants.Black] == noSquare {
+		return nil, ErrInvalidPosition
+	}
+
+	switch fields[1] {
+	case "w":
+		b.side = constants.White
+	case "b":
+		b.side = constants.Black
+	default:
+		return nil, ErrInvalidPosition
+	}
+
+	for _, letter := range fields[2] {
+		switch letter {
+		case 'K':
+			b.castling |= whiteKingside
+		case 'Q':
+			b.castling |= whiteQueenside
+		case 'k':
+			b.castling |= blackKingside
+		case 'q':
+			b.castling |= blackQueenside
+		}
+	}
+
+	if fields[3] != "-" {
+		if len(fields[3]) != 2 || fields[3][0] < 'a' || fields[3][0] > 'h' || fields[3][1] < '1' || fields[3][1] > '8' {
+			return nil, ErrInvalidPosition
+		}
+
+		b.ep = int(fields[3][1]-'1')*8 + int(fields[3][0]-'a')
+	}
+
+	if len(fields) > 4 {
+		b.halfmove, _ = strconv.Atoi(fields[4])
+	}
+
+	b.hash = b.computeHash()
+
+	return b, nil
+}
+
+func (b *board) computeHash() uint64 {
+	var hash uint64
+
+	for square, piece := range b.squares {
+		if piece != noPiece {
+			hash ^= zobristPieces[piece][square]
+		}
+	}
+
+	if b.side == constants.Black {
+		hash ^= zobristSide
+	}
+
+	hash ^= zobristCastling[b.castling]
+
+	if b.ep != noSquare {
+		hash ^= zobristEp[b.ep%8]
+	}
+
+	return hash
+}
+
+func (b *board) makeMove(m move) {
+	from, to := m.from(), m.to()
+	piece := b.squares[from]
+	color := pieceColor(piece)
+
+	u := undo{move: m, castling: b.castling, ep: b.ep, halfmove: b.halfmove, hash: b.hash}
+
+	captureSquare := to
+	if m.flags()&flagEnPassant != 0 {
+		captureSquare = enPassantVictim(to, color)
+	}
+
+	u.captured = b.squares[captureSquare]
+	if u.captured != noPiece {
+		b.hash ^= zobristPieces[u.captured][captureSquare]
+		b.squares[captureSquare] = noPiece
+	}
+
+	placed := piece
+	if m.promotion() != noPiece {
+		placed = makePiece(m.promotion(), color)
+	}
+
+	b.hash ^= zobristPieces[piece][from] ^ zobristPieces[placed][to]
+	b.squares[from] = noPiece
+	b.squares[to] = placed
+
+	if pieceKind(piece) == king {
+		b.kings[color] = to
+	}
+
+	if m.flags()&flagCastle != 0 {
+		rookFrom, rookTo := castlingRookSquares(to)
+		rookPiece := b.squares[rookFrom]
+		b.hash ^= zobristPieces[rookPiece][rookFrom] ^ zobristPieces[rookPiece][rookTo]
+		b.squares[rookFrom] = noPiece
+		b.squares[rookTo] = rookPiece
+	}
+
+	b.hash ^= zobristCastling[b.castling]
+	b.castling &^= castlingMasks[from] | castlingMasks[to]
+	b.hash ^= zobristCastling[b.castling]
+
+	if b.ep != noSquare {
+		b.hash ^= zobristEp[b.ep%8]
+	}
+
+	b.ep = noSquare
+	if m.flags()&flagDoublePush != 0 {
+		b.ep = (from + to) / 2
+		b.hash ^= zobristEp[b.ep%8]
+	}
+
+	if pieceKind(piece) == pawn || u.captured != noPiece {
+		b.halfmove = 0
+	} else {
+		b.halfmove++
+	}
+
+	b.side ^= 1
+	b.hash ^= zobristSide
+
+	b.history = append(b.history, u)
+}
+
+func (b *board) unmakeMove() {
+	u := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+
+	m := u.move
+	from, to := m.from(), m.to()
+
+	b.side ^= 1
+	color := b.side
+
+	piece := b.squares[to]
+	if m.promotion() != noPiece {
+		piece = makePiece(pawn, color)
+	}
+
+	b.squares[to] = noPiece
+	b.squares[from] = piece
+
+	if pieceKind(piece) == king {
+		b.kings[color] = from
+	}
+
+	if u.captured != noPiece {
+		captureSquare := to
+		if m.flags()&flagEnPassant != 0 {
+			captureSquare = enPassantVictim(to, color)
+		}
+
+		b.squares[captureSquare] = u.captured
+	}
+
+	if m.flags()&flagCastle != 0 {
+		rookFrom, rookTo := castlingRookSquares(to)
+		b.squares[rookFrom] = b.squares[rookTo]
+		b.squares[rookTo] = noPiece
+	}
+
+	b.castling = u.castling
+	b.ep = u.ep
+	b.halfmove = u.halfmove
+	b.hash = u.hash
+}
+
+// enPassantVictim is the square of the pawn that is captured by moving en passant to the given square.
+func enPassantVictim(to int, color int) int {
+	if color == constants.White {
+		return to - 8
+	}
+
+	return to + 8
+}
+
+// castlingRookSquares returns where the rook comes from and goes to when the king castles to the given square.
+func castlingRookSquares(kingTo int) (int, int) {
+	if kingTo%8 == 6 {
+		return kingTo + 1, kingTo - 1
+	}
+
+	return kingTo - 2, kingTo + 1
+}
+
+func (b *board) inCheck(color int) bool {
+	return b.isAttacked(b.kings[color], color^1)
+}
+
+// isRepetition tells whether the position occurred before since the last capture or pawn move.
+func (b *board) isRepetition() bool {
+	for i := len(b.history) - 2; i >= 0 && i >= len(b.history)-b.halfmove; i -= 2 {
+		if b.history[i].hash == b.hash {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,153 @@
+package engines
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sync"
+	"time"
+)
+
+// Bots seats engines as players and answers with a move whenever it is their turn.
+type Bots struct {
+	engine   Engine
+	fallback Engine
+	bots     map[game.Id]*bot
+	mutex    sync.Mutex
+}
+
+type bot struct {
+	token    string
+	limits   Limits
+	thinking bool
+}
+
+func NewBots(engine Engine) *Bots {
+	return &Bots{
+		engine:   engine,
+		fallback: NewNativeEngine(),
+		bots:     make(map[game.Id]*bot),
+	}
+}
+
+// Seat adds a bot as the next player of the game.
+func (b *Bots) Seat(g *game.Game, limits Limits) (*game.Player, error) {
+	seat := &bot{
+		token:  "bot-" + uuid.NewString(),
+		limits: limits,
+	}
+
+	b.mutex.Lock()
+	b.bots[g.Id()] = seat
+	b.mutex.Unlock()
+
+	return g.AddPlayer(fmt.Sprintf("Computer (level %d)", limits.Skill), seat.token, "")
+}
+
+// CanPlay tells whether a bot can play from the given starting pieces. Engines need exactly one king of each
+// color and no pawns on the first or last rank. Without starting pieces the game starts from the standard position.
+func CanPlay(startingPieces []game.Piece) bool {
+	if len(startingPieces) == 0 {
+		return true
+	}
+
+	kings := make(map[int]int)
+	for _, piece := range startingPieces {
+		switch {
+		case piece.Type() == constants.King:
+			kings[piece.Color()]++
+		case piece.Type() == constants.Pawn && (piece.Y() == 0 || piece.Y() == 7):
+			return false
+		}
+	}
+
+	return kings[constants.White] == 1 && kings[constants.Black] == 1
+}
+
+// IsBot tells whether the player with the given token is a bot.
+func (b *Bots) IsBot(g *game.Game, token string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	seat, ok := b.bots[g.Id()]
+
+	return ok && seat.token == token
+}
+
+// HandleMove lets the bot answer a move of its opponent.
+func (b *Bots) HandleMove(g *game.Game, _ game.Move) {
+	b.play(g)
+}
+
+// HandlePlayerJoined lets the bot open the game once its opponent took a seat.
+func (b *Bots) HandlePlayerJoined(g *game.Game, _ *game.Player) {
+	b.play(g)
+}
+
+func (b *Bots) HandleGameRemoved(g *game.Game) {
+	b.mutex.Lock()
+	delete(b.bots, g.Id())
+	b.mutex.Unlock()
+}
+
+func (b *Bots) play(g *game.Game) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	seat, ok := b.bots[g.Id()]
+	if !ok || seat.thinking || g.IsOver() || g.PlayerCount() < 2 {
+		return
+	}
+
+	player := g.GetPlayerByToken(seat.token)
+	if player == nil || player.Color() != g.ActiveColor() {
+		return
+	}
+
+	seat.thinking = true
+	limits := seat.limits
+	color := player.Color()
+
+	// the engine searches a copy of the position, while the players and the clock keep using the game
+	fen, plies := g.Position()
+
+	// never think longer than a small share of the time that is left on the clock
+	if !g.TimeControl().IsUnlimited() {
+		budget := g.TimeLeft(player.Color()) / 30
+		if budget < 50*time.Millisecond {
+			budget = 50 * time.Millisecond
+		}
+
+		if limits.MoveTime == 0 || limits.MoveTime > budget {
+			limits.MoveTime = budget
+		}
+	}
+
+	go func() {
+		move, err := b.engine.BestMove(fen, limits)
+		if err != nil {
+			move, err = b.fallback.BestMove(fen, limits)
+		}
+
+		b.mutex.Lock()
+		seat.thinking = false
+		b.mutex.Unlock()
+
+		if err != nil {
+			return
+		}
+
+		// the move is only played if nothing happened in the game during the search
+		played, reason := g.MoveAfter(plies, color, move.FromX, move.FromY, move.ToX, move.ToY, move.PromoteToType)
+		if played != nil || reason == constants.NotYourTurn || reason == constants.GameIsOver {
+			return
+		}
+
+		// the engine suggested a move the game does not accept
+		move, err = b.fallback.BestMove(fen, limits)
+		if err == nil {
+			g.MoveAfter(plies, color, move.FromX, move.FromY, move.ToX, move.ToY, move.PromoteToType)
+		}
+	}()
+}
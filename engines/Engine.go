@@ -0,0 +1,99 @@
+package engines
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrNoMove = errors.New("the engine did not find a move")
+
+const (
+	MinSkill = 0
+	MaxSkill = 20
+)
+
+// Limits bound how strong an engine plays and how long it may think about a move.
+// A zero depth or move time leaves the limit to the engine.
+type Limits struct {
+	Skill    int
+	Depth    int
+	MoveTime time.Duration
+}
+
+// Move is a move suggested by an engine.
+type Move struct {
+	FromX         int
+	FromY         int
+	ToX           int
+	ToY           int
+	PromoteToType *string
+}
+
+// Engine finds a move for the active color of a position given in Forsyth-Edwards Notation.
+type Engine interface {
+	BestMove(fen string, limits Limits) (Move, error)
+}
+
+// ParseMove reads a move in coordinate notation, such as e2e4 or e7e8q.
+func ParseMove(coordinates string) (Move, bool) {
+	if len(coordinates) != 4 && len(coordinates) != 5 {
+		return Move{}, false
+	}
+
+	coordinates = strings.ToLower(coordinates)
+
+	for i := 0; i < 4; i += 2 {
+		if coordinates[i] < 'a' || coordinates[i] > 'h' || coordinates[i+1] < '1' || coordinates[i+1] > '8' {
+			return Move{}, false
+		}
+	}
+
+	move := Move{
+		FromX: int(coordinates[0] - 'a'),
+		FromY: int(coordinates[1] - '1'),
+		ToX:   int(coordinates[2] - 'a'),
+		ToY:   int(coordinates[3] - '1'),
+	}
+
+	if len(coordinates) == 5 {
+		var promoteToType string
+
+		switch coordinates[4] {
+		case 'q':
+			promoteToType = "queen"
+		case 'r':
+			promoteToType = "rook"
+		case 'b':
+			promoteToType = "bishop"
+		case 'n':
+			promoteToType = "knight"
+		default:
+			return Move{}, false
+		}
+
+		move.PromoteToType = &promoteToType
+	}
+
+	return move, true
+}
+
+// Coordinates writes the move in coordinate notation, the notation UCI engines use.
+func (m Move) Coordinates() string {
+	coordinates := string([]byte{byte('a' + m.FromX), byte('1' + m.FromY), byte('a' + m.ToX), byte('1' + m.ToY)})
+
+	if m.PromoteToType != nil {
+		switch *m.PromoteToType {
+		case "queen":
+			coordinates += "q"
+		case "rook":
+			coordinates += "r"
+		case "bishop":
+			coordinates += "b"
+		case "knight":
+			coordinates += "n"
+		}
+	}
+
+	return coordinates
+}
@@ -0,0 +1,142 @@
+package engines
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+)
+
+var pieceValues = [7]int{noPiece: 0, pawn: 100, knight: 320, bishop: 330, rook: 500, queen: 900, king: 0}
+
+// the piece-square tables are written from the view of white, with the eighth rank on top
+var pieceSquareTables = [7][64]int{
+	pawn: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	knight: {
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	bishop: {
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	rook: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	},
+	queen: {
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	king: {
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	},
+}
+
+// the king belongs in the center once most pieces are gone
+var kingEndgameTable = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+// phaseWeights add up to maxPhase when all pieces are on the board
+var phaseWeights = [7]int{knight: 1, bishop: 1, rook: 2, queen: 4}
+
+const maxPhase = 24
+
+const bishopPairBonus = 30
+
+// tableIndex mirrors the square for white, because the tables are written with the eighth rank first.
+func tableIndex(square int, color int) int {
+	if color == constants.White {
+		return (7-square/8)*8 + square%8
+	}
+
+	return square
+}
+
+// evaluate scores the position in centipawns from the view of the side to move.
+func (b *board) evaluate() int {
+	var score, kingMiddlegame, kingEndgame [2]int
+	var bishops [2]int
+	phase := 0
+
+	for square, piece := range b.squares {
+		if piece == noPiece {
+			continue
+		}
+
+		kind, color := pieceKind(piece), pieceColor(piece)
+		index := tableIndex(square, color)
+
+		if kind == king {
+			kingMiddlegame[color] = pieceSquareTables[king][index]
+			kingEndgame[color] = kingEndgameTable[index]
+			continue
+		}
+
+		score[color] += pieceValues[kind] + pieceSquareTables[kind][index]
+		phase += phaseWeights[kind]
+
+		if kind == bishop {
+			bishops[color]++
+		}
+	}
+
+	if phase > maxPhase {
+		phase = maxPhase
+	}
+
+	for color := range score {
+		score[color] += (kingMiddlegame[color]*phase + kingEndgame[color]*(maxPhase-phase)) / maxPhase
+
+		if bishops[color] >= 2 {
+			score[color] += bishopPairBonus
+		}
+	}
+
+	return score[b.side] - score[b.side^1]
+}
@@ -0,0 +1,318 @@
+package engines
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+)
+
+// move packs the start and target square, the promotion piece kind and the flags of a move.
+type move uint32
+
+const noMove move = 0
+
+const (
+	flagCapture = 1 << iota
+	flagEnPassant
+	flagCastle
+	flagDoublePush
+)
+
+func newMove(from int, to int, promotion int, flags int) move {
+	return move(from | to<<6 | promotion<<12 | flags<<15)
+}
+
+func (m move) from() int {
+	return int(m & 63)
+}
+
+func (m move) to() int {
+	return int(m>>6) & 63
+}
+
+func (m move) promotion() int {
+	return int(m>>12) & 7
+}
+
+func (m move) flags() int {
+	return int(m >> 15)
+}
+
+func (m move) isQuiet() bool {
+	return m.flags()&(flagCapture|flagEnPassant) == 0 && m.promotion() == noPiece
+}
+
+func (m move) asMove() Move {
+	result := Move{FromX: m.from() % 8, FromY: m.from() / 8, ToX: m.to() % 8, ToY: m.to() / 8}
+
+	if m.promotion() != noPiece {
+		var promoteToType string
+
+		switch m.promotion() {
+		case queen:
+			promoteToType = "queen"
+		case rook:
+			promoteToType = "rook"
+		case bishop:
+			promoteToType = "bishop"
+		case knight:
+			promoteToType = "knight"
+		}
+
+		result.PromoteToType = &promoteToType
+	}
+
+	return result
+}
+
+var (
+	knightTargets [64][]int
+	kingTargets   [64][]int
+	pawnTargets   [2][64][]int
+
+	// rays lists the squares in every direction, the first four are straight and the last four diagonal
+	rays [64][8][]int
+)
+
+var directions = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func init() {
+	knightJumps := [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+
+	for square := 0; square < 64; square++ {
+		x, y := square%8, square/8
+
+		for _, jump := range knightJumps {
+			if target, ok := offset(x, y, jump[0], jump[1]); ok {
+				knightTargets[square] = append(knightTargets[square], target)
+			}
+		}
+
+		for d, direction := range directions {
+			if target, ok := offset(x, y, direction[0], direction[1]); ok {
+				kingTargets[square] = append(kingTargets[square], target)
+			}
+
+			for distance := 1; distance < 8; distance++ {
+				target, ok := offset(x, y, direction[0]*distance, direction[1]*distance)
+				if !ok {
+					break
+				}
+
+				rays[square][d] = append(rays[square][d], target)
+			}
+		}
+
+		for _, dx := range []int{-1, 1} {
+			if target, ok := offset(x, y, dx, 1); ok {
+				pawnTargets[constants.White][square] = append(pawnTargets[constants.White][square], target)
+			}
+
+			if target, ok := offset(x, y, dx, -1); ok {
+				pawnTargets[constants.Black][square] = append(pawnTargets[constants.Black][square], target)
+			}
+		}
+	}
+}
+
+func offset(x int, y int, dx int, dy int) (int, bool) {
+	x, y = x+dx, y+dy
+	if x < 0 || x > 7 || y < 0 || y > 7 {
+		return 0, false
+	}
+
+	return y*8 + x, true
+}
+
+// isAttacked tells whether a piece of the given color attacks the square.
+func (b *board) isAttacked(square int, by int) bool {
+	// a pawn attacks the square if a pawn of the other color on the square would attack the pawn
+	for _, target := range pawnTargets[by^1][square] {
+		if b.squares[target] == makePiece(pawn, by) {
+			return true
+		}
+	}
+
+	for _, target := range knightTargets[square] {
+		if b.squares[target] == makePiece(knight, by) {
+			return true
+		}
+	}
+
+	for _, target := range kingTargets[square] {
+		if b.squares[target] == makePiece(king, by) {
+			return true
+		}
+	}
+
+	for d := range directions {
+		slider := bishop
+		if d < 4 {
+			slider = rook
+		}
+
+		for _, target := range rays[square][d] {
+			piece := b.squares[target]
+			if piece == noPiece {
+				continue
+			}
+
+			if pieceColor(piece) == by && (pieceKind(piece) == slider || pieceKind(piece) == queen) {
+				return true
+			}
+
+			break
+		}
+	}
+
+	return false
+}
+
+// generateMoves appends the pseudo-legal moves of the side to move, which may still leave the own king in check.
+// With capturesOnly only captures and promotions to a queen are generated.
+func (b *board) generateMoves(moves []move, capturesOnly bool) []move {
+	color := b.side
+
+	for square, piece := range b.squares {
+		if piece == noPiece || pieceColor(piece) != color {
+			continue
+		}
+
+		switch pieceKind(piece) {
+		case pawn:
+			moves = b.generatePawnMoves(moves, square, capturesOnly)
+		case knight:
+			moves = b.generateTargetMoves(moves, square, knightTargets[square], capturesOnly)
+		case king:
+			moves = b.generateTargetMoves(moves, square, kingTargets[square], capturesOnly)
+			if !capturesOnly {
+				moves = b.generateCastlingMoves(moves, square)
+			}
+		case bishop:
+			moves = b.generateSlidingMoves(moves, square, 4, 8, capturesOnly)
+		case rook:
+			moves = b.generateSlidingMoves(moves, square, 0, 4, capturesOnly)
+		case queen:
+			moves = b.generateSlidingMoves(moves, square, 0, 8, capturesOnly)
+		}
+	}
+
+	return moves
+}
+
+func (b *board) generatePawnMoves(moves []move, square int, capturesOnly bool) []move {
+	color := b.side
+	forward, startRank, lastRank := 8, 1, 7
+	if color == constants.Black {
+		forward, startRank, lastRank = -8, 6, 0
+	}
+
+	addPawnMove := func(to int, flags int) {
+		if to/8 != lastRank {
+			moves = append(moves, newMove(square, to, noPiece, flags))
+			return
+		}
+
+		moves = append(moves, newMove(square, to, queen, flags))
+		if !capturesOnly {
+			moves = append(moves, newMove(square, to, rook, flags), newMove(square, to, bishop, flags), newMove(square, to, knight, flags))
+		}
+	}
+
+	to := square + forward
+	if b.squares[to] == noPiece {
+		if !capturesOnly || to/8 == lastRank {
+			addPawnMove(to, 0)
+		}
+
+		if !capturesOnly && square/8 == startRank && b.squares[to+forward] == noPiece {
+			moves = append(moves, newMove(square, to+forward, noPiece, flagDoublePush))
+		}
+	}
+
+	for _, target := range pawnTargets[color][square] {
+		if piece := b.squares[target]; piece != noPiece && pieceColor(piece) != color {
+			addPawnMove(target, flagCapture)
+		} else if target == b.ep {
+			moves = append(moves, newMove(square, target, noPiece, flagCapture|flagEnPassant))
+		}
+	}
+
+	return moves
+}
+
+func (b *board) generateTargetMoves(moves []move, square int, targets []int, capturesOnly bool) []move {
+	for _, target := range targets {
+		piece := b.squares[target]
+
+		if piece == noPiece {
+			if !capturesOnly {
+				moves = append(moves, newMove(square, target, noPiece, 0))
+			}
+		} else if pieceColor(piece) != b.side {
+			moves = append(moves, newMove(square, target, noPiece, flagCapture))
+		}
+	}
+
+	return moves
+}
+
+func (b *board) generateSlidingMoves(moves []move, square int, firstDirection int, lastDirection int, capturesOnly bool) []move {
+	for d := firstDirection; d < lastDirection; d++ {
+		for _, target := range rays[square][d] {
+			piece := b.squares[target]
+
+			if piece == noPiece {
+				if !capturesOnly {
+					moves = append(moves, newMove(square, target, noPiece, 0))
+				}
+				continue
+			}
+
+			if pieceColor(piece) != b.side {
+				moves = append(moves, newMove(square, target, noPiece, flagCapture))
+			}
+
+			break
+		}
+	}
+
+	return moves
+}
+
+func (b *board) generateCastlingMoves(moves []move, square int) []move {
+	color := b.side
+	kingside, queenside, home := whiteKingside, whiteQueenside, 4
+	if color == constants.Black {
+		kingside, queenside, home = blackKingside, blackQueenside, 60
+	}
+
+	if square != home || b.castling&(kingside|queenside) == 0 || b.isAttacked(home, color^1) {
+		return moves
+	}
+
+	if b.castling&kingside != 0 && b.squares[home+3] == makePiece(rook, color) && b.squares[home+1] == noPiece && b.squares[home+2] == noPiece &&
+		!b.isAttacked(home+1, color^1) && !b.isAttacked(home+2, color^1) {
+		moves = append(moves, newMove(home, home+2, noPiece, flagCastle))
+	}
+
+	if b.castling&queenside != 0 && b.squares[home-4] == makePiece(rook, color) && b.squares[home-1] == noPiece && b.squares[home-2] == noPiece && b.squares[home-3] == noPiece &&
+		!b.isAttacked(home-1, color^1) && !b.isAttacked(home-2, color^1) {
+		moves = append(moves, newMove(home, home-2, noPiece, flagCastle))
+	}
+
+	return moves
+}
+
+// legalMoves returns the moves of the side to move that do not leave the own king in check.
+func (b *board) legalMoves() []move {
+	var legal []move
+
+	for _, m := range b.generateMoves(nil, false) {
+		b.makeMove(m)
+		if !b.inCheck(b.side ^ 1) {
+			legal = append(legal, m)
+		}
+		b.unmakeMove()
+	}
+
+	return legal
+}
@@ -0,0 +1,99 @@
+package engines
+
+import (
+	"math/rand"
+	"time"
+)
+
+// maxSearchTime bounds searches that are only limited by depth.
+const maxSearchTime = 30 * time.Second
+
+// NativeEngine is an alpha-beta engine that runs in the server process, so no engine binary has to be installed.
+type NativeEngine struct{}
+
+func NewNativeEngine() *NativeEngine {
+	return &NativeEngine{}
+}
+
+// Analysis is the result of a search. The score is in centipawns from the view of the side to move.
+// Mate is the number of moves to a forced mate, negative if the side to move gets mated, and 0 without a mate.
+type Analysis struct {
+	BestMove Move
+	Score    int
+	Mate     int
+	Depth    int
+	Nodes    int
+}
+
+func (e *NativeEngine) BestMove(fen string, limits Limits) (Move, error) {
+	analysis, err := e.Analyze(fen, limits)
+	if err != nil {
+		return Move{}, err
+	}
+
+	return analysis.BestMove, nil
+}
+
+// Analyze searches the position given in Forsyth-Edwards Notation.
+func (e *NativeEngine) Analyze(fen string, limits Limits) (Analysis, error) {
+	b, err := parseFen(fen)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	depth, noise := strength(limits)
+
+	moveTime := limits.MoveTime
+	if moveTime == 0 {
+		moveTime = defaultMoveTime
+		if limits.Depth > 0 {
+			moveTime = maxSearchTime
+		}
+	}
+
+	s := newSearcher(b, time.Now().Add(moveTime), noise, rand.Uint64())
+	result := s.search(depth)
+
+	if result.move == noMove {
+		return Analysis{}, ErrNoMove
+	}
+
+	analysis := Analysis{
+		BestMove: result.move.asMove(),
+		Score:    result.score,
+		Depth:    result.depth,
+		Nodes:    result.nodes,
+	}
+
+	switch {
+	case result.score > mateBound:
+		analysis.Mate = (mateScore - result.score + 1) / 2
+	case result.score < -mateBound:
+		analysis.Mate = -(mateScore + result.score + 1) / 2
+	}
+
+	return analysis, nil
+}
+
+// strength turns the skill level into the deepest search and the noise added to the evaluation.
+// At the highest skill only the given depth and move time limit the engine.
+func strength(limits Limits) (int, int) {
+	skill := limits.Skill
+	if skill < MinSkill {
+		skill = MinSkill
+	}
+	if skill > MaxSkill {
+		skill = MaxSkill
+	}
+
+	depth := maxPly
+	if skill < MaxSkill {
+		depth = 1 + skill/2
+	}
+
+	if limits.Depth > 0 && limits.Depth < depth {
+		depth = limits.Depth
+	}
+
+	return depth, (MaxSkill - skill) * 10
+}
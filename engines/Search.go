@@ -0,0 +1,374 @@
+package engines
+
+import (
+	"time"
+)
+
+const (
+	infinity  = 1000000
+	mateScore = 100000
+	maxPly    = 64
+
+	// scores beyond mateBound are mates, the distance to the mate is encoded in the difference to mateScore
+	mateBound = mateScore - maxPly
+)
+
+const (
+	boundExact = iota
+	boundLower
+	boundUpper
+)
+
+const transpositionTableSize = 1 << 16
+
+type transposition struct {
+	key   uint64
+	move  move
+	score int
+	depth int
+	bound int
+}
+
+// searcher runs an iterative deepening alpha-beta search on a board.
+type searcher struct {
+	board    *board
+	table    []transposition
+	killers  [maxPly][2]move
+	history  [64][64]int
+	nodes    int
+	deadline time.Time
+	stopped  bool
+
+	// noise weakens the engine by blurring the evaluation by up to this many centipawns
+	noise int
+	seed  uint64
+}
+
+// searchResult is what the searcher knows after the deepest completed iteration.
+type searchResult struct {
+	move  move
+	score int
+	depth int
+	nodes int
+}
+
+func newSearcher(b *board, deadline time.Time, noise int, seed uint64) *searcher {
+	return &searcher{
+		board:    b,
+		table:    make([]transposition, transpositionTableSize),
+		deadline: deadline,
+		noise:    noise,
+		seed:     seed,
+	}
+}
+
+func (s *searcher) search(maxDepth int) searchResult {
+	var result searchResult
+
+	legal := s.board.legalMoves()
+	if len(legal) == 0 {
+		return result
+	}
+
+	// always have an answer, even if the first iteration runs out of time
+	result.move = legal[0]
+
+	for depth := 1; depth <= maxDepth && depth < maxPly; depth++ {
+		score := s.negamax(depth, 0, -infinity, infinity)
+
+		if s.stopped {
+			break
+		}
+
+		if entry := s.probe(); entry != nil && entry.move != noMove {
+			result.move = entry.move
+		}
+
+		result.score = score
+		result.depth = depth
+
+		// there is no point in searching deeper once a forced mate is found
+		if score > mateBound || score < -mateBound {
+			break
+		}
+	}
+
+	result.nodes = s.nodes
+
+	return result
+}
+
+func (s *searcher) checkTime() {
+	if s.nodes&2047 == 0 && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+}
+
+func (s *searcher) negamax(depth int, ply int, alpha int, beta int) int {
+	b := s.board
+
+	if ply > 0 && (b.halfmove >= 100 || b.isRepetition()) {
+		return 0
+	}
+
+	s.nodes++
+	s.checkTime()
+	if s.stopped {
+		return 0
+	}
+
+	inCheck := b.inCheck(b.side)
+	if inCheck {
+		depth++
+	}
+
+	if depth <= 0 {
+		return s.quiesce(ply, alpha, beta)
+	}
+
+	if ply >= maxPly-1 {
+		return s.evaluate()
+	}
+
+	ttMove := noMove
+	if entry := s.probe(); entry != nil {
+		ttMove = entry.move
+
+		if ply > 0 && entry.depth >= depth {
+			score := scoreFromTable(entry.score, ply)
+
+			switch {
+			case entry.bound == boundExact:
+				return score
+			case entry.bound == boundLower && score >= beta:
+				return score
+			case entry.bound == boundUpper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	moves := b.generateMoves(make([]move, 0, 48), false)
+	scores := s.orderMoves(moves, ttMove, ply)
+
+	originalAlpha := alpha
+	best := -infinity
+	bestMove := noMove
+	legal := 0
+
+	for i := range moves {
+		m := pickMove(moves, scores, i)
+
+		b.makeMove(m)
+		if b.inCheck(b.side ^ 1) {
+			b.unmakeMove()
+			continue
+		}
+
+		legal++
+
+		var score int
+		if legal == 1 {
+			score = -s.negamax(depth-1, ply+1, -beta, -alpha)
+		} else {
+			// the first move is probably the best, so the others only have to be proven worse
+			score = -s.negamax(depth-1, ply+1, -alpha-1, -alpha)
+			if score > alpha && score < beta {
+				score = -s.negamax(depth-1, ply+1, -beta, -alpha)
+			}
+		}
+
+		b.unmakeMove()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best = score
+			bestMove = m
+		}
+
+		if score > alpha {
+			alpha = score
+		}
+
+		if alpha >= beta {
+			if m.isQuiet() {
+				s.killers[ply][1] = s.killers[ply][0]
+				s.killers[ply][0] = m
+				s.history[m.from()][m.to()] += depth * depth
+			}
+
+			break
+		}
+	}
+
+	if legal == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+
+		return 0
+	}
+
+	bound := boundExact
+	if best <= originalAlpha {
+		bound = boundUpper
+	} else if best >= beta {
+		bound = boundLower
+	}
+
+	s.store(depth, scoreToTable(best, ply), bestMove, bound)
+
+	return best
+}
+
+// quiesce only searches captures, so the evaluation is never taken in the middle of an exchange.
+func (s *searcher) quiesce(ply int, alpha int, beta int) int {
+	b := s.board
+
+	s.nodes++
+	s.checkTime()
+	if s.stopped {
+		return 0
+	}
+
+	standPat := s.evaluate()
+	if standPat >= beta || ply >= maxPly-1 {
+		return standPat
+	}
+
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	moves := b.generateMoves(make([]move, 0, 16), true)
+	scores := s.orderMoves(moves, noMove, ply)
+
+	for i := range moves {
+		m := pickMove(moves, scores, i)
+
+		b.makeMove(m)
+		if b.inCheck(b.side ^ 1) {
+			b.unmakeMove()
+			continue
+		}
+
+		score := -s.quiesce(ply+1, -beta, -alpha)
+		b.unmakeMove()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score >= beta {
+			return score
+		}
+
+		if score > alpha {
+			alpha = score
+		}
+	}
+
+	return alpha
+}
+
+func (s *searcher) evaluate() int {
+	score := s.board.evaluate()
+
+	if s.noise > 0 {
+		// the same position always gets the same noise, so the search stays consistent
+		hash := (s.board.hash ^ s.seed) * 0x9e3779b97f4a7c15
+		score += int(hash>>33%uint64(2*s.noise+1)) - s.noise
+	}
+
+	return score
+}
+
+// orderMoves scores the moves so the most promising are searched first: the move from the transposition table,
+// captures of valuable pieces by cheap ones, killer moves and finally quiet moves that caused cutoffs before.
+func (s *searcher) orderMoves(moves []move, ttMove move, ply int) []int {
+	scores := make([]int, len(moves))
+
+	for i, m := range moves {
+		switch {
+		case m == ttMove:
+			scores[i] = 1 << 30
+		case !m.isQuiet():
+			victim := pieceKind(s.board.squares[m.to()])
+			if m.flags()&flagEnPassant != 0 {
+				victim = pawn
+			}
+
+			attacker := pieceKind(s.board.squares[m.from()])
+			scores[i] = 1<<20 + pieceValues[victim]*10 - pieceValues[attacker]/10 + pieceValues[m.promotion()]
+		case m == s.killers[ply][0]:
+			scores[i] = 1<<19 + 1
+		case m == s.killers[ply][1]:
+			scores[i] = 1 << 19
+		default:
+			scores[i] = s.history[m.from()][m.to()]
+		}
+	}
+
+	return scores
+}
+
+// pickMove moves the best of the remaining moves to the given index and returns it.
+func pickMove(moves []move, scores []int, index int) move {
+	best := index
+	for i := index + 1; i < len(moves); i++ {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	moves[index], moves[best] = moves[best], moves[index]
+	scores[index], scores[best] = scores[best], scores[index]
+
+	return moves[index]
+}
+
+func (s *searcher) probe() *transposition {
+	entry := &s.table[s.board.hash%transpositionTableSize]
+	if entry.key != s.board.hash {
+		return nil
+	}
+
+	return entry
+}
+
+func (s *searcher) store(depth int, score int, m move, bound int) {
+	entry := &s.table[s.board.hash%transpositionTableSize]
+
+	// keep the deeper result of the same position
+	if entry.key == s.board.hash && entry.depth > depth && bound != boundExact {
+		return
+	}
+
+	*entry = transposition{key: s.board.hash, move: m, score: score, depth: depth, bound: bound}
+}
+
+// scoreToTable stores mates as the distance from the position instead of from the root.
+func scoreToTable(score int, ply int) int {
+	switch {
+	case score > mateBound:
+		return score + ply
+	case score < -mateBound:
+		return score - ply
+	}
+
+	return score
+}
+
+func scoreFromTable(score int, ply int) int {
+	switch {
+	case score > mateBound:
+		return score - ply
+	case score < -mateBound:
+		return score + ply
+	}
+
+	return score
+}
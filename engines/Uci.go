@@ -0,0 +1,142 @@
+package engines
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var ErrEngineTimeout = errors.New("the engine did not answer in time")
+
+// defaultMoveTime is used when neither a depth nor a move time is given, so the search always ends.
+const defaultMoveTime = 1 * time.Second
+
+// uciGracePeriod is how long an engine may take to start up and report its move on top of the move time.
+const uciGracePeriod = 10 * time.Second
+
+// UciEngine runs a locally installed engine binary that speaks the Universal Chess Interface.
+// Every search starts a new process, so one engine can serve any number of games at once.
+type UciEngine struct {
+	path string
+}
+
+func NewUciEngine(path string) *UciEngine {
+	return &UciEngine{path: path}
+}
+
+func (e *UciEngine) BestMove(fen string, limits Limits) (Move, error) {
+	if limits.Depth == 0 && limits.MoveTime == 0 {
+		limits.MoveTime = defaultMoveTime
+	}
+
+	timeout := uciGracePeriod + limits.MoveTime
+	if limits.Depth > 0 && limits.MoveTime == 0 {
+		timeout += time.Minute
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.path)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return Move{}, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return Move{}, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return Move{}, err
+	}
+
+	defer func() {
+		_, _ = io.WriteString(stdin, "quit\n")
+		_ = stdin.Close()
+		_ = cmd.Wait()
+	}()
+
+	session := uciSession{in: stdin, out: bufio.NewScanner(stdout)}
+
+	session.send("uci")
+	if _, err := session.await("uciok"); err != nil {
+		return Move{}, e.explain(ctx, err)
+	}
+
+	session.send(fmt.Sprintf("setoption name Skill Level value %d", limits.Skill))
+	session.send("isready")
+	if _, err := session.await("readyok"); err != nil {
+		return Move{}, e.explain(ctx, err)
+	}
+
+	session.send("ucinewgame")
+	session.send("position fen " + fen)
+
+	search := "go"
+	if limits.Depth > 0 {
+		search += fmt.Sprintf(" depth %d", limits.Depth)
+	}
+	if limits.MoveTime > 0 {
+		search += fmt.Sprintf(" movetime %d", limits.MoveTime.Milliseconds())
+	}
+	session.send(search)
+
+	line, err := session.await("bestmove")
+	if err != nil {
+		return Move{}, e.explain(ctx, err)
+	}
+
+	// bestmove e2e4 ponder e7e5
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Move{}, ErrNoMove
+	}
+
+	move, ok := ParseMove(fields[1])
+	if !ok {
+		return Move{}, ErrNoMove
+	}
+
+	return move, nil
+}
+
+func (e *UciEngine) explain(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ErrEngineTimeout
+	}
+
+	return err
+}
+
+type uciSession struct {
+	in  io.Writer
+	out *bufio.Scanner
+}
+
+func (s *uciSession) send(command string) {
+	_, _ = io.WriteString(s.in, command+"\n")
+}
+
+// await reads the output of the engine until a line starts with the given token and returns that line.
+func (s *uciSession) await(token string) (string, error) {
+	for s.out.Scan() {
+		line := strings.TrimSpace(s.out.Text())
+		if line == token || strings.HasPrefix(line, token+" ") {
+			return line, nil
+		}
+	}
+
+	if err := s.out.Err(); err != nil {
+		return "", err
+	}
+
+	return "", io.ErrUnexpectedEOF
+}
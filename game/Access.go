@@ -0,0 +1,43 @@
+package game
+
+import (
+	"crypto/subtle"
+	"github.com/racccoooon/chess-be/constants"
+)
+
+// InviteVerifier checks the signature and the expiry of an invite and returns the game and the role it was
+// issued for, or false if it grants nothing.
+type InviteVerifier func(invite string) (Id, int, bool)
+
+// SetInviteVerifier sets how the invites to private games are checked. Without a verifier, invites grant nothing.
+func (g *Manager) SetInviteVerifier(verifier InviteVerifier) {
+	g.inviteVerifier = verifier
+}
+
+// IsPrivate tells whether only players and people with the password or an invite may see the game.
+func (g *Game) IsPrivate() bool {
+	return g.private
+}
+
+// Admits tells whether someone who is not a player of the game may take a seat or watch it, depending on the role.
+// Private games need the password or a valid invite for the game. A seat invite allows watching as well.
+func (g *Game) Admits(role int, password string, invite string) bool {
+	if !g.private {
+		return true
+	}
+
+	if g.password != "" && subtle.ConstantTimeCompare([]byte(g.password), []byte(password)) == 1 {
+		return true
+	}
+
+	if invite == "" || g.manager == nil || g.manager.inviteVerifier == nil {
+		return false
+	}
+
+	gameId, inviteRole, ok := g.manager.inviteVerifier(invite)
+	if !ok || gameId != g.id {
+		return false
+	}
+
+	return inviteRole == role || inviteRole == constants.SeatInvite
+}
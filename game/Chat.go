@@ -0,0 +1,178 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxChatMessageLength = 140
+
+// maxChatHistory is how many messages of each room a game keeps, older messages are dropped.
+const maxChatHistory = 200
+
+// ChatFilter checks a chat message before it is sent. It returns the text to send, which may be censored,
+// or false if the message must not be sent at all.
+type ChatFilter func(text string) (string, bool)
+
+// ChatMessage is a message in the chat of a game. Players write in the player room and spectators in
+// the spectator room, so the color is only set for messages in the player room.
+type ChatMessage struct {
+	room     int
+	name     string
+	color    int
+	text     string
+	sendTime time.Time
+}
+
+func (m ChatMessage) Room() int {
+	return m.room
+}
+
+func (m ChatMessage) Name() string {
+	return m.name
+}
+
+func (m ChatMessage) Color() int {
+	return m.color
+}
+
+func (m ChatMessage) Text() string {
+	return m.text
+}
+
+func (m ChatMessage) SendTime() time.Time {
+	return m.sendTime
+}
+
+// SetChatFilter sets the filter every chat message of every game passes before it is sent.
+func (g *Manager) SetChatFilter(filter ChatFilter) {
+	g.chatFilter = filter
+}
+
+// Chat adds a message of the player to the player room of the game. Empty, overly long and filtered messages
+// are rejected with InvalidMessage, players who write too often with RateLimited.
+func (g *Game) Chat(player *Player, text string) (ChatMessage, int) {
+	g.lock()
+	defer g.unlock()
+
+	return g.addChatMessage(player.token, ChatMessage{
+		room:  constants.PlayerRoom,
+		name:  player.name,
+		color: player.color,
+		text:  text,
+	})
+}
+
+// SpectatorChat adds a message of a spectator to the spectator room of the game, which the players do not see.
+// The sender is the token of the spectator, which the rate limit is counted for.
+func (g *Game) SpectatorChat(sender string, name string, text string) (ChatMessage, int) {
+	g.lock()
+	defer g.unlock()
+
+	return g.addChatMessage(sender, ChatMessage{
+		room: constants.SpectatorRoom,
+		name: name,
+		text: text,
+	})
+}
+
+func (g *Game) addChatMessage(sender string, message ChatMessage) (ChatMessage, int) {
+	text := strings.TrimSpace(message.text)
+	if text == "" || utf8.RuneCountInString(text) > maxChatMessageLength {
+		return ChatMessage{}, constants.InvalidMessage
+	}
+
+	if g.manager != nil && g.manager.chatFilter != nil {
+		var ok bool
+		if text, ok = g.manager.chatFilter(text); !ok {
+			return ChatMessage{}, constants.InvalidMessage
+		}
+	}
+
+	if g.manager != nil && !g.manager.chatLimiter.allow(sender) {
+		return ChatMessage{}, constants.RateLimited
+	}
+
+	message.text = text
+	message.sendTime = time.Now()
+
+	g.chat = append(g.chat, message)
+	g.dropOldChatMessages(message.room)
+	g.notifyChat(message)
+
+	return message, constants.ChatAllowed
+}
+
+// dropOldChatMessages removes the oldest messages of the room beyond maxChatHistory.
+func (g *Game) dropOldChatMessages(room int) {
+	count := 0
+	for _, message := range g.chat {
+		if message.room == room {
+			count++
+		}
+	}
+
+	if count <= maxChatHistory {
+		return
+	}
+
+	for i, message := range g.chat {
+		if message.room == room {
+			g.chat = append(g.chat[:i], g.chat[i+1:]...)
+			return
+		}
+	}
+}
+
+// ChatMessages returns the messages of a room, oldest first.
+func (g *Game) ChatMessages(room int) []ChatMessage {
+	g.lock()
+	defer g.unlock()
+
+	var messages []ChatMessage
+	for _, message := range g.chat {
+		if message.room == room {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// PlayerChatMessages returns the messages of the player room the player of the given color sees, oldest first.
+func (g *Game) PlayerChatMessages(color int) []ChatMessage {
+	g.lock()
+	defer g.unlock()
+
+	var messages []ChatMessage
+	for _, message := range g.chat {
+		if message.room == constants.PlayerRoom && g.seesChatMessage(color, message) {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// SeesChatMessage tells whether the player of the given color sees a message of the player room. Players always
+// see their own messages and the messages of their opponent unless they muted them.
+func (g *Game) SeesChatMessage(color int, message ChatMessage) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.seesChatMessage(color, message)
+}
+
+func (g *Game) seesChatMessage(color int, message ChatMessage) bool {
+	return message.color == color || !g.muted[color]
+}
+
+// MuteOpponent hides the chat messages of the opponent from the player of the given color, or shows them again.
+func (g *Game) MuteOpponent(color int, muted bool) {
+	g.lock()
+	defer g.unlock()
+
+	g.muted[color] = muted
+}
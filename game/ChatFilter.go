@@ -0,0 +1,47 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NewWordFilter returns a chat filter that replaces the given words with asterisks, ignoring case.
+// Only whole words are replaced, so a blocked word inside a longer word is kept.
+func NewWordFilter(words []string) ChatFilter {
+	blocked := make(map[string]bool)
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			blocked[strings.ToLower(word)] = true
+		}
+	}
+
+	return func(text string) (string, bool) {
+		runes := []rune(text)
+
+		for start := 0; start < len(runes); {
+			if !isWordRune(runes[start]) {
+				start++
+				continue
+			}
+
+			end := start
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+
+			if blocked[strings.ToLower(string(runes[start:end]))] {
+				for i := start; i < end; i++ {
+					runes[i] = '*'
+				}
+			}
+
+			start = end
+		}
+
+		return string(runes), true
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
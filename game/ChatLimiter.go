@@ -0,0 +1,62 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// a sender may write chatRateLimit messages within chatRateWindow, across all games
+const (
+	chatRateLimit  = 5
+	chatRateWindow = 10 * time.Second
+)
+
+// chatLimiter counts the chat messages of each sender within the rate window. Senders are identified by their
+// token, so reconnecting does not reset their count.
+type chatLimiter struct {
+	sent  map[string][]time.Time
+	mutex sync.Mutex
+}
+
+func newChatLimiter() *chatLimiter {
+	return &chatLimiter{
+		sent: make(map[string][]time.Time),
+	}
+}
+
+// allow records a message of the sender and tells whether it is within the rate limit.
+func (l *chatLimiter) allow(sender string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+
+	var recent []time.Time
+	for _, sent := range l.sent[sender] {
+		if now.Sub(sent) < chatRateWindow {
+			recent = append(recent, sent)
+		}
+	}
+
+	if len(recent) >= chatRateLimit {
+		l.sent[sender] = recent
+		return false
+	}
+
+	l.sent[sender] = append(recent, now)
+
+	return true
+}
+
+// forgetIdle drops the senders who did not write within the rate window.
+func (l *chatLimiter) forgetIdle() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	for sender, sent := range l.sent {
+		if len(sent) == 0 || now.Sub(sent[len(sent)-1]) >= chatRateWindow {
+			delete(l.sent, sender)
+		}
+	}
+}
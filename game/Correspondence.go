@@ -0,0 +1,207 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"sort"
+	"time"
+)
+
+// Deadline returns when the active player of a correspondence game has to move, or false if there is no deadline
+// right now, because the game did not start, is over or the active player is on vacation.
+func (g *Game) Deadline() (time.Time, bool) {
+	g.lock()
+	defer g.unlock()
+
+	if g.deadline.IsZero() || g.isOver() {
+		return time.Time{}, false
+	}
+
+	return g.deadline, true
+}
+
+func (g *Game) IsOnVacation(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.isOnVacation(color)
+}
+
+func (g *Game) isOnVacation(color int) bool {
+	return !g.vacationStart[color].IsZero()
+}
+
+// VacationLeft returns how much of their vacation the player of the given color has not taken yet.
+func (g *Game) VacationLeft(color int) time.Duration {
+	g.lock()
+	defer g.unlock()
+
+	return g.vacationLeft(color)
+}
+
+func (g *Game) vacationLeft(color int) time.Duration {
+	left := g.timeControl.vacation - g.vacationUsed[color]
+	if g.isOnVacation(color) {
+		left -= time.Since(g.vacationStart[color])
+	}
+
+	if left < 0 {
+		return 0
+	}
+
+	return left
+}
+
+// StartVacation pauses the deadlines of the player of the given color until the vacation ends or is used up.
+func (g *Game) StartVacation(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.startVacation(color)
+}
+
+func (g *Game) startVacation(color int) bool {
+	if !g.timeControl.IsCorrespondence() || g.isOver() || g.isOnVacation(color) || g.vacationLeft(color) == 0 {
+		return false
+	}
+
+	now := time.Now()
+	g.vacationStart[color] = now
+
+	if g.activeColor() == color && !g.deadline.IsZero() {
+		g.pausedTime = g.deadline.Sub(now)
+		g.deadline = time.Time{}
+	}
+
+	return true
+}
+
+func (g *Game) EndVacation(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.returnFromVacation(color)
+}
+
+func (g *Game) returnFromVacation(color int) bool {
+	if !g.isOnVacation(color) {
+		return false
+	}
+
+	g.endVacation(color, time.Now())
+
+	return true
+}
+
+func (g *Game) endVacation(color int, at time.Time) {
+	g.vacationUsed[color] += at.Sub(g.vacationStart[color])
+	g.vacationStart[color] = time.Time{}
+
+	if g.activeColor() == color && g.pausedTime > 0 {
+		g.deadline = at.Add(g.pausedTime)
+		g.pausedTime = 0
+	}
+}
+
+// resetDeadline gives the active player of a correspondence game the days per move. If they are on vacation,
+// the time starts when they are back.
+func (g *Game) resetDeadline() {
+	if !g.timeControl.IsCorrespondence() {
+		return
+	}
+
+	moveTime := time.Duration(g.timeControl.daysPerMove) * 24 * time.Hour
+
+	if g.isOnVacation(g.activeColor()) {
+		g.deadline = time.Time{}
+		g.pausedTime = moveTime
+		return
+	}
+
+	g.deadline = time.Now().Add(moveTime)
+	g.pausedTime = 0
+}
+
+// checkDeadline ends vacations that are used up and ends the game if the active player missed their deadline.
+func (g *Game) checkDeadline() bool {
+	if g.isOver() {
+		return false
+	}
+
+	for _, color := range []int{constants.White, constants.Black} {
+		if g.isOnVacation(color) && g.vacationLeft(color) == 0 {
+			g.endVacation(color, g.vacationStart[color].Add(g.timeControl.vacation-g.vacationUsed[color]))
+		}
+	}
+
+	if g.deadline.IsZero() || time.Now().Before(g.deadline) {
+		return false
+	}
+
+	g.end(constants.WinFor(constants.GetOppositeColor(g.activeColor())), constants.Timeout)
+
+	return true
+}
+
+// GetTurns returns the running correspondence games in which it is the turn of the player with the token,
+// the most urgent first. Games whose deadline is paused by a vacation come last.
+func (g *Manager) GetTurns(token string) []*Game {
+	var games []*Game
+	deadlines := make(map[Id]time.Time)
+
+	for _, game := range g.snapshot() {
+		if !game.timeControl.IsCorrespondence() {
+			continue
+		}
+
+		game.lock()
+		if player := game.playerByToken(token); player != nil && !game.isOver() && len(game.players) == 2 &&
+			player.color == game.activeColor() {
+			games = append(games, game)
+			deadlines[game.id] = game.deadline
+		}
+		game.unlock()
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		a, b := deadlines[games[i].id], deadlines[games[j].id]
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+
+		return a.Before(b)
+	})
+
+	return games
+}
+
+// StartVacation starts the vacation of the player with the token in all of their running correspondence games
+// and returns the games in which it started.
+func (g *Manager) StartVacation(token string) []PlayerGame {
+	return g.forCorrespondenceGames(token, (*Game).startVacation)
+}
+
+// EndVacation ends the vacation of the player with the token in all of their games and returns these games.
+func (g *Manager) EndVacation(token string) []PlayerGame {
+	return g.forCorrespondenceGames(token, (*Game).returnFromVacation)
+}
+
+func (g *Manager) forCorrespondenceGames(token string, action func(game *Game, color int) bool) []PlayerGame {
+	var playerGames []PlayerGame
+
+	for _, game := range g.snapshot() {
+		if !game.timeControl.IsCorrespondence() {
+			continue
+		}
+
+		game.lock()
+		if player := game.playerByToken(token); player != nil && action(game, player.color) {
+			playerGames = append(playerGames, PlayerGame{
+				color: player.color,
+				id:    game.id,
+			})
+		}
+		game.unlock()
+	}
+
+	return playerGames
+}
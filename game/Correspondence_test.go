@@ -0,0 +1,289 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"testing"
+	"time"
+)
+
+const (
+	testDaysPerMove  = 3
+	testVacationDays = 14
+	day              = 24 * time.Hour
+)
+
+// newTestGame returns a game between two players, with the tokens white and black.
+func newTestGame(timeControl TimeControl) *Game {
+	g := NewGameManager().NewGame(Settings{
+		FirstPlayerColor: constants.White,
+		StartingColor:    constants.White,
+		TimeControl:      timeControl,
+	})
+	g.AddPlayer("White", "white", "")
+	g.AddPlayer("Black", "black", "")
+
+	return g
+}
+
+func newCorrespondenceGame() *Game {
+	return newTestGame(NewCorrespondenceTimeControl(testDaysPerMove, testVacationDays))
+}
+
+// playFirstMove plays e2e4.
+func playFirstMove(t *testing.T, g *Game) {
+	t.Helper()
+
+	if move, reason := g.Move(constants.White, 4, 1, 4, 3, nil); move == nil {
+		t.Fatalf("e2e4 was rejected: %s", constants.MoveErrorAsString(reason))
+	}
+}
+
+func TestCorrespondenceDeadline(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(t *testing.T, g *Game)
+		wantDeadline time.Duration // from now, if there is a deadline
+		wantOk       bool
+		wantResult   int
+	}{
+		{
+			name:         "the first player gets the days per move",
+			setup:        func(t *testing.T, g *Game) {},
+			wantDeadline: testDaysPerMove * day,
+			wantOk:       true,
+		},
+		{
+			name: "a move gives the opponent the days per move",
+			setup: func(t *testing.T, g *Game) {
+				g.deadline = time.Now().Add(time.Hour)
+				playFirstMove(t, g)
+			},
+			wantDeadline: testDaysPerMove * day,
+			wantOk:       true,
+		},
+		{
+			name: "the active player on vacation has no deadline",
+			setup: func(t *testing.T, g *Game) {
+				g.StartVacation(constants.White)
+			},
+		},
+		{
+			name: "the waiting player on vacation does not pause the deadline",
+			setup: func(t *testing.T, g *Game) {
+				g.StartVacation(constants.Black)
+			},
+			wantDeadline: testDaysPerMove * day,
+			wantOk:       true,
+		},
+		{
+			name: "a move to a player on vacation pauses the deadline",
+			setup: func(t *testing.T, g *Game) {
+				g.StartVacation(constants.Black)
+				playFirstMove(t, g)
+			},
+		},
+		{
+			name: "the rest of the time continues after a vacation",
+			setup: func(t *testing.T, g *Game) {
+				g.deadline = time.Now().Add(2 * time.Hour)
+				g.StartVacation(constants.White)
+				g.EndVacation(constants.White)
+			},
+			wantDeadline: 2 * time.Hour,
+			wantOk:       true,
+		},
+		{
+			name: "a used up vacation ends when it ran out",
+			setup: func(t *testing.T, g *Game) {
+				g.deadline = time.Now().Add(2 * time.Hour)
+				g.StartVacation(constants.White)
+				g.vacationStart[constants.White] = time.Now().Add(-testVacationDays*day - time.Hour)
+				g.CheckTimeout()
+			},
+			wantDeadline: time.Hour,
+			wantOk:       true,
+		},
+		{
+			name: "a missed deadline loses the game",
+			setup: func(t *testing.T, g *Game) {
+				g.deadline = time.Now().Add(-time.Second)
+				if !g.CheckTimeout() {
+					t.Error("the missed deadline was not detected")
+				}
+			},
+			wantResult: constants.BlackWon,
+		},
+		{
+			name: "a used up vacation can lose the game",
+			setup: func(t *testing.T, g *Game) {
+				g.deadline = time.Now().Add(2 * time.Hour)
+				g.StartVacation(constants.White)
+				g.vacationStart[constants.White] = time.Now().Add(-testVacationDays*day - 3*time.Hour)
+				g.CheckTimeout()
+			},
+			wantResult: constants.BlackWon,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newCorrespondenceGame()
+			test.setup(t, g)
+
+			deadline, ok := g.Deadline()
+			if ok != test.wantOk {
+				t.Fatalf("has deadline = %v, want %v", ok, test.wantOk)
+			}
+
+			if ok {
+				if difference := time.Until(deadline) - test.wantDeadline; difference < -time.Minute || difference > time.Minute {
+					t.Errorf("deadline in %v, want %v", time.Until(deadline), test.wantDeadline)
+				}
+			}
+
+			if g.Result() != test.wantResult {
+				t.Errorf("result = %s, want %s", constants.ResultAsString(g.Result()), constants.ResultAsString(test.wantResult))
+			}
+		})
+	}
+}
+
+func TestStartVacation(t *testing.T) {
+	tests := []struct {
+		name     string
+		game     func() *Game
+		setup    func(g *Game)
+		want     bool
+		wantLeft time.Duration // the vacation left afterwards
+	}{
+		{
+			name:     "correspondence game",
+			game:     newCorrespondenceGame,
+			setup:    func(g *Game) {},
+			want:     true,
+			wantLeft: testVacationDays * day,
+		},
+		{
+			name: "part of the vacation was taken",
+			game: newCorrespondenceGame,
+			setup: func(g *Game) {
+				g.vacationUsed[constants.White] = 4 * day
+			},
+			want:     true,
+			wantLeft: (testVacationDays - 4) * day,
+		},
+		{
+			name: "the vacation was used up",
+			game: newCorrespondenceGame,
+			setup: func(g *Game) {
+				g.vacationUsed[constants.White] = testVacationDays * day
+			},
+		},
+		{
+			name: "already on vacation",
+			game: newCorrespondenceGame,
+			setup: func(g *Game) {
+				g.StartVacation(constants.White)
+			},
+			wantLeft: testVacationDays * day,
+		},
+		{
+			name: "the game is over",
+			game: newCorrespondenceGame,
+			setup: func(g *Game) {
+				g.Resign(constants.Black)
+			},
+			wantLeft: testVacationDays * day,
+		},
+		{
+			name: "game with clocks",
+			game: func() *Game {
+				return newTestGame(NewTimeControl(5*time.Minute, 0))
+			},
+			setup: func(g *Game) {},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := test.game()
+			test.setup(g)
+
+			if got := g.StartVacation(constants.White); got != test.want {
+				t.Errorf("StartVacation() = %v, want %v", got, test.want)
+			}
+
+			if difference := g.VacationLeft(constants.White) - test.wantLeft; difference < -time.Minute || difference > time.Minute {
+				t.Errorf("vacation left = %v, want %v", g.VacationLeft(constants.White), test.wantLeft)
+			}
+		})
+	}
+}
+
+func TestCorrespondenceGamesCannotBeClaimed(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeControl TimeControl
+		want        bool
+	}{
+		{name: "game with clocks", timeControl: NewTimeControl(5*time.Minute, 0), want: true},
+		{name: "correspondence game", timeControl: NewCorrespondenceTimeControl(testDaysPerMove, testVacationDays)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newTestGame(test.timeControl)
+			g.GetPlayerByColor(constants.Black).disconnectTime = time.Now().Add(-time.Hour)
+
+			if _, ok := g.ClaimableTime(constants.White); ok != test.want {
+				t.Errorf("claimable = %v, want %v", ok, test.want)
+			}
+
+			if got := g.ClaimVictory(constants.White); got != test.want {
+				t.Errorf("ClaimVictory() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeControl TimeControl
+		players     int
+		created     time.Duration // ago
+		ended       time.Duration // ago, zero if the game is running
+		want        bool
+	}{
+		{name: "new game", timeControl: NewTimeControl(5*time.Minute, 0), players: 2, want: false},
+		{name: "old game", timeControl: NewTimeControl(5*time.Minute, 0), players: 2, created: 2 * day, want: true},
+		{name: "running correspondence game", timeControl: NewCorrespondenceTimeControl(testDaysPerMove, testVacationDays), players: 2, created: 30 * day, want: false},
+		{name: "correspondence game without opponent", timeControl: NewCorrespondenceTimeControl(testDaysPerMove, testVacationDays), players: 1, created: 2 * day, want: true},
+		{name: "correspondence game that just ended", timeControl: NewCorrespondenceTimeControl(testDaysPerMove, testVacationDays), players: 2, created: 30 * day, ended: time.Hour, want: false},
+		{name: "correspondence game that ended long ago", timeControl: NewCorrespondenceTimeControl(testDaysPerMove, testVacationDays), players: 2, created: 30 * day, ended: 2 * day, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGameManager().NewGame(Settings{
+				FirstPlayerColor: constants.White,
+				StartingColor:    constants.White,
+				TimeControl:      test.timeControl,
+			})
+			for i := 0; i < test.players; i++ {
+				g.AddPlayer("Player", string(rune('a'+i)), "")
+			}
+
+			g.createTime = time.Now().Add(-test.created)
+
+			if test.ended > 0 {
+				g.Resign(constants.Black)
+				g.endTime = time.Now().Add(-test.ended)
+			}
+
+			if got := g.isExpired(); got != test.want {
+				t.Errorf("isExpired() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
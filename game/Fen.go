@@ -0,0 +1,219 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"strconv"
+	"strings"
+)
+
+// Fen describes the current position in Forsyth-Edwards Notation, which is how positions are handed to engines.
+func (g *Game) Fen() string {
+	g.lock()
+	defer g.unlock()
+
+	return g.fen()
+}
+
+// Position returns the current position in Forsyth-Edwards Notation together with the number of moves played,
+// so a move found for the position can be played with MoveAfter.
+func (g *Game) Position() (string, int) {
+	g.lock()
+	defer g.unlock()
+
+	return g.fen(), len(g.moves)
+}
+
+func (g *Game) fen() string {
+	var fen strings.Builder
+
+	for y := 7; y >= 0; y-- {
+		empty := 0
+
+		for x := 0; x < 8; x++ {
+			piece := g.GetPieceAt(x, y)
+			if piece == nil {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				fen.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+
+			fen.WriteString(pieceLetter(*piece))
+		}
+
+		if empty > 0 {
+			fen.WriteString(strconv.Itoa(empty))
+		}
+
+		if y > 0 {
+			fen.WriteString("/")
+		}
+	}
+
+	if g.activeColor() == constants.White {
+		fen.WriteString(" w ")
+	} else {
+		fen.WriteString(" b ")
+	}
+
+	fen.WriteString(g.castlingRights())
+	fen.WriteString(" ")
+	fen.WriteString(g.enPassantSquare())
+	fen.WriteString(" ")
+	fen.WriteString(strconv.Itoa(g.halfmoveClock()))
+	fen.WriteString(" ")
+	fen.WriteString(strconv.Itoa(g.moveCount(constants.Black) + 1))
+
+	return fen.String()
+}
+
+func pieceLetter(piece Piece) string {
+	letter := "p"
+
+	switch piece.type_ {
+	case constants.Rook:
+		letter = "r"
+	case constants.Knight:
+		letter = "n"
+	case constants.Bishop:
+		letter = "b"
+	case constants.Queen:
+		letter = "q"
+	case constants.King:
+		letter = "k"
+	}
+
+	if piece.color == constants.White {
+		return strings.ToUpper(letter)
+	}
+
+	return letter
+}
+
+func (g *Game) castlingRights() string {
+	rights := ""
+
+	for _, color := range []int{constants.White, constants.Black} {
+		homeRow := 0
+		if color == constants.Black {
+			homeRow = 7
+		}
+
+		king := g.GetPieceAt(4, homeRow)
+		if king == nil || king.type_ != constants.King || king.color != color || king.hasMoved {
+			continue
+		}
+
+		for _, side := range []struct {
+			rookX  int
+			letter string
+		}{{7, "k"}, {0, "q"}} {
+			rook := g.GetPieceAt(side.rookX, homeRow)
+			if rook == nil || rook.type_ != constants.Rook || rook.color != color || rook.hasMoved {
+				continue
+			}
+
+			if color == constants.White {
+				rights += strings.ToUpper(side.letter)
+			} else {
+				rights += side.letter
+			}
+		}
+	}
+
+	if rights == "" {
+		return "-"
+	}
+
+	return rights
+}
+
+// enPassantSquare returns the square a pawn skipped with its double step in the last move.
+func (g *Game) enPassantSquare() string {
+	lastMove := g.lastMove()
+	if lastMove == nil || lastMove.t != constants.Pawn || abs(lastMove.toY-lastMove.fromY) != 2 {
+		return "-"
+	}
+
+	return string(rune('a'+lastMove.toX)) + string(rune('1'+(lastMove.fromY+lastMove.toY)/2))
+}
+
+// halfmoveClock counts the moves since the last capture or pawn move.
+func (g *Game) halfmoveClock() int {
+	count := 0
+
+	for i := len(g.moves) - 1; i >= 0; i-- {
+		move := g.moves[i]
+		if move.captures || move.t == constants.Pawn || move.kind == constants.Promotion {
+			break
+		}
+
+		count++
+	}
+
+	return count
+}
+
+// InitialFen describes the position the game started from.
+func (g *Game) InitialFen() string {
+	return g.replay().Fen()
+}
+
+// Positions replays the game from its initial position and returns the position before every move,
+// followed by the current position.
+func (g *Game) Positions() []string {
+	g.lock()
+	defer g.unlock()
+
+	replay := g.replay()
+
+	positions := make([]string, 0, len(g.moves)+1)
+
+	for _, move := range g.moves {
+		positions = append(positions, replay.Fen())
+		replay.replayMove(move)
+	}
+
+	return append(positions, replay.Fen())
+}
+
+// PositionAfter returns the pieces and the active color after the first plies moves of the game.
+func (g *Game) PositionAfter(plies int) ([]Piece, int) {
+	g.lock()
+	defer g.unlock()
+
+	replay := g.replay()
+
+	for _, move := range g.moves[:plies] {
+		replay.replayMove(move)
+	}
+
+	return replay.pieces, replay.activeColor()
+}
+
+// replay creates a game without players, clocks or listeners in the initial position of the game.
+func (g *Game) replay() *Game {
+	replay := &Game{
+		turn:          g.startingColor,
+		startingColor: g.startingColor,
+		spectators:    make(map[string]bool),
+		drawOffer:     noDrawOffer,
+	}
+
+	replay.pieces = append(replay.pieces, g.initial...)
+
+	return replay
+}
+
+func (g *Game) replayMove(move Move) {
+	var promoteToType *string
+	if move.kind == constants.Promotion {
+		promotionType := constants.TypeAsString(move.promoteToType)
+		promoteToType = &promotionType
+	}
+
+	g.Move(move.color, move.fromX, move.fromY, move.toX, move.toY, promoteToType)
+}
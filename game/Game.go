@@ -1,20 +1,36 @@
 package game
 
 import (
+	"errors"
 	"fmt"
 	"github.com/racccoooon/chess-be/constants"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 )
 
+var (
+	ErrGameFull      = errors.New("the game already has two players")
+	ErrAlreadySeated = errors.New("the player already plays in the game")
+)
+
 type Manager struct {
 	games map[Id]*Game
+	mutex sync.RWMutex
+
+	listeners       listeners
+	chatFilter      ChatFilter
+	chatLimiter     *chatLimiter
+	inviteVerifier  InviteVerifier
+	disconnectGrace time.Duration
 }
 
 func NewGameManager() *Manager {
 	return &Manager{
-		games: make(map[Id]*Game),
+		games:           make(map[Id]*Game),
+		chatLimiter:     newChatLimiter(),
+		disconnectGrace: DefaultDisconnectGrace,
 	}
 }
 
@@ -34,15 +50,34 @@ func (p PlayerGame) Id() Id {
 func (g *Manager) GetGamesForPlayer(token string) []PlayerGame {
 	var playerGames []PlayerGame
 
-	for _, game := range g.games {
-		for _, player := range game.players {
-			if player.token == token {
-				playerGames = append(playerGames, PlayerGame{
-					color: player.color,
-					id:    game.id,
-				})
-			}
+	for _, game := range g.snapshot() {
+		game.lock()
+		if player := game.playerByToken(token); player != nil {
+			playerGames = append(playerGames, PlayerGame{
+				color: player.color,
+				id:    game.id,
+			})
+		}
+		game.unlock()
+	}
+
+	return playerGames
+}
+
+// RenamePlayer changes the name of the player with the token in all games and returns these games.
+func (g *Manager) RenamePlayer(token string, name string) []PlayerGame {
+	var playerGames []PlayerGame
+
+	for _, game := range g.snapshot() {
+		game.lock()
+		if player := game.playerByToken(token); player != nil {
+			player.name = name
+			playerGames = append(playerGames, PlayerGame{
+				color: player.color,
+				id:    game.id,
+			})
 		}
+		game.unlock()
 	}
 
 	return playerGames
@@ -51,7 +86,7 @@ func (g *Manager) GetGamesForPlayer(token string) []PlayerGame {
 func (g *Manager) GetGames() []*Game {
 	var games []*Game
 
-	for _, game := range g.games {
+	for _, game := range g.snapshot() {
 		if game.public {
 			games = append(games, game)
 		}
@@ -83,21 +118,81 @@ func generateRandomString(length int) string {
 	return string(b)
 }
 
+// snapshot returns all games, so they can be iterated without holding the lock while listeners run.
+func (g *Manager) snapshot() []*Game {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	games := make([]*Game, 0, len(g.games))
+	for _, game := range g.games {
+		games = append(games, game)
+	}
+
+	return games
+}
+
+// gameLifetime is how long a game is kept after it was created, or after a correspondence game ended.
+const gameLifetime = 24 * time.Hour
+
+// Cleanup removes the games that outlived their lifetime.
 func (g *Manager) Cleanup() {
-	for id, game := range g.games {
-		if time.Since(game.createTime) > 24*time.Hour {
-			delete(g.games, id)
+	var removed []*Game
+
+	// the games are checked before the map is locked, so a game is never locked while the map is
+	for _, game := range g.snapshot() {
+		if game.isExpired() {
+			removed = append(removed, game)
 		}
 	}
+
+	g.mutex.Lock()
+	for _, game := range removed {
+		delete(g.games, game.id)
+	}
+	g.mutex.Unlock()
+
+	for _, game := range removed {
+		g.notifyGameRemoved(game)
+	}
+
+	g.chatLimiter.forgetIdle()
 }
 
-func (g *Manager) NewGame(firstPlayerColor int, startingPieces []Piece, startingColor int, public bool) *Game {
+// isExpired tells whether the game may be removed. Correspondence games take weeks, so once they started they are
+// kept until they are over for the lifetime.
+func (g *Game) isExpired() bool {
+	g.lock()
+	defer g.unlock()
+
+	if g.timeControl.IsCorrespondence() && len(g.players) == 2 {
+		return g.isOver() && time.Since(g.endTime) > gameLifetime
+	}
+
+	return time.Since(g.createTime) > gameLifetime
+}
+
+// Settings describe how a game is set up when it is created.
+type Settings struct {
+	FirstPlayerColor int
+	StartingPieces   []Piece
+	StartingColor    int
+	Public           bool
+	Private          bool   // private games can only be joined and watched with the password or an invite
+	Password         string // optional for private games
+	TimeControl      TimeControl
+	Rated            bool
+	Armageddon       bool
+	AllowBerserk     bool
+	SpectatorPolicy  int
+	BroadcastDelay   BroadcastDelay // holds back what spectators see of the running game
+}
+
+func (g *Manager) NewGame(settings Settings) *Game {
 	game := &Game{
-		id:               g.newGameId(),
-		firstPlayerColor: firstPlayerColor,
+		firstPlayerColor: settings.FirstPlayerColor,
 
-		turn:          startingColor,
-		startingColor: startingColor,
+		turn:          settings.StartingColor,
+		startingColor: settings.StartingColor,
 
 		players: make([]*Player, 0),
 		pieces:  make([]Piece, 0),
@@ -105,16 +200,48 @@ func (g *Manager) NewGame(firstPlayerColor int, startingPieces []Piece, starting
 
 		createTime: time.Now(),
 
-		public: public,
+		public:       settings.Public,
+		private:      settings.Private,
+		password:     settings.Password,
+		rated:        settings.Rated,
+		allowBerserk: settings.AllowBerserk && !settings.TimeControl.IsUnlimited(),
+
+		spectatorPolicy: settings.SpectatorPolicy,
+		broadcastDelay:  settings.BroadcastDelay,
+
+		variant:     constants.Standard,
+		timeControl: settings.TimeControl,
+		clock:       [2]time.Duration{settings.TimeControl.initial, settings.TimeControl.initial},
+		spectators:  make(map[string]bool),
+
+		drawOffer:    noDrawOffer,
+		rematchOffer: noRematchOffer,
+
+		manager: g,
+	}
+
+	if len(settings.StartingPieces) > 0 {
+		game.variant = constants.FromPosition
 	}
 
-	game.initializeBoard(startingPieces)
+	// black has less time in an armageddon game, but a draw counts as a win for black
+	if settings.Armageddon {
+		game.armageddon = true
+		game.clock[constants.Black] = settings.TimeControl.initial * 4 / 5
+	}
+
+	game.initializeBoard(settings.StartingPieces)
 
 	for i := range game.pieces {
 		game.initial = append(game.initial, game.pieces[i])
 	}
 
+	g.mutex.Lock()
+	game.id = g.newGameId()
 	g.games[game.id] = game
+	g.mutex.Unlock()
+
+	g.notifyGameCreated(game)
 
 	return game
 }
@@ -124,6 +251,9 @@ func (g *Game) InitialPieces() []Piece {
 }
 
 func (g *Manager) GetGame(id Id) *Game {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
 	if game, ok := g.games[id]; ok {
 		return game
 	}
@@ -133,21 +263,87 @@ func (g *Manager) GetGame(id Id) *Game {
 
 type Id string
 
+// Game is used by the hub connections, the REST handlers, the bots and the clock ticker at the same time.
+// The settings never change after the game was created. The exported methods that read or change the rest of
+// the state take the lock of the game, while the move rules expect their caller to hold it.
 type Game struct {
 	id               Id
 	firstPlayerColor int
+	startingColor    int
+	initial          []Piece
+	createTime       time.Time
 
-	turn          int
-	startingColor int
+	public       bool
+	private      bool
+	password     string
+	rated        bool
+	armageddon   bool
+	allowBerserk bool
+	variant      int
+	timeControl  TimeControl
+
+	spectatorPolicy int
+	broadcastDelay  BroadcastDelay
+
+	manager *Manager
+
+	mutex     sync.Mutex
+	pending   []func() // listener calls that run once the game is unlocked
+	notifying bool     // a goroutine is running the pending listener calls
+
+	turn int
 
 	players []*Player
 	pieces  []Piece
-	initial []Piece
 	moves   []Move
 
-	createTime time.Time
+	berserk    [2]bool
+	clock      [2]time.Duration
+	turnStart  time.Time
+	spectators map[string]bool
+	chat       []ChatMessage
+	muted      [2]bool
+
+	deadline      time.Time        // when the active player of a correspondence game has to move
+	pausedTime    time.Duration    // the time left for the move while the active player is on vacation
+	vacationStart [2]time.Time     // zero if the player is not on vacation
+	vacationUsed  [2]time.Duration // the vacation the player took before the current one
+
+	result      int
+	termination int
+	endTime     time.Time
+	drawOffer   int
+
+	rematchOffer    int
+	startingRematch bool // the rematch was accepted and is being created
+	previousGameId  Id
+	rematchGameId   Id
+}
 
-	public bool
+func (g *Game) lock() {
+	g.mutex.Lock()
+}
+
+// unlock releases the game and runs the listeners that were notified while it was locked, so they may use the game.
+// Only one goroutine runs the listeners of a game at a time, which keeps its events in order.
+func (g *Game) unlock() {
+	if g.notifying {
+		g.mutex.Unlock()
+		return
+	}
+
+	g.notifying = true
+	for len(g.pending) > 0 {
+		call := g.pending[0]
+		g.pending = g.pending[1:]
+
+		g.mutex.Unlock()
+		call()
+		g.mutex.Lock()
+	}
+	g.notifying = false
+
+	g.mutex.Unlock()
 }
 
 type Move struct {
@@ -161,16 +357,42 @@ type Move struct {
 	status        int
 	captures      bool
 	promoteToType int
+	playTime      time.Time
 }
 
 func (g *Game) Pieces() []Piece {
-	return g.pieces
+	g.lock()
+	defer g.unlock()
+
+	return append([]Piece(nil), g.pieces...)
 }
 
 func (g *Game) Name() string {
 	return string(g.id)
 }
 
+func (g *Game) CreateTime() time.Time {
+	return g.createTime
+}
+
+func (g *Game) IsPublic() bool {
+	return g.public
+}
+
+// IsRated tells whether the result of the game changes the ratings of the players.
+func (g *Game) IsRated() bool {
+	return g.rated
+}
+
+// IsArmageddon tells whether a draw counts as a win for black, who starts with less time in return.
+func (g *Game) IsArmageddon() bool {
+	return g.armageddon
+}
+
+func (g *Game) Variant() int {
+	return g.variant
+}
+
 func (Move *Move) Color() int {
 	return Move.color
 }
@@ -207,6 +429,10 @@ func (Move *Move) Captures() bool {
 	return Move.captures
 }
 
+func (Move *Move) PlayTime() time.Time {
+	return Move.playTime
+}
+
 type Piece struct {
 	color    int
 	type_    int
@@ -246,6 +472,13 @@ func (g *Game) Id() Id {
 }
 
 func (g *Game) ActiveColor() int {
+	g.lock()
+	defer g.unlock()
+
+	return g.activeColor()
+}
+
+func (g *Game) activeColor() int {
 	return g.turn % 2
 }
 
@@ -294,7 +527,22 @@ func (g *Game) initializeBoardWithDefaultPieces() {
 	}
 }
 
+// LastMove returns a copy of the last move, or nil if no move was played yet.
 func (g *Game) LastMove() *Move {
+	g.lock()
+	defer g.unlock()
+
+	move := g.lastMove()
+	if move == nil {
+		return nil
+	}
+
+	last := *move
+
+	return &last
+}
+
+func (g *Game) lastMove() *Move {
 	if len(g.moves) == 0 {
 		return nil
 	}
@@ -302,7 +550,18 @@ func (g *Game) LastMove() *Move {
 	return &g.moves[len(g.moves)-1]
 }
 
-func (g *Game) AddPlayer(name string, token string, connectionId string) *Player {
+func (g *Game) AddPlayer(name string, token string, connectionId string) (*Player, error) {
+	g.lock()
+	defer g.unlock()
+
+	if len(g.players) >= 2 {
+		return nil, ErrGameFull
+	}
+
+	if g.playerByToken(token) != nil {
+		return nil, ErrAlreadySeated
+	}
+
 	color := g.firstPlayerColor
 	if g.firstPlayerColor == constants.RandomColor {
 		rand.Seed(time.Now().UnixNano())
@@ -318,14 +577,33 @@ func (g *Game) AddPlayer(name string, token string, connectionId string) *Player
 		token:        token,
 		connectionId: connectionId,
 		color:        color,
+		game:         g,
 	}
 
 	g.players = append(g.players, player)
 
-	return player
+	if len(g.players) == 2 {
+		g.startClock()
+		g.resetDeadline()
+	}
+
+	g.notifyPlayerJoined(player)
+
+	if len(g.players) == 2 {
+		g.notifyTurn()
+	}
+
+	return player, nil
 }
 
 func (g *Game) GetPlayerByToken(token string) *Player {
+	g.lock()
+	defer g.unlock()
+
+	return g.playerByToken(token)
+}
+
+func (g *Game) playerByToken(token string) *Player {
 	for _, player := range g.players {
 		if player.token == token {
 			return player
@@ -335,7 +613,31 @@ func (g *Game) GetPlayerByToken(token string) *Player {
 	return nil
 }
 
+func (g *Game) GetPlayerByColor(color int) *Player {
+	g.lock()
+	defer g.unlock()
+
+	return g.playerByColor(color)
+}
+
+func (g *Game) playerByColor(color int) *Player {
+	for _, player := range g.players {
+		if player.color == color {
+			return player
+		}
+	}
+
+	return nil
+}
+
 func (g *Game) GetPlayerByConnectionId(connectionId string) *Player {
+	g.lock()
+	defer g.unlock()
+
+	return g.playerByConnectionId(connectionId)
+}
+
+func (g *Game) playerByConnectionId(connectionId string) *Player {
 	for _, player := range g.players {
 		if player.connectionId == connectionId {
 			return player
@@ -346,9 +648,13 @@ func (g *Game) GetPlayerByConnectionId(connectionId string) *Player {
 }
 
 func (g *Game) RejoinPlayer(token string, connectionId string) *Player {
+	g.lock()
+	defer g.unlock()
+
 	for _, player := range g.players {
 		if player.token == token {
 			player.connectionId = connectionId
+			player.disconnectTime = time.Time{}
 			return player
 		}
 	}
@@ -357,6 +663,9 @@ func (g *Game) RejoinPlayer(token string, connectionId string) *Player {
 }
 
 func (g *Game) OpponentName(color int) string {
+	g.lock()
+	defer g.unlock()
+
 	for _, player := range g.players {
 		if player.color != color {
 			return player.name
@@ -367,20 +676,30 @@ func (g *Game) OpponentName(color int) string {
 }
 
 func (g *Game) PlayerCount() int {
+	g.lock()
+	defer g.unlock()
+
 	return len(g.players)
 }
 
+// History returns a copy of the moves played so far.
 func (g *Game) History() []Move {
-	return g.moves
+	g.lock()
+	defer g.unlock()
+
+	return append([]Move(nil), g.moves...)
 }
 
 func (g *Game) GetValidMoves(fromX int, fromY int) []Move {
+	g.lock()
+	defer g.unlock()
+
 	piece := g.GetPieceAt(fromX, fromY)
 	if piece == nil {
 		return nil
 	}
 
-	if piece.color != g.ActiveColor() {
+	if piece.color != g.activeColor() {
 		return nil
 	}
 
@@ -403,7 +722,37 @@ func (g *Game) GetValidMoves(fromX int, fromY int) []Move {
 	return moves
 }
 
-func (g *Game) Move(fromX int, fromY int, toX int, toY int, promoteToType *string) bool {
+// Move plays the move for the player of the given color. It returns the move that was played,
+// or nil and the reason why the move is not allowed.
+func (g *Game) Move(color int, fromX int, fromY int, toX int, toY int, promoteToType *string) (*Move, int) {
+	g.lock()
+	defer g.unlock()
+
+	return g.move(color, fromX, fromY, toX, toY, promoteToType)
+}
+
+// MoveAfter plays the move only if the game still has the given number of moves, so a move that was found for
+// an earlier position is never played in a later one.
+func (g *Game) MoveAfter(plies int, color int, fromX int, fromY int, toX int, toY int, promoteToType *string) (*Move, int) {
+	g.lock()
+	defer g.unlock()
+
+	if len(g.moves) != plies {
+		return nil, constants.NotYourTurn
+	}
+
+	return g.move(color, fromX, fromY, toX, toY, promoteToType)
+}
+
+func (g *Game) move(color int, fromX int, fromY int, toX int, toY int, promoteToType *string) (*Move, int) {
+	if g.isOver() || g.checkTimeout() {
+		return nil, constants.GameIsOver
+	}
+
+	reason := g.ExplainMove(color, fromX, fromY, toX, toY, promoteToType)
+	if reason != constants.MoveAllowed {
+		return nil, reason
+	}
 
 	promotionType := constants.Pawn
 
@@ -412,20 +761,18 @@ func (g *Game) Move(fromX int, fromY int, toX int, toY int, promoteToType *strin
 	}
 
 	piece := g.GetPieceAt(fromX, fromY)
-	if piece == nil {
-		return false
-	}
 
-	if piece.color != g.ActiveColor() {
-		return false
-	}
+	_, moveType := g.IsMoveValid(*piece, toX, toY)
 
-	isValidMove, moveType := g.IsMoveValid(*piece, toX, toY)
-	if !isValidMove {
-		return false
+	captures := g.RemovePieceAt(toX, toY)
+
+	// the pawn captured en passant is next to the destination
+	if moveType == constants.EnPassant {
+		captures = g.RemovePieceAt(toX, fromY)
 	}
 
-	captures := g.RemovePieceAt(toX, toY)
+	// removing a piece reorders the board, so look the moving piece up again
+	piece = g.GetPieceAt(fromX, fromY)
 
 	piece.x = toX
 	piece.y = toY
@@ -443,34 +790,49 @@ func (g *Game) Move(fromX int, fromY int, toX int, toY int, promoteToType *strin
 
 	g.turn++
 
-	status := constants.IsNotCheck
-
-	if moveType != constants.Promotion && promotionType != constants.Pawn {
-		return false
-	}
+	g.chargeClock(piece.color)
+	g.resetDeadline()
 
 	if moveType == constants.Promotion {
 		piece.type_ = promotionType
 	}
 
-	// check if check
-	if g.IsInCheck(g.ActiveColor()) {
+	// the move is added before the status is determined, because en passant depends on the last move
+	move := Move{piece.color, piece.type_, fromX, fromY, toX, toY, moveType, constants.IsNotCheck, captures, promotionType, time.Now()}
+	g.moves = append(g.moves, move)
+
+	status := constants.IsNotCheck
+
+	if g.IsInCheck(g.activeColor()) {
 		status = constants.IsCheck
 	}
 
-	// check if checkmate
-	if g.IsInCheckmate(g.ActiveColor()) {
+	if g.IsInCheckmate(g.activeColor()) {
 		status = constants.IsCheckmate
+	} else if g.IsInStalemate(g.activeColor()) {
+		status = constants.IsStalemate
 	}
 
-	// check if stalemate
-	if g.IsInStalemate(g.ActiveColor()) {
-		status = constants.IsStalemate
+	move.status = status
+	g.moves[len(g.moves)-1].status = status
+
+	// a draw offer stands until the opponent moves
+	if g.drawOffer == g.activeColor() {
+		g.drawOffer = noDrawOffer
 	}
 
-	g.moves = append(g.moves, Move{piece.color, piece.type_, fromX, fromY, toX, toY, moveType, status, captures, promotionType})
+	g.notifyMove(move)
 
-	return true
+	switch status {
+	case constants.IsCheckmate:
+		g.end(constants.WinFor(piece.color), constants.Checkmate)
+	case constants.IsStalemate:
+		g.end(constants.Drawn, constants.Stalemate)
+	}
+
+	g.notifyTurn()
+
+	return &move, constants.MoveAllowed
 }
 
 func (g *Game) IsMoveValid(piece Piece, toX int, toY int) (bool, int) {
@@ -517,9 +879,15 @@ func (g *Game) IsMoveValid(piece Piece, toX int, toY int) (bool, int) {
 
 	// check if move puts own king in check
 	// temporarily move piece in clone
-	pieceRef := clone.GetPieceAt(piece.x, piece.y)
 	clone.RemovePieceAt(toX, toY)
 
+	if moveType == constants.EnPassant {
+		clone.RemovePieceAt(toX, piece.y)
+	}
+
+	// removing a piece reorders the board, so look the moving piece up afterwards
+	pieceRef := clone.GetPieceAt(piece.x, piece.y)
+
 	pieceRef.x = toX
 	pieceRef.y = toY
 
@@ -613,7 +981,7 @@ func (g *Game) IsPawnMoveValid(piece Piece, toX int, toY int) (bool, int) {
 	// if starting position, can move 2 squares
 	if !piece.hasMoved {
 		if piece.x == toX && piece.y+direction*2 == toY {
-			if isDestinationEmpty {
+			if isDestinationEmpty && g.GetPieceAt(toX, piece.y+direction) == nil {
 				return true, moveType
 			}
 		}
@@ -629,7 +997,7 @@ func (g *Game) IsPawnMoveValid(piece Piece, toX int, toY int) (bool, int) {
 	// can move 1 square diagonally
 	xDiff := abs(piece.x - toX)
 
-	isDestinationEnPassant := g.IsDestinationEnPassant(toX)
+	isDestinationEnPassant := g.IsDestinationEnPassant(piece, toX, toY)
 
 	if xDiff == 1 && piece.y+direction == toY {
 		if !isDestinationEmpty {
@@ -655,15 +1023,17 @@ func (g *Game) IsPromotionMove(piece Piece, toX int, toY int) bool {
 	return false
 }
 
-func (g *Game) IsDestinationEnPassant(toX int) bool {
+// IsDestinationEnPassant tells whether the pawn can capture en passant on the destination, which requires the
+// opponent to have moved a pawn two squares right before, next to the capturing pawn.
+func (g *Game) IsDestinationEnPassant(piece Piece, toX int, toY int) bool {
 	if len(g.moves) == 0 {
 		return false
 	}
 
 	lastMove := g.moves[len(g.moves)-1]
 
-	// has to be a pawn
-	if lastMove.t != constants.Pawn {
+	// has to be a pawn of the opponent
+	if lastMove.t != constants.Pawn || lastMove.color == piece.color {
 		return false
 	}
 
@@ -678,7 +1048,8 @@ func (g *Game) IsDestinationEnPassant(toX int) bool {
 		return false
 	}
 
-	return true
+	// has to land next to the capturing pawn, which moves to the square the pawn skipped
+	return lastMove.toY == piece.y && toY == (lastMove.fromY+lastMove.toY)/2
 }
 
 func (g *Game) IsRookMoveValid(piece Piece, toX int, toY int) bool {
@@ -906,154 +1277,31 @@ func (g *Game) IsInCheck(color int) bool {
 	return g.IsInCheckAt(king.x, king.y)
 }
 
+// IsInCheckmate tells whether the king of the color is in check and no move gets it out of check.
 func (g *Game) IsInCheckmate(color int) bool {
-	king := g.GetKing(color)
-
-	if king == nil {
-		return false
-	}
-
-	// check if king is in check
-	if !g.IsInCheck(color) {
-		return false
-	}
-
-	// check if king can move
-	for x := -1; x <= 1; x++ {
-		for y := -1; y <= 1; y++ {
-			targetX := king.x + x
-			targetY := king.y + y
-
-			isValidMove, _ := g.IsMoveValid(*king, targetX, targetY)
-			if isValidMove {
-				return false
-			}
-		}
-	}
-
-	// get all enemy pieces that check the king
-	checkingPieces := make([]Piece, 0)
-	for _, piece := range g.pieces {
-		opponentColor := constants.GetOppositeColor(color)
-		if piece.color == opponentColor && piece.type_ != constants.King {
-			// if piece can move onto the king
-			pieceIsCheckingKing, _ := g.IsMoveValid(piece, king.x, king.y)
-			if pieceIsCheckingKing {
-				checkingPieces = append(checkingPieces, piece)
-			}
-		}
-	}
-
-	// if there is more than one piece checking the king, then the king is in checkmate
-	if len(checkingPieces) > 1 {
-		return true
-	}
-
-	checkingPiece := checkingPieces[0]
-
-	// check if the piece checking the king can be captured
-	for _, piece := range g.pieces {
-		if piece.color == color {
-			isValidMove, _ := g.IsMoveValid(piece, checkingPiece.x, checkingPiece.y)
-			if isValidMove {
-				return false
-			}
-		}
-	}
-
-	// knights can't be blocked
-	if checkingPiece.type_ == constants.Knight {
-		return true
-	}
-
-	// check if it is in the same row
-	if checkingPiece.x == king.x {
-		// check if any player piece can be placed between the king and the checking piece
-		for y := min(checkingPiece.y, king.y) + 1; y < max(checkingPiece.y, king.y); y++ {
-			for _, piece := range g.pieces {
-				if piece.color == color && piece.type_ != constants.King {
-					isValidMove, _ := g.IsMoveValid(piece, checkingPiece.x, y)
-					if isValidMove {
-						return false
-					}
-				}
-			}
-		}
-
-		return true
-	}
-
-	// check if it is in the same column
-	if checkingPiece.y == king.y {
-		// check if any player piece can be placed between the king and the checking piece
-		for x := min(checkingPiece.x, king.x) + 1; x < max(checkingPiece.x, king.x); x++ {
-			for _, piece := range g.pieces {
-				if piece.color == color && piece.type_ != constants.King {
-					isValidMove, _ := g.IsMoveValid(piece, x, checkingPiece.y)
-					if isValidMove {
-						return false
-					}
-				}
-			}
-		}
-
-		return true
-	}
-
-	// check if it is in the same diagonal
-	if abs(checkingPiece.x-king.x) == abs(checkingPiece.y-king.y) {
-		startX := min(checkingPiece.x, king.x) + 1
-		startY := min(checkingPiece.y, king.y) + 1
-
-		diff := abs(checkingPiece.x-king.x) - 1
-
-		for i := 0; i < diff; i++ {
-			for _, piece := range g.pieces {
-				if piece.color == color && piece.type_ != constants.King {
-					isValidMove, _ := g.IsMoveValid(piece, startX+i, startY+i)
-					if isValidMove {
-						return false
-					}
-				}
-			}
-		}
-
-		return true
-	}
-
-	return true
+	return g.IsInCheck(color) && !g.hasValidMove(color)
 }
 
+// IsInStalemate tells whether the color is not in check but can not move.
 func (g *Game) IsInStalemate(color int) bool {
-	// check if any player piece can move
+	return !g.IsInCheck(color) && !g.hasValidMove(color)
+}
+
+func (g *Game) hasValidMove(color int) bool {
 	for _, piece := range g.pieces {
 		if piece.color == color {
 			for x := 0; x < 8; x++ {
 				for y := 0; y < 8; y++ {
 					isValidMove, _ := g.IsMoveValid(piece, x, y)
 					if isValidMove {
-						return false
+						return true
 					}
 				}
 			}
 		}
 	}
 
-	return true
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+	return false
 }
 
 func (g *Game) GetKing(color int) *Piece {
@@ -1068,7 +1316,7 @@ func (g *Game) GetKing(color int) *Piece {
 
 func (g *Game) IsInCheckAt(x int, y int) bool {
 	for _, piece := range g.pieces {
-		if piece.color != g.ActiveColor() && piece.type_ != constants.King {
+		if piece.color != g.activeColor() && piece.type_ != constants.King {
 			isValidMove, _ := g.IsMoveValid(piece, x, y)
 			if isValidMove {
 				return true
@@ -1102,7 +1350,7 @@ func (g *Game) RemovePieceAt(x int, y int) bool {
 }
 
 func (g *Game) Promote(t int) bool {
-	move := g.LastMove()
+	move := g.lastMove()
 
 	if move == nil {
 		return false
@@ -1129,9 +1377,17 @@ type Player struct {
 	token        string
 	connectionId string
 	color        int
+
+	disconnectTime time.Time // zero while the player is connected
+	abandoned      bool      // the listeners were told the player did not come back in time
+
+	game *Game // guards the name, the connection and the disconnect time
 }
 
 func (p *Player) Name() string {
+	p.game.lock()
+	defer p.game.unlock()
+
 	return p.name
 }
 
@@ -1143,6 +1399,15 @@ func (p *Player) Color() int {
 	return p.color
 }
 
+// ConnectionId returns the hub connection the player joined with, or an empty string for players who joined
+// over the REST api.
+func (p *Player) ConnectionId() string {
+	p.game.lock()
+	defer p.game.unlock()
+
+	return p.connectionId
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
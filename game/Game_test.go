@@ -0,0 +1,75 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"testing"
+	"time"
+)
+
+func TestAddPlayer(t *testing.T) {
+	tests := []struct {
+		name    string
+		seated  []string
+		token   string
+		wantErr error
+	}{
+		{name: "empty game", token: "white"},
+		{name: "second player", seated: []string{"white"}, token: "black"},
+		{name: "full game", seated: []string{"white", "black"}, token: "third", wantErr: ErrGameFull},
+		{name: "already seated", seated: []string{"white"}, token: "white", wantErr: ErrAlreadySeated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGameManager().NewGame(Settings{TimeControl: NewTimeControl(5*time.Minute, 0)})
+			for _, token := range test.seated {
+				if _, err := g.AddPlayer("Player", token, ""); err != nil {
+					t.Fatalf("seating %s: %v", token, err)
+				}
+			}
+
+			player, err := g.AddPlayer("Player", test.token, "")
+			if err != test.wantErr {
+				t.Fatalf("AddPlayer() error = %v, want %v", err, test.wantErr)
+			}
+
+			if err == nil && g.GetPlayerByToken(test.token) != player {
+				t.Error("the player was not seated")
+			}
+
+			if want := len(test.seated); err != nil && g.PlayerCount() != want {
+				t.Errorf("player count = %d, want %d", g.PlayerCount(), want)
+			}
+		})
+	}
+}
+
+func TestMoveIsPlayedForTheGivenColor(t *testing.T) {
+	tests := []struct {
+		name       string
+		color      int
+		wantReason int
+	}{
+		{name: "active player", color: constants.White, wantReason: constants.MoveAllowed},
+		{name: "waiting player", color: constants.Black, wantReason: constants.NotYourTurn},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newTestGame(NewTimeControl(5*time.Minute, 0))
+
+			move, reason := g.Move(test.color, 4, 1, 4, 3, nil)
+			if reason != test.wantReason {
+				t.Fatalf("reason = %s, want %s", constants.MoveErrorAsString(reason), constants.MoveErrorAsString(test.wantReason))
+			}
+
+			if (move != nil) != (test.wantReason == constants.MoveAllowed) {
+				t.Fatalf("move = %v, want a move only when it is allowed", move)
+			}
+
+			if move != nil && (move.ToX() != 4 || move.ToY() != 3) {
+				t.Errorf("played move to %d,%d, want 4,3", move.ToX(), move.ToY())
+			}
+		})
+	}
+}
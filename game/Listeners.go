@@ -0,0 +1,202 @@
+package game
+
+type MoveListener func(game *Game, move Move)
+
+type GameListener func(game *Game)
+
+type PlayerListener func(game *Game, player *Player)
+
+type DrawOfferListener func(game *Game, color int)
+
+type BerserkListener func(game *Game, color int)
+
+type ChatListener func(game *Game, message ChatMessage)
+
+type listeners struct {
+	move              []MoveListener
+	gameCreated       []GameListener
+	gameEnded         []GameListener
+	gameRemoved       []GameListener
+	playerJoined      []PlayerListener
+	drawOffered       []DrawOfferListener
+	berserk           []BerserkListener
+	chat              []ChatListener
+	spectatorsChanged []GameListener
+	abandoned         []PlayerListener
+	turn              []PlayerListener
+}
+
+// OnMove registers a listener that is called after a move was played in any game of the manager.
+func (g *Manager) OnMove(listener MoveListener) {
+	g.listeners.move = append(g.listeners.move, listener)
+}
+
+// OnGameCreated registers a listener that is called after a new game was created.
+func (g *Manager) OnGameCreated(listener GameListener) {
+	g.listeners.gameCreated = append(g.listeners.gameCreated, listener)
+}
+
+// OnGameRemoved registers a listener that is called after a game was removed by Cleanup.
+func (g *Manager) OnGameRemoved(listener GameListener) {
+	g.listeners.gameRemoved = append(g.listeners.gameRemoved, listener)
+}
+
+// OnSpectatorsChanged registers a listener that is called when a spectator starts or stops watching a game.
+func (g *Manager) OnSpectatorsChanged(listener GameListener) {
+	g.listeners.spectatorsChanged = append(g.listeners.spectatorsChanged, listener)
+}
+
+// OnGameEnded registers a listener that is called once a game has a result.
+func (g *Manager) OnGameEnded(listener GameListener) {
+	g.listeners.gameEnded = append(g.listeners.gameEnded, listener)
+}
+
+// OnPlayerJoined registers a listener that is called when a new player takes a seat.
+func (g *Manager) OnPlayerJoined(listener PlayerListener) {
+	g.listeners.playerJoined = append(g.listeners.playerJoined, listener)
+}
+
+// OnBerserk registers a listener that is called when a player halves their clock.
+func (g *Manager) OnBerserk(listener BerserkListener) {
+	g.listeners.berserk = append(g.listeners.berserk, listener)
+}
+
+// OnAbandoned registers a listener that is called when a disconnected player did not come back within the grace
+// period, so their opponent may claim the game.
+func (g *Manager) OnAbandoned(listener PlayerListener) {
+	g.listeners.abandoned = append(g.listeners.abandoned, listener)
+}
+
+// OnTurn registers a listener that is called when it becomes the turn of a player in a correspondence game.
+func (g *Manager) OnTurn(listener PlayerListener) {
+	g.listeners.turn = append(g.listeners.turn, listener)
+}
+
+// OnChat registers a listener that is called when someone writes in the chat of a game.
+func (g *Manager) OnChat(listener ChatListener) {
+	g.listeners.chat = append(g.listeners.chat, listener)
+}
+
+// OnDrawOffered registers a listener that is called when a player offers a draw.
+func (g *Manager) OnDrawOffered(listener DrawOfferListener) {
+	g.listeners.drawOffered = append(g.listeners.drawOffered, listener)
+}
+
+// The notify functions of a game are called while it is locked. They queue the listener calls, which run once
+// the game is unlocked.
+
+func (g *Game) notifyMove(move Move) {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.move {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, move) })
+	}
+}
+
+func (g *Game) notifyGameEnded() {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.gameEnded {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g) })
+	}
+}
+
+func (g *Game) notifyPlayerJoined(player *Player) {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.playerJoined {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, player) })
+	}
+}
+
+func (g *Game) notifyDrawOffered(color int) {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.drawOffered {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, color) })
+	}
+}
+
+func (g *Game) notifyBerserk(color int) {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.berserk {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, color) })
+	}
+}
+
+func (g *Game) notifyAbandoned(player *Player) {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.abandoned {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, player) })
+	}
+}
+
+func (g *Game) notifyTurn() {
+	if g.manager == nil || !g.timeControl.IsCorrespondence() || g.isOver() {
+		return
+	}
+
+	player := g.playerByColor(g.activeColor())
+	if player == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.turn {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, player) })
+	}
+}
+
+func (g *Game) notifyChat(message ChatMessage) {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.chat {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g, message) })
+	}
+}
+
+func (g *Manager) notifyGameCreated(game *Game) {
+	for _, listener := range g.listeners.gameCreated {
+		listener(game)
+	}
+}
+
+func (g *Manager) notifyGameRemoved(game *Game) {
+	for _, listener := range g.listeners.gameRemoved {
+		listener(game)
+	}
+}
+
+func (g *Game) notifySpectatorsChanged() {
+	if g.manager == nil {
+		return
+	}
+
+	for _, listener := range g.manager.listeners.spectatorsChanged {
+		listener := listener
+		g.pending = append(g.pending, func() { listener(g) })
+	}
+}
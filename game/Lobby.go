@@ -0,0 +1,194 @@
+package game
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/racccoooon/chess-be/constants"
+	"sort"
+)
+
+const (
+	defaultLobbyLimit = 20
+	maxLobbyLimit     = 100
+)
+
+// LobbyQuery filters, sorts and paginates the public games that are not over yet.
+type LobbyQuery struct {
+	OpenSeatsOnly bool
+	Variant       *int
+	Category      *int
+	Rated         *bool
+	Sort          int
+	Cursor        string
+	Limit         int
+}
+
+type lobbyCursor struct {
+	key int64
+	id  Id
+}
+
+// QueryLobby returns one page of lobby games and the cursor of the next page, which is empty on the last page.
+func (g *Manager) QueryLobby(query LobbyQuery) ([]*Game, string, error) {
+	var games []*Game
+
+	// the spectator count may change while sorting, so every game is sorted by the key it had before
+	keys := make(map[Id]int64)
+
+	for _, game := range g.snapshot() {
+		if !game.public || game.IsOver() {
+			continue
+		}
+
+		if query.OpenSeatsOnly && game.OpenSeats() == 0 {
+			continue
+		}
+
+		if query.Variant != nil && game.variant != *query.Variant {
+			continue
+		}
+
+		if query.Category != nil && game.timeControl.Category() != *query.Category {
+			continue
+		}
+
+		if query.Rated != nil && game.rated != *query.Rated {
+			continue
+		}
+
+		games = append(games, game)
+		keys[game.id] = lobbyKey(query.Sort, game)
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return isBeforeInLobby(query.Sort, keys[games[i].id], games[i].id, keys[games[j].id], games[j].id)
+	})
+
+	if query.Cursor != "" {
+		cursor, err := decodeLobbyCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		start := len(games)
+		for i, game := range games {
+			if isBeforeInLobby(query.Sort, cursor.key, cursor.id, keys[game.id], game.id) {
+				start = i
+				break
+			}
+		}
+
+		games = games[start:]
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLobbyLimit
+	}
+	if limit > maxLobbyLimit {
+		limit = maxLobbyLimit
+	}
+
+	if len(games) <= limit {
+		return games, "", nil
+	}
+
+	last := games[limit-1]
+	nextCursor := encodeLobbyCursor(lobbyCursor{key: keys[last.id], id: last.id})
+
+	return games[:limit], nextCursor, nil
+}
+
+func lobbyKey(sort int, game *Game) int64 {
+	if sort == constants.MostSpectators {
+		return int64(game.SpectatorCount())
+	}
+
+	return game.createTime.UnixNano()
+}
+
+// isBeforeInLobby orders games by their sort key and uses the id to break ties, so cursors point to a unique position.
+func isBeforeInLobby(sort int, keyA int64, idA Id, keyB int64, idB Id) bool {
+	if keyA != keyB {
+		if sort == constants.OldestFirst {
+			return keyA < keyB
+		}
+
+		return keyA > keyB
+	}
+
+	return idA < idB
+}
+
+func encodeLobbyCursor(cursor lobbyCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", cursor.key, cursor.id)))
+}
+
+func decodeLobbyCursor(encoded string) (lobbyCursor, error) {
+	var cursor lobbyCursor
+
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+
+	var id string
+	if _, err := fmt.Sscanf(string(decoded), "%d:%s", &cursor.key, &id); err != nil {
+		return cursor, err
+	}
+	cursor.id = Id(id)
+
+	return cursor, nil
+}
+
+func (g *Game) OpenSeats() int {
+	g.lock()
+	defer g.unlock()
+
+	return 2 - len(g.players)
+}
+
+func (g *Game) SpectatorCount() int {
+	g.lock()
+	defer g.unlock()
+
+	return len(g.spectators)
+}
+
+func (g *Game) IsSpectator(connectionId string) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.spectators[connectionId]
+}
+
+func (g *Game) AddSpectator(connectionId string) {
+	g.lock()
+	defer g.unlock()
+
+	if g.spectators[connectionId] {
+		return
+	}
+
+	g.spectators[connectionId] = true
+	g.notifySpectatorsChanged()
+}
+
+func (g *Game) RemoveSpectator(connectionId string) {
+	g.lock()
+	defer g.unlock()
+
+	if !g.spectators[connectionId] {
+		return
+	}
+
+	delete(g.spectators, connectionId)
+	g.notifySpectatorsChanged()
+}
+
+// RemoveSpectatorFromAllGames is used when a connection goes away without leaving the games it watched.
+func (g *Manager) RemoveSpectatorFromAllGames(connectionId string) {
+	for _, game := range g.snapshot() {
+		game.RemoveSpectator(connectionId)
+	}
+}
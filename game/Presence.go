@@ -0,0 +1,154 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"time"
+)
+
+// DefaultDisconnectGrace is how long a disconnected player may take to come back before the opponent may claim
+// the victory or a draw.
+const DefaultDisconnectGrace = time.Minute
+
+// SetDisconnectGrace sets how long a disconnected player may take to come back in all games.
+func (g *Manager) SetDisconnectGrace(grace time.Duration) {
+	g.disconnectGrace = grace
+}
+
+func (g *Manager) DisconnectGrace() time.Duration {
+	return g.disconnectGrace
+}
+
+// DisconnectPlayerFromAllGames marks the players using the connection as disconnected and returns their games.
+func (g *Manager) DisconnectPlayerFromAllGames(connectionId string) []PlayerGame {
+	var playerGames []PlayerGame
+
+	for _, game := range g.snapshot() {
+		game.lock()
+		if player := game.playerByConnectionId(connectionId); player != nil {
+			player.connectionId = ""
+			player.disconnectTime = time.Now()
+			player.abandoned = false
+
+			playerGames = append(playerGames, PlayerGame{
+				color: player.color,
+				id:    game.id,
+			})
+		}
+		game.unlock()
+	}
+
+	return playerGames
+}
+
+// CheckDisconnects tells the listeners about the players of running games that did not come back within
+// the grace period, once per disconnect. Correspondence games are left out, because their players are rarely
+// online at the same time.
+func (g *Manager) CheckDisconnects() {
+	for _, game := range g.snapshot() {
+		game.lock()
+		game.checkDisconnects(g.disconnectGrace)
+		game.unlock()
+	}
+}
+
+func (g *Game) checkDisconnects(grace time.Duration) {
+	if g.timeControl.IsCorrespondence() || g.isOver() || len(g.players) != 2 {
+		return
+	}
+
+	for _, player := range g.players {
+		if player.isConnected() || player.abandoned || time.Since(player.disconnectTime) < grace {
+			continue
+		}
+
+		player.abandoned = true
+		g.notifyAbandoned(player)
+	}
+}
+
+// IsConnected tells whether the player is connected to the hub or never was, like players of the REST api.
+func (p *Player) IsConnected() bool {
+	p.game.lock()
+	defer p.game.unlock()
+
+	return p.isConnected()
+}
+
+func (p *Player) isConnected() bool {
+	return p.disconnectTime.IsZero()
+}
+
+func (p *Player) DisconnectTime() time.Time {
+	p.game.lock()
+	defer p.game.unlock()
+
+	return p.disconnectTime
+}
+
+// ClaimableTime returns when the opponent of the given color may claim the game, or false if the opponent
+// is connected or the game is a correspondence game, which can not be claimed.
+func (g *Game) ClaimableTime(color int) (time.Time, bool) {
+	g.lock()
+	defer g.unlock()
+
+	return g.claimableTime(color)
+}
+
+func (g *Game) claimableTime(color int) (time.Time, bool) {
+	if g.timeControl.IsCorrespondence() {
+		return time.Time{}, false
+	}
+
+	opponent := g.playerByColor(constants.GetOppositeColor(color))
+	if opponent == nil || opponent.isConnected() {
+		return time.Time{}, false
+	}
+
+	return opponent.disconnectTime.Add(g.disconnectGrace()), true
+}
+
+// ClaimVictory ends the game as a win for the given color if the opponent is gone for longer than the grace period.
+func (g *Game) ClaimVictory(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	if !g.mayClaim(color) {
+		return false
+	}
+
+	g.end(constants.WinFor(color), constants.Abandonment)
+
+	return true
+}
+
+// ClaimDraw ends the game as a draw if the opponent of the given color is gone for longer than the grace period.
+func (g *Game) ClaimDraw(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	if !g.mayClaim(color) {
+		return false
+	}
+
+	g.end(constants.Drawn, constants.Abandonment)
+
+	return true
+}
+
+func (g *Game) mayClaim(color int) bool {
+	if g.timeControl.IsCorrespondence() || g.isOver() || len(g.players) != 2 {
+		return false
+	}
+
+	claimableTime, ok := g.claimableTime(color)
+
+	return ok && !time.Now().Before(claimableTime)
+}
+
+func (g *Game) disconnectGrace() time.Duration {
+	if g.manager == nil {
+		return DefaultDisconnectGrace
+	}
+
+	return g.manager.disconnectGrace
+}
@@ -0,0 +1,132 @@
+package game
+
+import "github.com/racccoooon/chess-be/constants"
+
+const noRematchOffer = -1
+
+// PreviousGameId returns the game this game is a rematch of, or an empty id.
+func (g *Game) PreviousGameId() Id {
+	g.lock()
+	defer g.unlock()
+
+	return g.previousGameId
+}
+
+// RematchGameId returns the rematch of this game, or an empty id if there is none yet.
+func (g *Game) RematchGameId() Id {
+	g.lock()
+	defer g.unlock()
+
+	return g.rematchGameId
+}
+
+// RematchOfferedBy returns the color of the player with a pending rematch offer and whether there is one.
+func (g *Game) RematchOfferedBy() (int, bool) {
+	g.lock()
+	defer g.unlock()
+
+	return g.rematchOffer, g.rematchOffer != noRematchOffer
+}
+
+// OfferRematch offers a rematch of a finished game on behalf of the given color. If the opponent already offered
+// a rematch, the offer is accepted and the rematch is returned.
+func (g *Game) OfferRematch(color int) (*Game, bool) {
+	g.lock()
+
+	if !g.isOver() || len(g.players) != 2 || g.rematchGameId != "" || g.startingRematch {
+		g.unlock()
+		return nil, false
+	}
+
+	if g.rematchOffer == constants.GetOppositeColor(color) {
+		setup := g.prepareRematch()
+		g.unlock()
+
+		return g.startRematch(setup), true
+	}
+
+	g.rematchOffer = color
+	g.unlock()
+
+	return nil, true
+}
+
+// AcceptRematch creates a game with the same starting position, time control and rating as this game,
+// in which the players swap colors.
+func (g *Game) AcceptRematch(color int) (*Game, bool) {
+	g.lock()
+
+	if g.rematchGameId != "" || g.startingRematch || g.rematchOffer != constants.GetOppositeColor(color) {
+		g.unlock()
+		return nil, false
+	}
+
+	setup := g.prepareRematch()
+	g.unlock()
+
+	return g.startRematch(setup), true
+}
+
+// rematchSetup is what the rematch is created from. It is collected while the game is locked, so the rematch
+// can be created and joined without holding the lock of this game.
+type rematchSetup struct {
+	settings Settings
+	seats    []rematchSeat
+}
+
+type rematchSeat struct {
+	name         string
+	token        string
+	connectionId string
+}
+
+func (g *Game) prepareRematch() rematchSetup {
+	g.startingRematch = true
+	g.rematchOffer = noRematchOffer
+
+	setup := rematchSetup{
+		settings: Settings{
+			FirstPlayerColor: constants.White,
+			StartingColor:    g.startingColor,
+			Public:           g.public,
+			Private:          g.private,
+			Password:         g.password,
+			TimeControl:      g.timeControl,
+			Rated:            g.rated,
+			Armageddon:       g.armageddon,
+			SpectatorPolicy:  g.spectatorPolicy,
+			BroadcastDelay:   g.broadcastDelay,
+		},
+	}
+
+	if g.variant == constants.FromPosition {
+		setup.settings.StartingPieces = g.initial
+	}
+
+	// the first player gets white, so the player who had black is seated first
+	for _, previousColor := range []int{constants.Black, constants.White} {
+		player := g.playerByColor(previousColor)
+		setup.seats = append(setup.seats, rematchSeat{player.name, player.token, player.connectionId})
+	}
+
+	return setup
+}
+
+func (g *Game) startRematch(setup rematchSetup) *Game {
+	rematch := g.manager.NewGame(setup.settings)
+
+	rematch.lock()
+	rematch.previousGameId = g.id
+	rematch.unlock()
+
+	g.lock()
+	g.rematchGameId = rematch.id
+	g.startingRematch = false
+	g.unlock()
+
+	for _, seat := range setup.seats {
+		rematch.AddPlayer(seat.name, seat.token, seat.connectionId)
+	}
+
+	return rematch
+}
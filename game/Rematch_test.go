@@ -0,0 +1,82 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRematch(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept func(g *Game) (*Game, bool)
+	}{
+		{
+			name: "accepted",
+			accept: func(g *Game) (*Game, bool) {
+				return g.AcceptRematch(constants.Black)
+			},
+		},
+		{
+			name: "offered by both players",
+			accept: func(g *Game) (*Game, bool) {
+				return g.OfferRematch(constants.Black)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newTestGame(NewTimeControl(5*time.Minute, 0))
+			g.Resign(constants.Black)
+
+			if rematch, ok := g.OfferRematch(constants.White); rematch != nil || !ok {
+				t.Fatalf("OfferRematch() = %v, %v, want no rematch yet", rematch, ok)
+			}
+
+			rematch, ok := test.accept(g)
+			if rematch == nil || !ok {
+				t.Fatal("the rematch was not created")
+			}
+
+			if g.RematchGameId() != rematch.Id() || rematch.PreviousGameId() != g.Id() {
+				t.Error("the games are not linked")
+			}
+
+			if rematch.GetPlayerByToken("white").Color() != constants.Black || rematch.GetPlayerByToken("black").Color() != constants.White {
+				t.Error("the players did not swap colors")
+			}
+
+			if again, ok := g.AcceptRematch(constants.Black); again != nil || ok {
+				t.Error("the rematch was created twice")
+			}
+		})
+	}
+}
+
+func TestRematchIsCreatedOnce(t *testing.T) {
+	g := newTestGame(NewTimeControl(5*time.Minute, 0))
+	g.Resign(constants.Black)
+	g.OfferRematch(constants.White)
+
+	var wg sync.WaitGroup
+	rematches := make(chan *Game, 10)
+
+	for i := 0; i < cap(rematches); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rematch, ok := g.AcceptRematch(constants.Black); ok {
+				rematches <- rematch
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(rematches)
+
+	if len(rematches) != 1 {
+		t.Errorf("%d rematches were created, want 1", len(rematches))
+	}
+}
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"time"
+)
+
+const noDrawOffer = -1
+
+func (g *Game) Result() int {
+	g.lock()
+	defer g.unlock()
+
+	return g.result
+}
+
+func (g *Game) Termination() int {
+	g.lock()
+	defer g.unlock()
+
+	return g.termination
+}
+
+func (g *Game) IsOver() bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.isOver()
+}
+
+func (g *Game) isOver() bool {
+	return g.result != constants.InProgress
+}
+
+// DrawOfferedBy returns the color of the player with a pending draw offer and whether there is one.
+func (g *Game) DrawOfferedBy() (int, bool) {
+	g.lock()
+	defer g.unlock()
+
+	return g.drawOffer, g.drawOffer != noDrawOffer
+}
+
+func (g *Game) Resign(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	if g.isOver() {
+		return false
+	}
+
+	g.end(constants.WinFor(constants.GetOppositeColor(color)), constants.Resignation)
+
+	return true
+}
+
+// OfferDraw offers a draw on behalf of the given color. If the opponent already offered a draw,
+// the offer is accepted and the game ends.
+func (g *Game) OfferDraw(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	if g.isOver() {
+		return false
+	}
+
+	if g.drawOffer == constants.GetOppositeColor(color) {
+		return g.acceptDraw(color)
+	}
+
+	if g.drawOffer == color {
+		return true
+	}
+
+	g.drawOffer = color
+	g.notifyDrawOffered(color)
+
+	return true
+}
+
+func (g *Game) AcceptDraw(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.acceptDraw(color)
+}
+
+func (g *Game) acceptDraw(color int) bool {
+	if g.isOver() || g.drawOffer != constants.GetOppositeColor(color) {
+		return false
+	}
+
+	g.end(constants.Drawn, constants.DrawAgreement)
+
+	return true
+}
+
+func (g *Game) DeclineDraw(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	if g.drawOffer != constants.GetOppositeColor(color) {
+		return false
+	}
+
+	g.drawOffer = noDrawOffer
+
+	return true
+}
+
+func (g *Game) end(result int, termination int) {
+	g.result = result
+	g.termination = termination
+	g.endTime = time.Now()
+	g.drawOffer = noDrawOffer
+
+	g.notifyGameEnded()
+}
@@ -0,0 +1,199 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+)
+
+// ExplainMove checks a move of the player with the given color the same way Move does and returns the reason
+// why it is not allowed, or constants.MoveAllowed if the move can be played.
+func (g *Game) ExplainMove(color int, fromX int, fromY int, toX int, toY int, promoteToType *string) int {
+	if color != g.activeColor() {
+		return constants.NotYourTurn
+	}
+
+	piece := g.GetPieceAt(fromX, fromY)
+	if piece == nil {
+		return constants.NoPieceAtSquare
+	}
+
+	if piece.color != color {
+		return constants.NotYourPiece
+	}
+
+	reason := g.ExplainPieceMove(*piece, toX, toY)
+	if reason != constants.MoveAllowed {
+		return reason
+	}
+
+	if piece.type_ == constants.Pawn && g.IsPromotionMove(*piece, toX, toY) {
+		if promoteToType == nil {
+			return constants.PromotionMissing
+		}
+
+		if !constants.IsPromotionType(*promoteToType) {
+			return constants.PromotionInvalid
+		}
+
+		return constants.MoveAllowed
+	}
+
+	// only pawns reaching the last rank can be promoted
+	if promoteToType != nil {
+		return constants.PromotionInvalid
+	}
+
+	return constants.MoveAllowed
+}
+
+// ExplainPieceMove returns the reason why the piece can not move to the given square,
+// or constants.MoveAllowed if IsMoveValid accepts the move.
+func (g *Game) ExplainPieceMove(piece Piece, toX int, toY int) int {
+	if toX < 0 || toX > 7 || toY < 0 || toY > 7 {
+		return constants.OutOfBounds
+	}
+
+	if piece.x == toX && piece.y == toY {
+		return constants.InvalidPieceMovement
+	}
+
+	pieceAtSquare := g.GetPieceAt(toX, toY)
+	if pieceAtSquare != nil && pieceAtSquare.color == piece.color {
+		return constants.CannotCaptureOwn
+	}
+
+	if isValidMove, _ := g.IsMoveValid(piece, toX, toY); isValidMove {
+		return constants.MoveAllowed
+	}
+
+	var reason int
+
+	switch piece.type_ {
+	case constants.Pawn:
+		reason = g.explainPawnMove(piece, toX, toY)
+	case constants.Rook:
+		reason = g.explainSlidingMove(piece, toX, toY, piece.x == toX || piece.y == toY, g.IsRookMoveValid)
+	case constants.Knight:
+		reason = g.explainKnightMove(piece, toX, toY)
+	case constants.Bishop:
+		reason = g.explainSlidingMove(piece, toX, toY, abs(piece.x-toX) == abs(piece.y-toY), g.IsBishopMoveValid)
+	case constants.Queen:
+		isLine := piece.x == toX || piece.y == toY || abs(piece.x-toX) == abs(piece.y-toY)
+		reason = g.explainSlidingMove(piece, toX, toY, isLine, g.IsQueenMoveValid)
+	case constants.King:
+		reason = g.explainKingMove(piece, toX, toY)
+	}
+
+	if reason != constants.MoveAllowed {
+		return reason
+	}
+
+	// the piece can move there, so the move must expose the own king
+	return constants.LeavesKingInCheck
+}
+
+func (g *Game) explainPawnMove(piece Piece, toX int, toY int) int {
+	direction := 1
+	if piece.color == constants.Black {
+		direction = -1
+	}
+
+	xDiff := abs(piece.x - toX)
+	yDiff := toY - piece.y
+
+	if xDiff == 0 && (yDiff == direction || (yDiff == 2*direction && !piece.hasMoved)) {
+		for y := piece.y + direction; y != toY+direction; y += direction {
+			if g.GetPieceAt(piece.x, y) != nil {
+				return constants.PathBlocked
+			}
+		}
+
+		return constants.MoveAllowed
+	}
+
+	if xDiff == 1 && yDiff == direction {
+		isValidMove, _ := g.IsPawnMoveValid(piece, toX, toY)
+		if !isValidMove {
+			// pawns can only move diagonally when capturing
+			return constants.InvalidPieceMovement
+		}
+
+		return constants.MoveAllowed
+	}
+
+	return constants.InvalidPieceMovement
+}
+
+func (g *Game) explainSlidingMove(piece Piece, toX int, toY int, isLine bool, isMoveValid func(Piece, int, int) bool) int {
+	if !isLine {
+		return constants.InvalidPieceMovement
+	}
+
+	if !isMoveValid(piece, toX, toY) {
+		return constants.PathBlocked
+	}
+
+	return constants.MoveAllowed
+}
+
+func (g *Game) explainKnightMove(piece Piece, toX int, toY int) int {
+	if !g.IsKnightMoveValid(piece, toX, toY) {
+		return constants.InvalidPieceMovement
+	}
+
+	return constants.MoveAllowed
+}
+
+func (g *Game) explainKingMove(piece Piece, toX int, toY int) int {
+	xDiff := abs(piece.x - toX)
+	yDiff := abs(piece.y - toY)
+
+	if xDiff == 2 && yDiff == 0 && (toX == 2 || toX == 6) {
+		return g.explainCastle(piece, toX, toY)
+	}
+
+	if xDiff > 1 || yDiff > 1 {
+		return constants.InvalidPieceMovement
+	}
+
+	// the king may not move next to the enemy king
+	return constants.LeavesKingInCheck
+}
+
+func (g *Game) explainCastle(piece Piece, toX int, toY int) int {
+	homeRow := 0
+	if piece.color == constants.Black {
+		homeRow = 7
+	}
+
+	if piece.hasMoved || piece.x != 4 || piece.y != homeRow || toY != homeRow {
+		return constants.InvalidPieceMovement
+	}
+
+	rookX := 7
+	pathXs := []int{5, 6}
+	kingXs := []int{4, 5, 6}
+	if toX == 2 {
+		rookX = 0
+		pathXs = []int{1, 2, 3}
+		kingXs = []int{4, 3, 2}
+	}
+
+	rook := g.GetPieceAt(rookX, homeRow)
+	if rook == nil || rook.type_ != constants.Rook || rook.color != piece.color || rook.hasMoved {
+		return constants.InvalidPieceMovement
+	}
+
+	for _, x := range pathXs {
+		if g.GetPieceAt(x, homeRow) != nil {
+			return constants.PathBlocked
+		}
+	}
+
+	for _, x := range kingXs {
+		if g.IsInCheckAt(x, homeRow) {
+			return constants.CastlingThroughCheck
+		}
+	}
+
+	return constants.MoveAllowed
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"testing"
+)
+
+func TestExplainMove(t *testing.T) {
+	queen, king := "queen", "king"
+
+	// a white pawn about to promote and a black rook pinning the white rook to its king
+	custom := []Piece{
+		NewPiece(constants.White, constants.King, 4, 0),
+		NewPiece(constants.White, constants.Rook, 4, 1),
+		NewPiece(constants.White, constants.Rook, 7, 0),
+		NewPiece(constants.White, constants.Pawn, 0, 6),
+		NewPiece(constants.Black, constants.King, 0, 4),
+		NewPiece(constants.Black, constants.Rook, 4, 7),
+		NewPiece(constants.Black, constants.Rook, 5, 7),
+	}
+
+	tests := []struct {
+		name           string
+		startingPieces []Piece
+		color          int
+		from, to       [2]int
+		promoteToType  *string
+		want           int
+	}{
+		{name: "allowed", color: constants.White, from: [2]int{4, 1}, to: [2]int{4, 3}, want: constants.MoveAllowed},
+		{name: "not your turn", color: constants.Black, from: [2]int{4, 6}, to: [2]int{4, 4}, want: constants.NotYourTurn},
+		{name: "no piece at the square", color: constants.White, from: [2]int{4, 3}, to: [2]int{4, 4}, want: constants.NoPieceAtSquare},
+		{name: "opponent's piece", color: constants.White, from: [2]int{4, 6}, to: [2]int{4, 4}, want: constants.NotYourPiece},
+		{name: "out of bounds", color: constants.White, from: [2]int{1, 0}, to: [2]int{-1, 1}, want: constants.OutOfBounds},
+		{name: "capture own piece", color: constants.White, from: [2]int{0, 0}, to: [2]int{0, 1}, want: constants.CannotCaptureOwn},
+		{name: "invalid piece movement", color: constants.White, from: [2]int{1, 0}, to: [2]int{1, 2}, want: constants.InvalidPieceMovement},
+		{name: "blocked path", color: constants.White, from: [2]int{2, 0}, to: [2]int{4, 2}, want: constants.PathBlocked},
+		{name: "leaves the king in check", startingPieces: custom, color: constants.White, from: [2]int{4, 1}, to: [2]int{3, 1}, want: constants.LeavesKingInCheck},
+		{name: "castling through check", startingPieces: custom, color: constants.White, from: [2]int{4, 0}, to: [2]int{6, 0}, want: constants.CastlingThroughCheck},
+		{name: "promotion missing", startingPieces: custom, color: constants.White, from: [2]int{0, 6}, to: [2]int{0, 7}, want: constants.PromotionMissing},
+		{name: "promotion to a king", startingPieces: custom, color: constants.White, from: [2]int{0, 6}, to: [2]int{0, 7}, promoteToType: &king, want: constants.PromotionInvalid},
+		{name: "promotion", startingPieces: custom, color: constants.White, from: [2]int{0, 6}, to: [2]int{0, 7}, promoteToType: &queen, want: constants.MoveAllowed},
+		{name: "promotion without reaching the last rank", color: constants.White, from: [2]int{4, 1}, to: [2]int{4, 3}, promoteToType: &queen, want: constants.PromotionInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGameManager().NewGame(Settings{
+				StartingPieces: test.startingPieces,
+				StartingColor:  constants.White,
+			})
+
+			got := g.ExplainMove(test.color, test.from[0], test.from[1], test.to[0], test.to[1], test.promoteToType)
+			if got != test.want {
+				t.Errorf("ExplainMove() = %s, want %s", constants.MoveErrorAsString(got), constants.MoveErrorAsString(test.want))
+			}
+		})
+	}
+}
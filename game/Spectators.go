@@ -0,0 +1,88 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"time"
+)
+
+// BroadcastDelay holds back a move of a running game from the spectators until Moves more moves were played
+// and Duration has passed since it was played. Spectators see the whole game once it is over.
+type BroadcastDelay struct {
+	Moves    int
+	Duration time.Duration
+}
+
+func (d BroadcastDelay) IsZero() bool {
+	return d.Moves == 0 && d.Duration == 0
+}
+
+// Releases tells whether what happened at the given time, after the given number of moves, may be shown once
+// the game has played that many moves.
+func (d BroadcastDelay) Releases(played int, plies int, at time.Time) bool {
+	if plies > 0 && played-plies < d.Moves {
+		return false
+	}
+
+	return time.Since(at) >= d.Duration
+}
+
+func (g *Game) SpectatorPolicy() int {
+	return g.spectatorPolicy
+}
+
+func (g *Game) BroadcastDelay() BroadcastDelay {
+	return g.broadcastDelay
+}
+
+// AdmitsSpectators tells whether people who do not play the game may watch it right now.
+func (g *Game) AdmitsSpectators() bool {
+	g.lock()
+	defer g.unlock()
+
+	switch g.spectatorPolicy {
+	case constants.SpectatorsDisallowed:
+		return false
+	case constants.SpectatorsAfterEnd:
+		return g.isOver()
+	}
+
+	return true
+}
+
+// IsDelayed tells whether the spectators currently see the game later than the players.
+func (g *Game) IsDelayed() bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.isDelayed()
+}
+
+func (g *Game) isDelayed() bool {
+	return !g.broadcastDelay.IsZero() && !g.isOver()
+}
+
+// IsReleased tells whether the spectators may see what happened at the given time, after the given number of moves.
+// Anything that happened after a move is released together with the move, so spectators see it at the right position.
+func (g *Game) IsReleased(plies int, at time.Time) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.isReleased(plies, at)
+}
+
+func (g *Game) isReleased(plies int, at time.Time) bool {
+	return !g.isDelayed() || g.broadcastDelay.Releases(len(g.moves), plies, at)
+}
+
+// SpectatorPlies returns how many moves of the game the spectators may see.
+func (g *Game) SpectatorPlies() int {
+	g.lock()
+	defer g.unlock()
+
+	plies := len(g.moves)
+	for plies > 0 && !g.isReleased(plies, g.moves[plies-1].playTime) {
+		plies--
+	}
+
+	return plies
+}
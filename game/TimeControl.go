@@ -0,0 +1,214 @@
+package game
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"time"
+)
+
+// TimeControl is the time each player has for the whole game and the increment added after every move.
+// Correspondence games have no clocks but a number of days for every move and a vacation each player may take.
+// The zero value is a game without clocks.
+type TimeControl struct {
+	initial   time.Duration
+	increment time.Duration
+
+	daysPerMove int
+	vacation    time.Duration
+}
+
+func NewTimeControl(initial time.Duration, increment time.Duration) TimeControl {
+	return TimeControl{
+		initial:   initial,
+		increment: increment,
+	}
+}
+
+func NewCorrespondenceTimeControl(daysPerMove int, vacationDays int) TimeControl {
+	return TimeControl{
+		daysPerMove: daysPerMove,
+		vacation:    time.Duration(vacationDays) * 24 * time.Hour,
+	}
+}
+
+func (t TimeControl) Initial() time.Duration {
+	return t.initial
+}
+
+func (t TimeControl) Increment() time.Duration {
+	return t.increment
+}
+
+func (t TimeControl) DaysPerMove() int {
+	return t.daysPerMove
+}
+
+// Vacation returns how long each player of a correspondence game may pause their deadlines.
+func (t TimeControl) Vacation() time.Duration {
+	return t.vacation
+}
+
+// IsUnlimited tells whether the game is played without clocks, which correspondence games are.
+func (t TimeControl) IsUnlimited() bool {
+	return t.initial == 0
+}
+
+func (t TimeControl) IsCorrespondence() bool {
+	return t.daysPerMove > 0
+}
+
+// Category classifies the time control by the estimated duration of a game of 40 moves.
+func (t TimeControl) Category() int {
+	if t.IsCorrespondence() {
+		return constants.Correspondence
+	}
+
+	if t.IsUnlimited() {
+		return constants.Unlimited
+	}
+
+	estimated := t.initial + 40*t.increment
+
+	switch {
+	case estimated < 3*time.Minute:
+		return constants.Bullet
+	case estimated < 8*time.Minute:
+		return constants.Blitz
+	case estimated < 25*time.Minute:
+		return constants.Rapid
+	}
+
+	return constants.Classical
+}
+
+func (g *Game) TimeControl() TimeControl {
+	return g.timeControl
+}
+
+// TimeLeft returns the time left on the clock of the given color, including the running time of the current move.
+func (g *Game) TimeLeft(color int) time.Duration {
+	g.lock()
+	defer g.unlock()
+
+	return g.timeLeft(color)
+}
+
+func (g *Game) timeLeft(color int) time.Duration {
+	timeLeft := g.clock[color]
+
+	if g.isClockRunning() && g.activeColor() == color {
+		timeLeft -= time.Since(g.turnStart)
+	}
+
+	if timeLeft < 0 {
+		return 0
+	}
+
+	return timeLeft
+}
+
+// CheckTimeout ends the game if the active player ran out of time or missed the deadline of a correspondence game
+// and reports whether that happened.
+func (g *Game) CheckTimeout() bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.checkTimeout()
+}
+
+func (g *Game) checkTimeout() bool {
+	if g.timeControl.IsCorrespondence() {
+		return g.checkDeadline()
+	}
+
+	if !g.isClockRunning() {
+		return false
+	}
+
+	if g.timeLeft(g.activeColor()) > 0 {
+		return false
+	}
+
+	g.clock[g.activeColor()] = 0
+	g.end(constants.WinFor(constants.GetOppositeColor(g.activeColor())), constants.Timeout)
+
+	return true
+}
+
+// CheckTimeouts ends all games in which the active player ran out of time.
+func (g *Manager) CheckTimeouts() {
+	for _, game := range g.snapshot() {
+		game.CheckTimeout()
+	}
+}
+
+func (g *Game) isClockRunning() bool {
+	return !g.timeControl.IsUnlimited() && !g.turnStart.IsZero() && !g.isOver()
+}
+
+func (g *Game) startClock() {
+	if g.timeControl.IsUnlimited() || !g.turnStart.IsZero() {
+		return
+	}
+
+	g.turnStart = time.Now()
+}
+
+// chargeClock subtracts the time the player used for the move that was just played and adds the increment.
+func (g *Game) chargeClock(color int) {
+	if !g.isClockRunning() {
+		return
+	}
+
+	g.clock[color] -= time.Since(g.turnStart)
+	if !g.berserk[color] {
+		g.clock[color] += g.timeControl.increment
+	}
+	g.turnStart = time.Now()
+}
+
+// AllowsBerserk tells whether the players may trade half of their time for a bonus, as in arena tournaments.
+func (g *Game) AllowsBerserk() bool {
+	return g.allowBerserk
+}
+
+func (g *Game) IsBerserk(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	return g.berserk[color]
+}
+
+// Berserk halves the clock of a player and drops their increment. It is only possible before their first move.
+func (g *Game) Berserk(color int) bool {
+	g.lock()
+	defer g.unlock()
+
+	if !g.allowBerserk || g.isOver() || g.berserk[color] || g.moveCount(color) > 0 {
+		return false
+	}
+
+	g.berserk[color] = true
+	g.clock[color] /= 2
+	g.notifyBerserk(color)
+
+	return true
+}
+
+// MoveCount returns the number of moves the given color played.
+func (g *Game) MoveCount(color int) int {
+	g.lock()
+	defer g.unlock()
+
+	return g.moveCount(color)
+}
+
+func (g *Game) moveCount(color int) int {
+	count := 0
+	for _, move := range g.moves {
+		if move.color == color {
+			count++
+		}
+	}
+
+	return count
+}
@@ -6,6 +6,7 @@ require (
 	github.com/go-kit/log v0.2.1
 	github.com/google/uuid v1.3.0
 	github.com/philippseith/signalr v0.6.0
+	golang.org/x/crypto v0.17.0
 )
 
 require (
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/routing"
+	"net/http"
+	"time"
+)
+
+const (
+	accountTokenLifetime = 24 * time.Hour
+	guestTokenLifetime   = 7 * 24 * time.Hour
+)
+
+type AccountHandler struct {
+	store  *accounts.Store
+	issuer *auth.Issuer
+}
+
+func NewAccountHandler(store *accounts.Store, issuer *auth.Issuer) *AccountHandler {
+	return &AccountHandler{store: store, issuer: issuer}
+}
+
+func (h *AccountHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/accounts", h.register)
+	router.Handle(http.MethodGet, "/api/accounts/me", h.getMe)
+	router.Handle(http.MethodPost, "/api/auth/login", h.login)
+	router.Handle(http.MethodPost, "/api/auth/guest", h.guest)
+}
+
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type registerRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Bot      bool   `json:"bot"` // bot accounts play through the bot api
+}
+
+type accountResponse struct {
+	Id        string    `json:"id"`
+	Username  string    `json:"username"`
+	Bot       bool      `json:"bot"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *AccountHandler) register(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	var request registerRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.store.Register(request.Username, request.Password, request.Bot)
+	switch err {
+	case nil:
+	case accounts.ErrUsernameTaken:
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	case accounts.ErrInvalidUsername, accounts.ErrPasswordTooShort, accounts.ErrPasswordTooLong:
+		writeJson(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJson(w, http.StatusCreated, accountAsResponse(account))
+}
+
+type tokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (h *AccountHandler) login(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	var request credentialsRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.store.Authenticate(request.Username, request.Password)
+	if err != nil {
+		writeJson(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
+		return
+	}
+
+	kind := constants.AccountIdentity
+	if account.IsBot() {
+		kind = constants.BotIdentity
+	}
+
+	token, expiresAt := h.issuer.Issue(auth.Identity{
+		Subject: account.Id(),
+		Name:    account.Username(),
+		Kind:    kind,
+	}, accountTokenLifetime)
+
+	writeJson(w, http.StatusOK, tokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+type guestRequest struct {
+	Name string `json:"name"`
+}
+
+// guest issues a signed token for anonymous play. Every call creates a new guest identity.
+func (h *AccountHandler) guest(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	var request guestRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	guestId := uuid.NewString()
+
+	name := request.Name
+	if name == "" {
+		name = "Guest-" + guestId[:4]
+	} else if err := h.store.CheckGuestName(name); err == accounts.ErrUsernameTaken {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		writeJson(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	token, expiresAt := h.issuer.Issue(auth.Identity{
+		Subject: "guest-" + guestId,
+		Name:    name,
+		Kind:    constants.GuestIdentity,
+	}, guestTokenLifetime)
+
+	writeJson(w, http.StatusOK, tokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (h *AccountHandler) getMe(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || !identity.HasAccount() {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	account := h.store.GetAccount(identity.Subject)
+	if account == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, accountAsResponse(account))
+}
+
+func accountAsResponse(account *accounts.Account) accountResponse {
+	return accountResponse{
+		Id:        account.Id(),
+		Username:  account.Username(),
+		Bot:       account.IsBot(),
+		CreatedAt: account.CreateTime(),
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"github.com/racccoooon/chess-be/analysis"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/routing"
+	"net/http"
+	"time"
+)
+
+type AnalysisHandler struct {
+	manager *analysis.Manager
+	games   *game.Manager
+}
+
+func NewAnalysisHandler(manager *analysis.Manager, games *game.Manager) *AnalysisHandler {
+	return &AnalysisHandler{manager: manager, games: games}
+}
+
+func (h *AnalysisHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/games/{gameId}/analysis", h.requestAnalysis)
+	router.Handle(http.MethodGet, "/api/games/{gameId}/analysis", h.getAnalysis)
+}
+
+const errMovesNotReleased = "the moves of the game are not released yet"
+
+type analysisResponse struct {
+	GameId        string                `json:"gameId"`
+	Status        string                `json:"status"`
+	AnalyzedPlies int                   `json:"analyzedPlies"`
+	TotalPlies    int                   `json:"totalPlies"`
+	RequestedAt   time.Time             `json:"requestedAt"`
+	Plies         []analysisPlyResponse `json:"plies"`
+	White         analysisSummary       `json:"white"`
+	Black         analysisSummary       `json:"black"`
+}
+
+type analysisPlyResponse struct {
+	Ply            int                `json:"ply"`
+	Color          string             `json:"color"`
+	Move           string             `json:"move"`
+	BestMove       *string            `json:"bestMove"` // null if the position before had no legal move
+	Evaluation     evaluationResponse `json:"evaluation"`
+	Loss           int                `json:"loss"`
+	Classification string             `json:"classification"`
+}
+
+type evaluationResponse struct {
+	Centipawns int  `json:"centipawns"`
+	Mate       *int `json:"mate"` // null without a forced mate
+}
+
+type analysisSummary struct {
+	AverageCentipawnLoss int `json:"averageCentipawnLoss"`
+	Inaccuracies         int `json:"inaccuracies"`
+	Mistakes             int `json:"mistakes"`
+	Blunders             int `json:"blunders"`
+}
+
+// requestAnalysis starts the analysis of a finished game, or returns the analysis if it was requested before.
+func (h *AnalysisHandler) requestAnalysis(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	game := h.games.GetGame(game.Id(params.String("gameId")))
+	if game == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !mayAnalyse(w, r, game, identity.Subject) {
+		return
+	}
+
+	gameAnalysis, created, err := h.manager.Request(game)
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusAccepted
+	}
+
+	writeJson(w, status, analysisAsResponse(gameAnalysis))
+}
+
+func (h *AnalysisHandler) getAnalysis(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameAnalysis := h.manager.GetAnalysis(game.Id(params.String("gameId")))
+	game := h.games.GetGame(game.Id(params.String("gameId")))
+	if gameAnalysis == nil || game == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !mayAnalyse(w, r, game, token) {
+		return
+	}
+
+	writeJson(w, http.StatusOK, analysisAsResponse(gameAnalysis))
+}
+
+// mayAnalyse tells whether the caller may see the analysis of the game and writes the error response if they
+// may not. The analysis shows every move, so the caller has to be allowed to see every move of the game.
+func mayAnalyse(w http.ResponseWriter, r *http.Request, g *game.Game, token string) bool {
+	plies, ok := visiblePlies(w, r, g, token)
+	if !ok {
+		return false
+	}
+
+	if plies < len(g.History()) {
+		writeJson(w, http.StatusForbidden, errorResponse{Error: errMovesNotReleased})
+		return false
+	}
+
+	return true
+}
+
+func analysisAsResponse(gameAnalysis *analysis.Analysis) analysisResponse {
+	plies := gameAnalysis.Plies()
+
+	response := analysisResponse{
+		GameId:        string(gameAnalysis.GameId()),
+		Status:        constants.AnalysisStatusAsString(gameAnalysis.Status()),
+		AnalyzedPlies: len(plies),
+		TotalPlies:    gameAnalysis.TotalPlies(),
+		RequestedAt:   gameAnalysis.CreateTime(),
+		Plies:         make([]analysisPlyResponse, len(plies)),
+		White:         summaryAsResponse(gameAnalysis.Summary(constants.White)),
+		Black:         summaryAsResponse(gameAnalysis.Summary(constants.Black)),
+	}
+
+	for i, ply := range plies {
+		response.Plies[i] = analysisPlyAsResponse(ply)
+	}
+
+	return response
+}
+
+func analysisPlyAsResponse(ply analysis.Ply) analysisPlyResponse {
+	response := analysisPlyResponse{
+		Ply:            ply.Number(),
+		Color:          constants.ColorAsString(ply.Color()),
+		Move:           ply.Move(),
+		Evaluation:     evaluationResponse{Centipawns: ply.Evaluation().Centipawns()},
+		Loss:           ply.Loss(),
+		Classification: constants.MoveClassificationAsString(ply.Classification()),
+	}
+
+	if ply.BestMove() != "" {
+		bestMove := ply.BestMove()
+		response.BestMove = &bestMove
+	}
+
+	if ply.Evaluation().IsMate() {
+		mate := ply.Evaluation().Mate()
+		response.Evaluation.Mate = &mate
+	}
+
+	return response
+}
+
+func summaryAsResponse(summary analysis.Summary) analysisSummary {
+	return analysisSummary{
+		AverageCentipawnLoss: summary.AverageLoss(),
+		Inaccuracies:         summary.Inaccuracies(),
+		Mistakes:             summary.Mistakes(),
+		Blunders:             summary.Blunders(),
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/analysis"
+	"github.com/racccoooon/chess-be/arenas"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/engines"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/history"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/routing"
+	"github.com/racccoooon/chess-be/simuls"
+	"github.com/racccoooon/chess-be/streams"
+	"github.com/racccoooon/chess-be/tournaments"
+	"net/http"
+)
+
+// Services are the subsystems the api handlers use.
+type Services struct {
+	Manager     *game.Manager
+	Bots        *engines.Bots
+	Issuer      *auth.Issuer
+	Accounts    *accounts.Store
+	Ratings     *ratings.Store
+	Archive     *history.Archive
+	Tournaments *tournaments.Manager
+	Arenas      *arenas.Manager
+	Simuls      *simuls.Manager
+	Analyses    *analysis.Manager
+	Challenges  *challenges.Manager
+	Broker      *streams.Broker
+}
+
+// NewApiRouter registers every route of the api. The registered routes are only listed at /api/routes with debug.
+func NewApiRouter(services Services, debug bool) *routing.Router {
+	router := routing.NewRouter()
+
+	NewGameHandler(services.Manager, services.Bots, services.Issuer).RegisterRoutes(router)
+	NewAccountHandler(services.Accounts, services.Issuer).RegisterRoutes(router)
+	NewRatingHandler(services.Ratings, services.Accounts).RegisterRoutes(router)
+	NewStatsHandler(services.Archive, services.Ratings, services.Accounts).RegisterRoutes(router)
+	NewTournamentHandler(services.Tournaments, services.Ratings).RegisterRoutes(router)
+	NewArenaHandler(services.Arenas, services.Ratings).RegisterRoutes(router)
+	NewSimulHandler(services.Simuls).RegisterRoutes(router)
+	NewAnalysisHandler(services.Analyses, services.Manager).RegisterRoutes(router)
+	NewBotHandler(services.Manager, services.Challenges, services.Broker).RegisterRoutes(router)
+	NewChallengeHandler(services.Challenges, services.Accounts).RegisterRoutes(router)
+	NewCorrespondenceHandler(services.Manager).RegisterRoutes(router)
+	router.HandleHttp(http.MethodGet, "/api/health", NewHealthHandler())
+	router.HandleHttp(http.MethodHead, "/api/health", NewHealthHandler())
+	router.HandleHttp(http.MethodGet, "/api/openapi.json", NewOpenApiHandler())
+	router.HandleHttp(http.MethodGet, "/api/asyncapi.json", NewAsyncApiHandler())
+
+	if debug {
+		router.Handle(http.MethodGet, "/api/routes", router.ListRoutes)
+	}
+
+	return router
+}
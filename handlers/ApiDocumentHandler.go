@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var openApiDocument []byte
+
+//go:embed asyncapi.json
+var asyncApiDocument []byte
+
+type ApiDocumentHandler struct {
+	document []byte
+}
+
+// NewOpenApiHandler serves the OpenAPI description of the REST api.
+func NewOpenApiHandler() *ApiDocumentHandler {
+	return &ApiDocumentHandler{document: openApiDocument}
+}
+
+// NewAsyncApiHandler serves the AsyncAPI description of the methods and events of the game hub.
+func NewAsyncApiHandler() *ApiDocumentHandler {
+	return &ApiDocumentHandler{document: asyncApiDocument}
+}
+
+func (h *ApiDocumentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.document)
+}
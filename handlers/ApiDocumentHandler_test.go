@@ -0,0 +1,614 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/analysis"
+	"github.com/racccoooon/chess-be/arenas"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/engines"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/history"
+	"github.com/racccoooon/chess-be/middlewares"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/routing"
+	"github.com/racccoooon/chess-be/simuls"
+	"github.com/racccoooon/chess-be/streams"
+	"github.com/racccoooon/chess-be/tournaments"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type jsonObject map[string]interface{}
+
+func (o jsonObject) string(key string) string {
+	value, _ := o[key].(string)
+	return value
+}
+
+func (o jsonObject) object(key string) jsonObject {
+	value, _ := o[key].(map[string]interface{})
+	return value
+}
+
+func (o jsonObject) array(key string) []jsonObject {
+	values, _ := o[key].([]interface{})
+
+	objects := make([]jsonObject, 0, len(values))
+	for _, value := range values {
+		object, _ := value.(map[string]interface{})
+		objects = append(objects, object)
+	}
+
+	return objects
+}
+
+// contract calls the api and checks each response against the documented response of the operation.
+type contract struct {
+	t        *testing.T
+	router   *routing.Router
+	handler  http.Handler
+	arenas   *arenas.Manager
+	broker   *streams.Broker
+	document jsonObject
+	called   map[string]bool
+}
+
+// newContract builds the api router main uses, without the hub and the background tickers.
+func newContract(t *testing.T) *contract {
+	gameManager := game.NewGameManager()
+
+	ratingStore := ratings.NewStore()
+	gameManager.OnGameEnded(ratingStore.RateGame)
+
+	archive := history.NewArchive()
+	gameManager.OnGameEnded(archive.RecordGame)
+
+	tournamentManager := tournaments.NewManager(gameManager)
+	gameManager.OnGameEnded(tournamentManager.RecordResult)
+	gameManager.OnGameRemoved(tournamentManager.HandleGameRemoved)
+
+	arenaManager := arenas.NewManager(gameManager)
+	gameManager.OnGameEnded(arenaManager.RecordResult)
+	gameManager.OnGameRemoved(arenaManager.HandleGameRemoved)
+
+	simulManager := simuls.NewManager(gameManager)
+	gameManager.OnGameEnded(simulManager.RecordResult)
+	gameManager.OnGameRemoved(simulManager.HandleGameRemoved)
+
+	bots := engines.NewBots(engines.NewNativeEngine())
+
+	broker := streams.NewBroker()
+	gameManager.OnPlayerJoined(broker.HandlePlayerJoined)
+	gameManager.OnMove(broker.HandleMove)
+	gameManager.OnGameEnded(broker.HandleGameEnded)
+	gameManager.OnGameRemoved(broker.HandleGameRemoved)
+	gameManager.OnChat(broker.HandleChat)
+
+	analysisManager := analysis.NewManager(engines.NewNativeEngine())
+
+	challengeManager := challenges.NewManager(gameManager)
+	challengeManager.OnChallengeUpdated(broker.HandleChallengeUpdated)
+
+	issuer := auth.NewIssuer([]byte("contract test secret"))
+	gameManager.SetInviteVerifier(func(token string) (game.Id, int, bool) {
+		invite, err := issuer.VerifyInvite(token)
+		return game.Id(invite.GameId), invite.Role, err == nil
+	})
+	accountStore := accounts.NewStore()
+
+	router := NewApiRouter(Services{
+		Manager:     gameManager,
+		Bots:        bots,
+		Issuer:      issuer,
+		Accounts:    accountStore,
+		Ratings:     ratingStore,
+		Archive:     archive,
+		Tournaments: tournamentManager,
+		Arenas:      arenaManager,
+		Simuls:      simulManager,
+		Analyses:    analysisManager,
+		Challenges:  challengeManager,
+		Broker:      broker,
+	}, true)
+
+	c := &contract{
+		t:       t,
+		router:  router,
+		handler: &middlewares.AuthMiddleware{Handler: router, Issuer: issuer},
+		arenas:  arenaManager,
+		broker:  broker,
+		called:  make(map[string]bool),
+	}
+
+	if err := json.Unmarshal(openApiDocument, &c.document); err != nil {
+		t.Fatalf("openapi.json is not valid json: %v", err)
+	}
+
+	return c
+}
+
+// call sends the request to target, an instance of the documented path, and checks that the response has
+// the given status and matches its schema. The response body is returned if it is an object.
+func (c *contract) call(method string, path string, target string, body interface{}, token string, status int) jsonObject {
+	c.t.Helper()
+
+	var requestBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, &requestBody)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	object, _ := c.serve(method, path, request, status)
+	return object
+}
+
+// stream opens the event stream at target and checks the events that are sent right away. The request is
+// cancelled from the start, so the stream ends after them.
+func (c *contract) stream(path string, target string, token string) []jsonObject {
+	c.t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	request := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	_, events := c.serve(http.MethodGet, path, request, http.StatusOK)
+	return events
+}
+
+// serve returns the response body if it is an object and the events if it is an event stream.
+func (c *contract) serve(method string, path string, request *http.Request, status int) (jsonObject, []jsonObject) {
+	c.t.Helper()
+
+	operation := method + " " + path
+	c.called[operation] = true
+
+	recorder := httptest.NewRecorder()
+	c.handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != status {
+		c.t.Fatalf("%s: status = %d, want %d: %s", operation, recorder.Code, status, recorder.Body.String())
+	}
+
+	response := c.document.object("paths").object(path).object(strings.ToLower(method)).object("responses").object(strconv.Itoa(status))
+	if response == nil {
+		c.t.Fatalf("%s: status %d is not documented", operation, status)
+	}
+
+	content := response.object("content")
+
+	if schema := content.object("application/x-ndjson").object("schema"); schema != nil {
+		var events []jsonObject
+
+		scanner := bufio.NewScanner(recorder.Body)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+
+			var event interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				c.t.Fatalf("%s: event is not valid json: %v", operation, err)
+			}
+
+			if err := c.validate(event, schema, "$"); err != nil {
+				c.t.Fatalf("%s: %v", operation, err)
+			}
+
+			object, _ := event.(map[string]interface{})
+			events = append(events, object)
+		}
+
+		return nil, events
+	}
+
+	schema := content.object("application/json").object("schema")
+	if schema == nil {
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &value); err != nil {
+		c.t.Fatalf("%s: response is not valid json: %v: %q", operation, err, recorder.Body.String())
+	}
+
+	if err := c.validate(value, schema, "$"); err != nil {
+		c.t.Fatalf("%s: %v", operation, err)
+	}
+
+	object, _ := value.(map[string]interface{})
+	return object, nil
+}
+
+// validate checks value against the parts of JSON Schema that openapi.json uses.
+func (c *contract) validate(value interface{}, schema jsonObject, path string) error {
+	for ref := schema.string("$ref"); ref != ""; ref = schema.string("$ref") {
+		schema = c.document.object("components").object("schemas").object(strings.TrimPrefix(ref, "#/components/schemas/"))
+		if schema == nil {
+			return fmt.Errorf("%s: unknown schema %s", path, ref)
+		}
+	}
+
+	if alternatives, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, alternative := range alternatives {
+			if c.validate(value, alternative.(map[string]interface{}), path) == nil {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			return fmt.Errorf("%s: %v matches %d of the oneOf schemas", path, value, matches)
+		}
+
+		return nil
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !types[jsonType(value)] &&
+		!(jsonType(value) == "integer" && types["number"]) {
+		return fmt.Errorf("%s: %v is %s, not %v", path, value, jsonType(value), schema["type"])
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			found = found || allowed == value
+		}
+
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch value := value.(type) {
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && value < minimum {
+			return fmt.Errorf("%s: %v is less than %v", path, value, minimum)
+		}
+
+		if maximum, ok := schema["maximum"].(float64); ok && value > maximum {
+			return fmt.Errorf("%s: %v is more than %v", path, value, maximum)
+		}
+	case string:
+		if schema.string("format") == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", path, value)
+			}
+		}
+	case []interface{}:
+		items := schema.object("items")
+		for i, item := range value {
+			if err := c.validate(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				return fmt.Errorf("%s: %s is missing", path, name)
+			}
+		}
+
+		properties := schema.object("properties")
+		for name, property := range value {
+			propertySchema := properties.object(name)
+			if propertySchema == nil {
+				propertySchema = schema.object("additionalProperties")
+			}
+
+			if propertySchema == nil {
+				return fmt.Errorf("%s: %s is not documented", path, name)
+			}
+
+			if err := c.validate(property, propertySchema, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func schemaTypes(schemaType interface{}) map[string]bool {
+	types := make(map[string]bool)
+
+	switch schemaType := schemaType.(type) {
+	case string:
+		types[schemaType] = true
+	case []interface{}:
+		for _, t := range schemaType {
+			types[t.(string)] = true
+		}
+	}
+
+	return types
+}
+
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// account registers an account and returns its id and a token for it.
+func (c *contract) account(username string, bot bool) (string, string) {
+	c.t.Helper()
+
+	credentials := credentialsRequest{Username: username, Password: "password1"}
+	id := c.call("POST", "/api/accounts", "/api/accounts",
+		registerRequest{Username: username, Password: "password1", Bot: bot}, "", http.StatusCreated).string("id")
+	token := c.call("POST", "/api/auth/login", "/api/auth/login", credentials, "", http.StatusOK).string("token")
+
+	return id, token
+}
+
+func (c *contract) newGame(request newGameRequest, token string) jsonObject {
+	c.t.Helper()
+
+	return c.call("POST", "/api/games", "/api/games", request, token, http.StatusCreated)
+}
+
+func (c *contract) join(gameId string, request interface{}, token string, status int) {
+	c.t.Helper()
+
+	c.call("POST", "/api/games/{gameId}/join", "/api/games/"+gameId+"/join", request, token, status)
+}
+
+func (c *contract) move(gameId string, fromX int, fromY int, toX int, toY int, token string, status int) {
+	c.t.Helper()
+
+	c.call("POST", "/api/games/{gameId}/moves", "/api/games/"+gameId+"/moves", moveRequest{
+		From: dtos.Position{X: fromX, Y: fromY},
+		To:   dtos.Position{X: toX, Y: toY},
+	}, token, status)
+}
+
+func (c *contract) resign(gameId string, token string, status int) {
+	c.t.Helper()
+
+	c.call("POST", "/api/games/{gameId}/resign", "/api/games/"+gameId+"/resign", nil, token, status)
+}
+
+func TestApiMatchesOpenApiDocument(t *testing.T) {
+	c := newContract(t)
+	blitz := &timeControlDto{InitialSeconds: 180, IncrementSeconds: 2}
+
+	c.call("GET", "/api/health", "/api/health", nil, "", http.StatusOK)
+	c.call("HEAD", "/api/health", "/api/health", nil, "", http.StatusOK)
+	c.call("GET", "/api/openapi.json", "/api/openapi.json", nil, "", http.StatusOK)
+	c.call("GET", "/api/asyncapi.json", "/api/asyncapi.json", nil, "", http.StatusOK)
+	c.call("GET", "/api/routes", "/api/routes", nil, "", http.StatusOK)
+
+	// accounts
+	aliceId, alice := c.account("alice", false)
+	_, bob := c.account("bob", false)
+	_, carol := c.account("carol", false)
+	_, robot := c.account("robot", true)
+	guest := c.call("POST", "/api/auth/guest", "/api/auth/guest", guestRequest{Name: "Guest"}, "", http.StatusOK).string("token")
+	c.call("POST", "/api/auth/guest", "/api/auth/guest", guestRequest{Name: "ALICE"}, "", http.StatusConflict)
+	c.call("POST", "/api/auth/guest", "/api/auth/guest", guestRequest{Name: "a"}, "", http.StatusBadRequest)
+
+	c.call("POST", "/api/accounts", "/api/accounts", registerRequest{Username: "Alice", Password: "password1"}, "", http.StatusConflict)
+	c.call("POST", "/api/accounts", "/api/accounts", registerRequest{Username: "x", Password: "password1"}, "", http.StatusBadRequest)
+	c.call("POST", "/api/auth/login", "/api/auth/login", credentialsRequest{Username: "alice", Password: "wrong"}, "", http.StatusUnauthorized)
+	c.call("GET", "/api/accounts/me", "/api/accounts/me", nil, alice, http.StatusOK)
+	c.call("GET", "/api/accounts/me", "/api/accounts/me", nil, "", http.StatusUnauthorized)
+
+	// a rated game from the first move to the analysis
+	gameId := c.newGame(newGameRequest{Color: "white", StartingColor: "white", IsPublic: true, Rated: true, TimeControl: blitz}, alice).string("gameId")
+	c.call("POST", "/api/games", "/api/games", "not a game", "", http.StatusBadRequest)
+	c.call("POST", "/api/games", "/api/games", newGameRequest{Color: "purple", StartingColor: "white"}, "", http.StatusBadRequest)
+	c.call("POST", "/api/games", "/api/games", newGameRequest{Color: "white", StartingColor: "randomColor"}, "", http.StatusBadRequest)
+	c.call("POST", "/api/games", "/api/games", newGameRequest{Color: "white", StartingColor: "white",
+		StartingPieces: []StartingPiece{{X: 4, Y: 0, Type: "dragon", Color: "white"}}}, "", http.StatusBadRequest)
+	c.call("POST", "/api/games", "/api/games", newGameRequest{Color: "white", StartingColor: "white",
+		StartingPieces: []StartingPiece{{X: 4, Y: 8, Type: "king", Color: "white"}}}, "", http.StatusBadRequest)
+	kings := []StartingPiece{{X: 4, Y: 0, Type: "king", Color: "white"}, {X: 4, Y: 7, Type: "king", Color: "black"}}
+	c.newGame(newGameRequest{Color: "white", StartingColor: "white", StartingPieces: kings, Bot: &botDto{Skill: 1}}, "")
+	c.call("POST", "/api/games", "/api/games", newGameRequest{Color: "white", StartingColor: "white",
+		StartingPieces: kings[:1], Bot: &botDto{Skill: 1}}, "", http.StatusBadRequest)
+	c.call("GET", "/api/games", "/api/games", nil, "", http.StatusOK)
+	c.call("GET", "/api/games", "/api/games?variant=nope", nil, "", http.StatusBadRequest)
+
+	c.join(gameId, nil, alice, http.StatusOK)
+	c.join(gameId, nil, bob, http.StatusOK)
+	c.join(gameId, nil, guest, http.StatusConflict)
+	c.join(gameId, nil, "unsigned", http.StatusUnauthorized)
+	c.join("nope", nil, carol, http.StatusNotFound)
+
+	c.move(gameId, 4, 6, 4, 4, bob, http.StatusUnprocessableEntity)
+	c.move(gameId, 4, 1, 4, 3, carol, http.StatusForbidden)
+	c.move(gameId, 4, 1, 4, 3, alice, http.StatusCreated)
+	c.call("GET", "/api/games/{gameId}/validmoves/{fromX}/{fromY}", "/api/games/"+gameId+"/validmoves/4/6", nil, bob, http.StatusOK)
+	c.call("GET", "/api/games/{gameId}", "/api/games/"+gameId, nil, alice, http.StatusOK)
+	c.call("GET", "/api/games/{gameId}", "/api/games/nope", nil, alice, http.StatusNotFound)
+	c.call("POST", "/api/games/{gameId}/draw", "/api/games/"+gameId+"/draw", drawRequest{Action: "offer"}, alice, http.StatusOK)
+	c.call("POST", "/api/games/{gameId}/draw", "/api/games/"+gameId+"/draw", drawRequest{Action: "shrug"}, bob, http.StatusBadRequest)
+	c.call("POST", "/api/games/{gameId}/analysis", "/api/games/"+gameId+"/analysis", nil, alice, http.StatusConflict)
+	c.resign(gameId, bob, http.StatusOK)
+	c.resign(gameId, bob, http.StatusConflict)
+	c.call("GET", "/api/games/{gameId}/history", "/api/games/"+gameId+"/history", nil, "", http.StatusOK)
+	c.call("GET", "/api/games/{gameId}/history", "/api/games/nope/history", nil, "", http.StatusNotFound)
+	c.call("POST", "/api/games/{gameId}/analysis", "/api/games/"+gameId+"/analysis", nil, alice, http.StatusAccepted)
+	c.call("GET", "/api/games/{gameId}/analysis", "/api/games/"+gameId+"/analysis", nil, alice, http.StatusOK)
+
+	// invites to a private game
+	private := c.newGame(newGameRequest{Color: "white", StartingColor: "white", Private: true, Password: "sesame"}, alice)
+	privateId := private.string("gameId")
+	c.join(privateId, joinGameRequest{Invite: private.object("seatInvite").string("invite")}, alice, http.StatusOK)
+	c.join(privateId, joinGameRequest{Password: "wrong"}, bob, http.StatusForbidden)
+	c.call("POST", "/api/games/{gameId}/invites", "/api/games/"+privateId+"/invites", newInviteRequest{Role: "spectator"}, alice, http.StatusCreated)
+	c.call("POST", "/api/games/{gameId}/invites", "/api/games/"+privateId+"/invites", newInviteRequest{Role: "king"}, alice, http.StatusBadRequest)
+	c.call("GET", "/api/games/{gameId}", "/api/games/"+privateId, nil, carol, http.StatusForbidden)
+
+	// ratings and statistics
+	c.call("GET", "/api/players/{playerId}/ratings", "/api/players/"+aliceId+"/ratings", nil, "", http.StatusOK)
+	c.call("GET", "/api/players/{playerId}/ratings", "/api/players/nobody/ratings", nil, "", http.StatusNotFound)
+	c.call("GET", "/api/players/{playerId}/stats", "/api/players/"+aliceId+"/stats", nil, "", http.StatusOK)
+	c.call("GET", "/api/players/{playerId}/games", "/api/players/"+aliceId+"/games", nil, "", http.StatusOK)
+	c.call("GET", "/api/players/{playerId}/games", "/api/players/"+aliceId+"/games?limit=0", nil, "", http.StatusBadRequest)
+	c.call("GET", "/api/leaderboards/{category}", "/api/leaderboards/blitz", nil, "", http.StatusOK)
+	c.call("GET", "/api/leaderboards/{category}", "/api/leaderboards/nope", nil, "", http.StatusNotFound)
+
+	// tournaments
+	tournamentId := c.call("POST", "/api/tournaments", "/api/tournaments",
+		newTournamentRequest{Name: "Weekly", Format: "swiss", Rounds: 3, TimeControl: blitz, Rated: true}, alice, http.StatusCreated).string("tournamentId")
+	c.call("POST", "/api/tournaments", "/api/tournaments", newTournamentRequest{Name: "Weekly", Format: "nope"}, alice, http.StatusBadRequest)
+	for _, token := range []string{alice, bob, carol} {
+		c.call("POST", "/api/tournaments/{tournamentId}/join", "/api/tournaments/"+tournamentId+"/join", nil, token, http.StatusOK)
+	}
+	c.call("POST", "/api/tournaments/{tournamentId}/join", "/api/tournaments/"+tournamentId+"/join", nil, guest, http.StatusForbidden)
+	c.call("POST", "/api/tournaments/{tournamentId}/withdraw", "/api/tournaments/"+tournamentId+"/withdraw", nil, carol, http.StatusOK)
+	c.call("POST", "/api/tournaments/{tournamentId}/start", "/api/tournaments/"+tournamentId+"/start", nil, bob, http.StatusForbidden)
+	c.call("POST", "/api/tournaments/{tournamentId}/start", "/api/tournaments/"+tournamentId+"/start", nil, alice, http.StatusOK)
+	c.call("GET", "/api/tournaments", "/api/tournaments", nil, "", http.StatusOK)
+	c.call("GET", "/api/tournaments/{tournamentId}", "/api/tournaments/"+tournamentId, nil, "", http.StatusOK)
+	c.call("GET", "/api/tournaments/{tournamentId}/rounds/{round}", "/api/tournaments/"+tournamentId+"/rounds/1", nil, "", http.StatusOK)
+	c.call("GET", "/api/tournaments/{tournamentId}/rounds/{round}", "/api/tournaments/"+tournamentId+"/rounds/9", nil, "", http.StatusNotFound)
+
+	// arenas
+	arenaId := c.call("POST", "/api/arenas", "/api/arenas",
+		newArenaRequest{Name: "Hourly", DurationMinutes: 60, TimeControl: blitz}, alice, http.StatusCreated).string("arenaId")
+	c.call("POST", "/api/arenas", "/api/arenas", newArenaRequest{Name: "Hourly", DurationMinutes: 60}, alice, http.StatusBadRequest)
+	c.call("POST", "/api/arenas/{arenaId}/join", "/api/arenas/"+arenaId+"/join", nil, alice, http.StatusOK)
+	c.call("POST", "/api/arenas/{arenaId}/join", "/api/arenas/"+arenaId+"/join", nil, bob, http.StatusOK)
+	c.call("POST", "/api/arenas/{arenaId}/join", "/api/arenas/"+arenaId+"/join", nil, bob, http.StatusConflict)
+	c.arenas.Tick()
+	arena := c.call("GET", "/api/arenas/{arenaId}", "/api/arenas/"+arenaId, nil, "", http.StatusOK)
+	arenaGameId := arena.array("leaderboard")[0].string("gameId")
+	c.call("POST", "/api/games/{gameId}/berserk", "/api/games/"+arenaGameId+"/berserk", nil, alice, http.StatusOK)
+	c.call("POST", "/api/games/{gameId}/berserk", "/api/games/"+arenaGameId+"/berserk", nil, alice, http.StatusConflict)
+	c.call("POST", "/api/arenas/{arenaId}/withdraw", "/api/arenas/"+arenaId+"/withdraw", nil, bob, http.StatusOK)
+	c.call("GET", "/api/arenas", "/api/arenas", nil, "", http.StatusOK)
+
+	// simuls
+	simulId := c.call("POST", "/api/simuls", "/api/simuls",
+		newSimulRequest{Name: "Simul", HostColor: "white", TimeControl: blitz}, alice, http.StatusCreated).string("simulId")
+	c.call("POST", "/api/simuls/{simulId}/join", "/api/simuls/"+simulId+"/join", nil, carol, http.StatusOK)
+	c.call("POST", "/api/simuls/{simulId}/withdraw", "/api/simuls/"+simulId+"/withdraw", nil, carol, http.StatusOK)
+	c.call("POST", "/api/simuls/{simulId}/start", "/api/simuls/"+simulId+"/start", nil, alice, http.StatusConflict)
+	c.call("POST", "/api/simuls/{simulId}/join", "/api/simuls/"+simulId+"/join", nil, carol, http.StatusOK)
+	c.call("POST", "/api/simuls/{simulId}/start", "/api/simuls/"+simulId+"/start", nil, alice, http.StatusOK)
+	c.call("GET", "/api/simuls", "/api/simuls", nil, "", http.StatusOK)
+	c.call("GET", "/api/simuls/{simulId}", "/api/simuls/"+simulId, nil, "", http.StatusOK)
+	c.call("GET", "/api/simuls/{simulId}", "/api/simuls/nope", nil, "", http.StatusNotFound)
+
+	// challenges and the bot api
+	challenge := func(token string) string {
+		return c.call("POST", "/api/challenges", "/api/challenges",
+			newChallengeRequest{Username: "robot", Color: "white", StartingColor: "white", TimeControl: blitz}, token, http.StatusCreated).string("id")
+	}
+	c.call("POST", "/api/challenges", "/api/challenges", newChallengeRequest{Username: "nobody", Color: "white", StartingColor: "white"}, alice, http.StatusNotFound)
+	c.call("POST", "/api/challenges", "/api/challenges", newChallengeRequest{Username: "robot", Color: "white", StartingColor: "white",
+		StartingPieces: []StartingPiece{{X: 4, Y: 0, Type: "king", Color: "green"}}}, alice, http.StatusBadRequest)
+	c.call("POST", "/api/challenges", "/api/challenges", newChallengeRequest{Username: "robot", Color: "white", StartingColor: "white",
+		StartingPieces: []StartingPiece{{X: 4, Y: 0, Type: "dragon", Color: "white"}}}, alice, http.StatusBadRequest)
+	c.call("POST", "/api/challenges", "/api/challenges", newChallengeRequest{Username: "robot", Color: "white", StartingColor: "white", Rated: true}, guest, http.StatusForbidden)
+
+	declined := challenge(alice)
+	c.call("POST", "/api/challenges/{challengeId}/decline", "/api/challenges/"+declined+"/decline", nil, robot, http.StatusOK)
+	cancelled := challenge(bob)
+	c.call("POST", "/api/challenges/{challengeId}/cancel", "/api/challenges/"+cancelled+"/cancel", nil, bob, http.StatusOK)
+	accepted := challenge(carol)
+	c.call("GET", "/api/challenges", "/api/challenges", nil, robot, http.StatusOK)
+	c.call("POST", "/api/challenges/{challengeId}/accept", "/api/challenges/"+accepted+"/accept", nil, carol, http.StatusForbidden)
+
+	botGameId := c.newGame(newGameRequest{Color: "white", StartingColor: "white"}, alice).string("gameId")
+	c.join(botGameId, nil, alice, http.StatusOK)
+	c.join(botGameId, nil, robot, http.StatusOK)
+
+	if events := c.stream("/api/bot/stream/event", "/api/bot/stream/event", robot); len(events) != 2 {
+		t.Errorf("%d events for the pending challenge and the running game, want 2", len(events))
+	}
+	c.call("GET", "/api/bot/stream/event", "/api/bot/stream/event", nil, alice, http.StatusForbidden)
+	c.call("POST", "/api/challenges/{challengeId}/accept", "/api/challenges/"+accepted+"/accept", nil, robot, http.StatusOK)
+
+	c.move(botGameId, 4, 1, 4, 3, alice, http.StatusCreated)
+	c.call("POST", "/api/bot/game/{gameId}/move/{move}", "/api/bot/game/"+botGameId+"/move/e7e5", nil, robot, http.StatusCreated)
+	c.call("POST", "/api/bot/game/{gameId}/move/{move}", "/api/bot/game/"+botGameId+"/move/e7e5", nil, robot, http.StatusUnprocessableEntity)
+	c.call("POST", "/api/bot/game/{gameId}/move/{move}", "/api/bot/game/"+botGameId+"/move/e7", nil, robot, http.StatusBadRequest)
+	c.call("POST", "/api/bot/game/{gameId}/chat", "/api/bot/game/"+botGameId+"/chat", botChatRequest{Text: "good luck"}, robot, http.StatusCreated)
+	c.call("POST", "/api/bot/game/{gameId}/chat", "/api/bot/game/"+botGameId+"/chat", botChatRequest{Text: ""}, robot, http.StatusBadRequest)
+	c.stream("/api/bot/game/stream/{gameId}", "/api/bot/game/stream/"+botGameId, robot)
+	c.call("POST", "/api/bot/game/{gameId}/resign", "/api/bot/game/"+botGameId+"/resign", nil, robot, http.StatusOK)
+	c.call("POST", "/api/bot/game/{gameId}/resign", "/api/bot/game/"+botGameId+"/resign", nil, robot, http.StatusConflict)
+	c.stream("/api/bot/game/stream/{gameId}", "/api/bot/game/stream/"+botGameId, robot)
+
+	// correspondence
+	correspondenceId := c.newGame(newGameRequest{Color: "white", StartingColor: "white",
+		TimeControl: &timeControlDto{DaysPerMove: 3, VacationDays: 7}}, alice).string("gameId")
+	c.join(correspondenceId, nil, alice, http.StatusOK)
+	c.join(correspondenceId, nil, bob, http.StatusOK)
+	c.call("GET", "/api/games/{gameId}", "/api/games/"+correspondenceId, nil, alice, http.StatusOK)
+	c.call("GET", "/api/correspondence/turns", "/api/correspondence/turns", nil, alice, http.StatusOK)
+	c.call("GET", "/api/correspondence/turns", "/api/correspondence/turns", nil, "", http.StatusUnauthorized)
+	c.call("POST", "/api/correspondence/vacation", "/api/correspondence/vacation", nil, alice, http.StatusOK)
+	c.call("DELETE", "/api/correspondence/vacation", "/api/correspondence/vacation", nil, alice, http.StatusOK)
+
+	var missing []string
+	for path, operations := range c.document.object("paths") {
+		for method := range operations.(map[string]interface{}) {
+			if method == "parameters" {
+				continue
+			}
+
+			if operation := strings.ToUpper(method) + " " + path; !c.called[operation] {
+				missing = append(missing, operation)
+			}
+		}
+	}
+	sort.Strings(missing)
+
+	for _, operation := range missing {
+		t.Errorf("%s was not called", operation)
+	}
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	c := newContract(t)
+	parameterType := regexp.MustCompile(`:\w+}`)
+
+	for _, route := range c.router.Routes() {
+		path := parameterType.ReplaceAllString(route.Pattern, "}")
+
+		if c.document.object("paths").object(path).object(strings.ToLower(route.Method)) == nil {
+			t.Errorf("%s %s is not documented", route.Method, route.Pattern)
+		}
+	}
+}
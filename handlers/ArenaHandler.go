@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/racccoooon/chess-be/arenas"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/routing"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type ArenaHandler struct {
+	manager *arenas.Manager
+	ratings *ratings.Store
+}
+
+func NewArenaHandler(manager *arenas.Manager, ratings *ratings.Store) *ArenaHandler {
+	return &ArenaHandler{manager: manager, ratings: ratings}
+}
+
+func (h *ArenaHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/arenas", h.newArena)
+	router.Handle(http.MethodGet, "/api/arenas", h.getArenas)
+	router.Handle(http.MethodGet, "/api/arenas/{arenaId}", h.getArena)
+	router.Handle(http.MethodPost, "/api/arenas/{arenaId}/join", h.join)
+	router.Handle(http.MethodPost, "/api/arenas/{arenaId}/withdraw", h.withdraw)
+}
+
+type newArenaRequest struct {
+	Name            string          `json:"name"`
+	TimeControl     *timeControlDto `json:"timeControl"`
+	Rated           bool            `json:"rated"`
+	StartsAt        *time.Time      `json:"startsAt"` // null to start right away
+	DurationMinutes int             `json:"durationMinutes"`
+}
+
+type newArenaResponse struct {
+	ArenaId string `json:"arenaId"`
+}
+
+func (h *ArenaHandler) newArena(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	var request newArenaRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// arenas need clocks, otherwise a single game could last until the end
+	if strings.TrimSpace(request.Name) == "" || request.DurationMinutes < 1 || request.TimeControl == nil ||
+		request.TimeControl.InitialSeconds <= 0 || request.TimeControl.IncrementSeconds < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	startTime := time.Now()
+	if request.StartsAt != nil && request.StartsAt.After(startTime) {
+		startTime = *request.StartsAt
+	}
+
+	arena := h.manager.NewArena(arenas.Settings{
+		Name: strings.TrimSpace(request.Name),
+		TimeControl: game.NewTimeControl(
+			time.Duration(request.TimeControl.InitialSeconds)*time.Second,
+			time.Duration(request.TimeControl.IncrementSeconds)*time.Second),
+		Rated:     request.Rated,
+		StartTime: startTime,
+		Duration:  time.Duration(request.DurationMinutes) * time.Minute,
+		OwnerId:   identity.Subject,
+	})
+
+	writeJson(w, http.StatusCreated, newArenaResponse{
+		ArenaId: string(arena.Id()),
+	})
+}
+
+type getArenasResponse struct {
+	Arenas []arenaSummaryResponse `json:"arenas"`
+}
+
+type arenaSummaryResponse struct {
+	Id          string           `json:"id"`
+	Name        string           `json:"name"`
+	Status      string           `json:"status"`
+	PlayerCount int              `json:"playerCount"`
+	TimeControl dtos.TimeControl `json:"timeControl"`
+	Rated       bool             `json:"rated"`
+	StartsAt    time.Time        `json:"startsAt"`
+	EndsAt      time.Time        `json:"endsAt"`
+}
+
+func (h *ArenaHandler) getArenas(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	response := getArenasResponse{
+		Arenas: []arenaSummaryResponse{},
+	}
+
+	for _, arena := range h.manager.GetArenas() {
+		response.Arenas = append(response.Arenas, arenaAsSummary(arena))
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func arenaAsSummary(arena *arenas.Arena) arenaSummaryResponse {
+	return arenaSummaryResponse{
+		Id:          string(arena.Id()),
+		Name:        arena.Name(),
+		Status:      constants.TournamentStatusAsString(arena.Status()),
+		PlayerCount: arena.PlayerCount(),
+		TimeControl: dtos.TimeControlAsResponse(arena.TimeControl()),
+		Rated:       arena.IsRated(),
+		StartsAt:    arena.StartTime(),
+		EndsAt:      arena.EndTime(),
+	}
+}
+
+type arenaResponse struct {
+	arenaSummaryResponse
+	OwnerId     string                  `json:"ownerId"`
+	Leaderboard []arenaStandingResponse `json:"leaderboard"`
+}
+
+type arenaStandingResponse struct {
+	Rank     int                   `json:"rank"`
+	PlayerId string                `json:"playerId"`
+	Name     string                `json:"name"`
+	Rating   int                   `json:"rating"`
+	Score    int                   `json:"score"`
+	Games    int                   `json:"games"`
+	Wins     int                   `json:"wins"`
+	OnFire   bool                  `json:"onFire"`
+	Paused   bool                  `json:"paused"`
+	GameId   *string               `json:"gameId"` // the game the player is playing right now
+	Sheet    []arenaResultResponse `json:"sheet"`
+}
+
+type arenaResultResponse struct {
+	Points  int  `json:"points"`
+	Fire    bool `json:"fire"`
+	Berserk bool `json:"berserk"`
+}
+
+func arenaAsResponse(arena *arenas.Arena) arenaResponse {
+	response := arenaResponse{
+		arenaSummaryResponse: arenaAsSummary(arena),
+		OwnerId:              arena.OwnerId(),
+		Leaderboard:          []arenaStandingResponse{},
+	}
+
+	for _, standing := range arena.Leaderboard() {
+		item := arenaStandingResponse{
+			Rank:     standing.Rank,
+			PlayerId: standing.PlayerId,
+			Name:     standing.Name,
+			Rating:   standing.Rating,
+			Score:    standing.Score,
+			Games:    standing.Games,
+			Wins:     standing.Wins,
+			OnFire:   standing.OnFire,
+			Paused:   standing.Paused,
+			Sheet:    []arenaResultResponse{},
+		}
+
+		if standing.GameId != "" {
+			gameId := string(standing.GameId)
+			item.GameId = &gameId
+		}
+
+		for _, result := range standing.Results {
+			item.Sheet = append(item.Sheet, arenaResultResponse{
+				Points:  result.Points,
+				Fire:    result.Fire,
+				Berserk: result.Berserk,
+			})
+		}
+
+		response.Leaderboard = append(response.Leaderboard, item)
+	}
+
+	return response
+}
+
+func (h *ArenaHandler) getArena(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	arena := h.manager.GetArena(arenas.Id(params.String("arenaId")))
+	if arena == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, arenaAsResponse(arena))
+}
+
+func (h *ArenaHandler) join(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	arena := h.manager.GetArena(arenas.Id(params.String("arenaId")))
+	if arena == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if arena.IsRated() && !identity.HasAccount() {
+		writeJson(w, http.StatusForbidden, errorResponse{Error: errRatedGameNeedsAccount})
+		return
+	}
+
+	rating := h.ratings.GetRating(identity.Subject, arena.TimeControl().Category())
+
+	err := arena.Join(identity.Subject, identity.Name, int(math.Round(rating.Rating())))
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, arenaAsResponse(arena))
+}
+
+func (h *ArenaHandler) withdraw(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	arena := h.manager.GetArena(arenas.Id(params.String("arenaId")))
+	if arena == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := arena.Withdraw(identity.Subject)
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, arenaAsResponse(arena))
+}
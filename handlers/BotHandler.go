@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/engines"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/routing"
+	"github.com/racccoooon/chess-be/streams"
+	"net/http"
+	"time"
+)
+
+// streamKeepAlive is how often an empty line is written to idle streams, so proxies don't close them.
+const streamKeepAlive = 7 * time.Second
+
+const errBotAccountRequired = "only bot accounts can use the bot api"
+
+// BotHandler lets programs play with a bot account over plain http: they follow newline-delimited json streams
+// and answer with moves, instead of implementing SignalR.
+type BotHandler struct {
+	manager    *game.Manager
+	challenges *challenges.Manager
+	broker     *streams.Broker
+}
+
+func NewBotHandler(manager *game.Manager, challenges *challenges.Manager, broker *streams.Broker) *BotHandler {
+	return &BotHandler{manager: manager, challenges: challenges, broker: broker}
+}
+
+func (h *BotHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodGet, "/api/bot/stream/event", h.streamEvents)
+	router.Handle(http.MethodGet, "/api/bot/game/stream/{gameId}", h.streamGame)
+	router.Handle(http.MethodPost, "/api/bot/game/{gameId}/move/{move}", h.move)
+	router.Handle(http.MethodPost, "/api/bot/game/{gameId}/resign", h.resign)
+	router.Handle(http.MethodPost, "/api/bot/game/{gameId}/chat", h.chat)
+}
+
+func readBotToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return "", false
+	}
+
+	if identity.Kind != constants.BotIdentity {
+		writeJson(w, http.StatusForbidden, errorResponse{Error: errBotAccountRequired})
+		return "", false
+	}
+
+	return identity.Subject, true
+}
+
+// streamEvents streams the challenges of the bot and its games starting and finishing. Pending challenges and games
+// that are already running are sent first.
+func (h *BotHandler) streamEvents(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readBotToken(w, r)
+	if !ok {
+		return
+	}
+
+	subscription := h.broker.SubscribePlayer(token)
+	defer h.broker.Unsubscribe(subscription)
+
+	var initial []interface{}
+	for _, challenge := range h.challenges.GetChallengesFor(token) {
+		initial = append(initial, streams.ChallengeUpdate(challenge))
+	}
+
+	for _, playerGame := range h.manager.GetGamesForPlayer(token) {
+		game := h.manager.GetGame(playerGame.Id())
+		if game != nil && !game.IsOver() && game.PlayerCount() == 2 {
+			initial = append(initial, streams.GameStart(game, playerGame.Color()))
+		}
+	}
+
+	// a client that fell behind reconnects and gets the pending challenges and running games again
+	writeStream(w, r, initial, subscription, func(event interface{}) bool {
+		return false
+	}, func() []interface{} {
+		return nil
+	})
+}
+
+// streamGame streams the full game once, then every change of its state and its chat until the game is over.
+func (h *BotHandler) streamGame(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readBotToken(w, r)
+	if !ok {
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, game.Id(params.String("gameId")))
+	if player == nil {
+		return
+	}
+
+	// subscribing before reading the state makes sure no move is missed in between
+	subscription := h.broker.SubscribeGame(game.Id())
+	defer h.broker.Unsubscribe(subscription)
+
+	full := streams.GameFull(game)
+	if game.IsOver() {
+		writeStream(w, r, []interface{}{full}, nil, nil, nil)
+		return
+	}
+
+	// the stream ends with the current state when the game ends or the client fell behind
+	writeStream(w, r, []interface{}{full}, subscription, func(event interface{}) bool {
+		state, ok := event.(streams.GameStateEvent)
+		return ok && state.Result != constants.ResultAsString(constants.InProgress)
+	}, func() []interface{} {
+		return []interface{}{streams.GameState(game)}
+	})
+}
+
+// writeStream writes the initial events and then the events of the subscription as newline-delimited json,
+// until the client goes away, the subscription is nil or isLast returns true for a written event.
+// Once the subscription is done, the events it still holds and the events from catchUp are written last.
+func writeStream(w http.ResponseWriter, r *http.Request, initial []interface{}, subscription *streams.Subscription,
+	isLast func(event interface{}) bool, catchUp func() []interface{}) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for _, event := range initial {
+		if encoder.Encode(event) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	if subscription == nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := w.Write([]byte("\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event := <-subscription.Events():
+			if encoder.Encode(event) != nil {
+				return
+			}
+			flusher.Flush()
+
+			if isLast(event) {
+				return
+			}
+		case <-subscription.Done():
+			for {
+				select {
+				case event := <-subscription.Events():
+					if encoder.Encode(event) != nil || isLast(event) {
+						flusher.Flush()
+						return
+					}
+				default:
+					for _, event := range catchUp() {
+						if encoder.Encode(event) != nil {
+							return
+						}
+					}
+					flusher.Flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *BotHandler) move(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readBotToken(w, r)
+	if !ok {
+		return
+	}
+
+	move, ok := engines.ParseMove(params.String("move"))
+	if !ok {
+		writeJson(w, http.StatusBadRequest, errorResponse{Error: "moves are written in coordinate notation like e2e4 or e7e8q"})
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, game.Id(params.String("gameId")))
+	if player == nil {
+		return
+	}
+
+	if game.ActiveColor() != player.Color() {
+		writeJson(w, http.StatusUnprocessableEntity, invalidMoveResponse{
+			Reason: constants.MoveErrorAsString(constants.NotYourTurn),
+		})
+		return
+	}
+
+	playMove(w, game, player, move.FromX, move.FromY, move.ToX, move.ToY, move.PromoteToType)
+}
+
+func (h *BotHandler) resign(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readBotToken(w, r)
+	if !ok {
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, game.Id(params.String("gameId")))
+	if player == nil {
+		return
+	}
+
+	if !game.Resign(player.Color()) {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	writeJson(w, http.StatusOK, gameAsGameState(game, token))
+}
+
+type botChatRequest struct {
+	Text string `json:"text"`
+}
+
+type chatMessageResponse struct {
+	Name   string    `json:"name"`
+	Color  string    `json:"color"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sentAt"`
+}
+
+func (h *BotHandler) chat(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readBotToken(w, r)
+	if !ok {
+		return
+	}
+
+	var request botChatRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, game.Id(params.String("gameId")))
+	if player == nil {
+		return
+	}
+
+	message, reason := game.Chat(player, request.Text)
+	if reason == constants.RateLimited {
+		writeJson(w, http.StatusTooManyRequests, errorResponse{Error: "at most 5 chat messages can be sent in 10 seconds"})
+		return
+	}
+
+	if reason != constants.ChatAllowed {
+		writeJson(w, http.StatusBadRequest, errorResponse{Error: "chat messages must not be empty or longer than 140 characters"})
+		return
+	}
+
+	writeJson(w, http.StatusCreated, chatMessageResponse{
+		Name:   message.Name(),
+		Color:  constants.ColorAsString(message.Color()),
+		Text:   message.Text(),
+		SentAt: message.SendTime(),
+	})
+}
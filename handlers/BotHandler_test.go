@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/racccoooon/chess-be/game"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameStreamEnds(t *testing.T) {
+	tests := []struct {
+		name       string
+		act        func(c *contract, gameId string, token string)
+		wantResult string
+	}{
+		{
+			name: "the game ended",
+			act: func(c *contract, gameId string, token string) {
+				// resigning again is rejected, which does not matter here
+				request := httptest.NewRequest(http.MethodPost, "/api/games/"+gameId+"/resign", nil)
+				request.Header.Set("Authorization", "Bearer "+token)
+				c.handler.ServeHTTP(httptest.NewRecorder(), request)
+			},
+			wantResult: "blackWon",
+		},
+		{
+			name: "the client fell behind",
+			act: func(c *contract, gameId string, token string) {
+				for i := 0; i < 100; i++ {
+					c.broker.PublishToGame(game.Id(gameId), struct{}{})
+				}
+			},
+			wantResult: "inProgress",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newContract(t)
+			_, alice := c.account("alice", false)
+			_, robot := c.account("robot", true)
+
+			gameId := c.newGame(newGameRequest{Color: "white", StartingColor: "white"}, alice).string("gameId")
+			c.join(gameId, nil, alice, http.StatusOK)
+			c.join(gameId, nil, robot, http.StatusOK)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			request := httptest.NewRequest(http.MethodGet, "/api/bot/game/stream/"+gameId, nil).WithContext(ctx)
+			request.Header.Set("Authorization", "Bearer "+robot)
+			recorder := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				c.handler.ServeHTTP(recorder, request)
+				close(done)
+			}()
+
+			// events published before the stream subscribed are lost, so keep going until it ends
+			for ended := false; !ended; {
+				test.act(c, gameId, alice)
+
+				select {
+				case <-done:
+					ended = true
+				case <-time.After(10 * time.Millisecond):
+				}
+
+				if ctx.Err() != nil {
+					t.Fatal("the stream did not end")
+				}
+			}
+
+			var last jsonObject
+			scanner := bufio.NewScanner(recorder.Body)
+			for scanner.Scan() {
+				if strings.TrimSpace(scanner.Text()) != "" {
+					last = nil
+					if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+						t.Fatalf("event is not valid json: %v", err)
+					}
+				}
+			}
+
+			// a game that ended before the stream subscribed is streamed in full once
+			state := last
+			if last.string("type") == "gameFull" {
+				state = last.object("state")
+			}
+
+			if state.string("type") != "gameState" || state.string("result") != test.wantResult {
+				t.Errorf("last event = %v, want the %s game state", last, test.wantResult)
+			}
+		})
+	}
+}
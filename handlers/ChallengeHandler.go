@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/routing"
+	"net/http"
+	"time"
+)
+
+type ChallengeHandler struct {
+	manager  *challenges.Manager
+	accounts *accounts.Store
+}
+
+func NewChallengeHandler(manager *challenges.Manager, accounts *accounts.Store) *ChallengeHandler {
+	return &ChallengeHandler{manager: manager, accounts: accounts}
+}
+
+func (h *ChallengeHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/challenges", h.newChallenge)
+	router.Handle(http.MethodGet, "/api/challenges", h.getChallenges)
+	router.Handle(http.MethodPost, "/api/challenges/{challengeId}/accept", h.accept)
+	router.Handle(http.MethodPost, "/api/challenges/{challengeId}/decline", h.decline)
+	router.Handle(http.MethodPost, "/api/challenges/{challengeId}/cancel", h.cancel)
+}
+
+type newChallengeRequest struct {
+	Username       string          `json:"username"` // the account that is challenged
+	Color          string          `json:"color"`    // white or black or randomColor, the color of the challenger
+	StartingPieces []StartingPiece `json:"startingPieces"`
+	StartingColor  string          `json:"startingColor"`
+	TimeControl    *timeControlDto `json:"timeControl"` // null for games without clocks
+	Rated          bool            `json:"rated"`
+}
+
+type challengeResponse struct {
+	Id          string                   `json:"id"`
+	Challenger  tournamentPlayerResponse `json:"challenger"`
+	Destination tournamentPlayerResponse `json:"destination"`
+	Color       string                   `json:"color"`
+	Variant     string                   `json:"variant"`
+	TimeControl dtos.TimeControl         `json:"timeControl"`
+	Rated       bool                     `json:"rated"`
+	Status      string                   `json:"status"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	ExpiresAt   time.Time                `json:"expiresAt"`
+	GameId      *string                  `json:"gameId"` // set once the challenge was accepted
+}
+
+type getChallengesResponse struct {
+	Challenges []challengeResponse `json:"challenges"`
+}
+
+func (h *ChallengeHandler) newChallenge(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	var request newChallengeRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !isColor(request.Color, true) || !isColor(request.StartingColor, false) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	startingPieces, ok := startingPiecesFromRequest(request.StartingPieces)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// games from a custom position can not be rated
+	if request.Rated && len(startingPieces) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timeControl, ok := timeControlFromRequest(request.TimeControl)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	destination := h.accounts.GetAccountByUsername(request.Username)
+	if destination == nil {
+		writeJson(w, http.StatusNotFound, errorResponse{Error: "the player does not exist"})
+		return
+	}
+
+	challenge, err := h.manager.Create(challenges.Settings{
+		ChallengerId:         identity.Subject,
+		ChallengerName:       identity.Name,
+		ChallengerHasAccount: identity.HasAccount(),
+		DestinationId:        destination.Id(),
+		DestinationName:      destination.Username(),
+		Color:                constants.ColorFromString(request.Color),
+		StartingPieces:       startingPieces,
+		StartingColor:        constants.ColorFromString(request.StartingColor),
+		TimeControl:          timeControl,
+		Rated:                request.Rated,
+	})
+
+	switch err {
+	case nil:
+		writeJson(w, http.StatusCreated, challengeAsResponse(challenge))
+	case challenges.ErrRatedNeedsAccounts:
+		writeJson(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+	case challenges.ErrTooManyChallenges:
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+	default:
+		writeJson(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+}
+
+// getChallenges lists the pending challenges the caller sent or received.
+func (h *ChallengeHandler) getChallenges(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	response := getChallengesResponse{
+		Challenges: []challengeResponse{},
+	}
+
+	for _, challenge := range h.manager.GetChallengesFor(identity.Subject) {
+		response.Challenges = append(response.Challenges, challengeAsResponse(challenge))
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func (h *ChallengeHandler) accept(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	h.answer(w, r, params, h.manager.Accept)
+}
+
+func (h *ChallengeHandler) decline(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	h.answer(w, r, params, h.manager.Decline)
+}
+
+func (h *ChallengeHandler) cancel(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	h.answer(w, r, params, h.manager.Cancel)
+}
+
+func (h *ChallengeHandler) answer(w http.ResponseWriter, r *http.Request, params routing.Params,
+	resolve func(id challenges.Id, playerId string) (*challenges.Challenge, error)) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	challenge, err := resolve(challenges.Id(params.String("challengeId")), identity.Subject)
+
+	switch err {
+	case nil:
+		writeJson(w, http.StatusOK, challengeAsResponse(challenge))
+	case challenges.ErrChallengeNotFound:
+		writeJson(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+	default:
+		writeJson(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+	}
+}
+
+func isColor(color string, allowRandom bool) bool {
+	return color == "white" || color == "black" || allowRandom && color == "randomColor"
+}
+
+func challengeAsResponse(challenge *challenges.Challenge) challengeResponse {
+	response := challengeResponse{
+		Id:          string(challenge.Id()),
+		Challenger:  tournamentPlayerResponse{Id: challenge.ChallengerId(), Name: challenge.ChallengerName()},
+		Destination: tournamentPlayerResponse{Id: challenge.DestinationId(), Name: challenge.DestinationName()},
+		Color:       constants.ColorAsString(challenge.Color()),
+		Variant:     constants.VariantAsString(challenge.Variant()),
+		TimeControl: dtos.TimeControlAsResponse(challenge.TimeControl()),
+		Rated:       challenge.IsRated(),
+		Status:      constants.ChallengeStatusAsString(challenge.Status()),
+		CreatedAt:   challenge.CreateTime(),
+		ExpiresAt:   challenge.ExpireTime(),
+	}
+
+	if challenge.GameId() != "" {
+		gameId := string(challenge.GameId())
+		response.GameId = &gameId
+	}
+
+	return response
+}
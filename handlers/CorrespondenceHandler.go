@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/routing"
+	"net/http"
+	"time"
+)
+
+type CorrespondenceHandler struct {
+	manager *game.Manager
+}
+
+func NewCorrespondenceHandler(manager *game.Manager) *CorrespondenceHandler {
+	return &CorrespondenceHandler{manager: manager}
+}
+
+func (h *CorrespondenceHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodGet, "/api/correspondence/turns", h.getTurns)
+	router.Handle(http.MethodPost, "/api/correspondence/vacation", h.startVacation)
+	router.Handle(http.MethodDelete, "/api/correspondence/vacation", h.endVacation)
+}
+
+type turnResponse struct {
+	GameId       string     `json:"gameId"`
+	PlayerColor  string     `json:"playerColor"`
+	OpponentName string     `json:"opponentName"`
+	Moves        int        `json:"moves"`
+	Deadline     *time.Time `json:"deadline"`            // null while the caller is on vacation
+	VacationLeft int        `json:"vacationLeftSeconds"` // the vacation the caller has not taken yet
+}
+
+type getTurnsResponse struct {
+	Games []turnResponse `json:"games"`
+}
+
+type vacationResponse struct {
+	GameIds []string `json:"gameIds"` // the games in which the vacation started or ended
+}
+
+// getTurns lists the correspondence games in which it is the turn of the caller, the most urgent first.
+func (h *CorrespondenceHandler) getTurns(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	response := getTurnsResponse{
+		Games: []turnResponse{},
+	}
+
+	for _, g := range h.manager.GetTurns(identity.Subject) {
+		player := g.GetPlayerByToken(identity.Subject)
+
+		turn := turnResponse{
+			GameId:       string(g.Id()),
+			PlayerColor:  constants.ColorAsString(player.Color()),
+			OpponentName: g.OpponentName(player.Color()),
+			Moves:        len(g.History()),
+			VacationLeft: int(g.VacationLeft(player.Color()) / time.Second),
+		}
+
+		if deadline, ok := g.Deadline(); ok {
+			turn.Deadline = &deadline
+		}
+
+		response.Games = append(response.Games, turn)
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+// startVacation pauses the deadlines of the caller in all of their running correspondence games.
+func (h *CorrespondenceHandler) startVacation(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	writeJson(w, http.StatusOK, vacationAsResponse(h.manager.StartVacation(identity.Subject)))
+}
+
+func (h *CorrespondenceHandler) endVacation(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	writeJson(w, http.StatusOK, vacationAsResponse(h.manager.EndVacation(identity.Subject)))
+}
+
+func vacationAsResponse(playerGames []game.PlayerGame) vacationResponse {
+	response := vacationResponse{
+		GameIds: []string{},
+	}
+
+	for _, playerGame := range playerGames {
+		response.GameIds = append(response.GameIds, string(playerGame.Id()))
+	}
+
+	return response
+}
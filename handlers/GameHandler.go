@@ -2,93 +2,60 @@ package handlers
 
 import (
 	"encoding/json"
+	"github.com/racccoooon/chess-be/auth"
 	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/engines"
 	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/routing"
 	"net/http"
-	"regexp"
 	"strconv"
+	"time"
 )
 
 type GameHandler struct {
 	manager *game.Manager
+	bots    *engines.Bots
+	issuer  *auth.Issuer
 }
 
-func NewGameHandler(manager *game.Manager) *GameHandler {
-	return &GameHandler{manager: manager}
+func NewGameHandler(manager *game.Manager, bots *engines.Bots, issuer *auth.Issuer) *GameHandler {
+	return &GameHandler{manager: manager, bots: bots, issuer: issuer}
 }
 
-func (h *GameHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch {
-	case r.Method == http.MethodPost && r.URL.Path == "/api/games/":
-		h.newGame(w, r)
-		return
-	case r.Method == http.MethodGet && r.URL.Path == "/api/games":
-		h.getGames(w, r)
-		return
-	}
-
-	var token string
-	var gameId string
-	var fromX int
-	var fromY int
-
-	// read token from header
-	if tokenHeader := r.Header.Get("Authorization"); tokenHeader != "" {
-		if len(tokenHeader) < 7 || tokenHeader[:7] != "Bearer " {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		// token head is "Bearer " + token
-		token = tokenHeader[7:]
-	}
-
-	switch {
-	case r.Method == http.MethodGet && match(r.URL.Path, "^/api/games/([a-zA-Z0-9-]+)/validmoves/([0-7])/([0-7])$", &gameId, &fromX, &fromY):
-		h.getValidMoves(w, r, token, game.Id(gameId), fromX, fromY)
-		return
-	}
-
-	w.WriteHeader(http.StatusNotFound)
+func (h *GameHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/games", h.newGame)
+	router.Handle(http.MethodGet, "/api/games", h.getGames)
+	router.Handle(http.MethodGet, "/api/games/{gameId}", h.getGame)
+	router.Handle(http.MethodPost, "/api/games/{gameId}/join", h.joinGame)
+	router.Handle(http.MethodPost, "/api/games/{gameId}/invites", h.newInvite)
+	router.Handle(http.MethodPost, "/api/games/{gameId}/moves", h.move)
+	router.Handle(http.MethodPost, "/api/games/{gameId}/resign", h.resign)
+	router.Handle(http.MethodPost, "/api/games/{gameId}/berserk", h.berserk)
+	router.Handle(http.MethodPost, "/api/games/{gameId}/draw", h.draw)
+	router.Handle(http.MethodGet, "/api/games/{gameId}/history", h.getHistory)
+	router.Handle(http.MethodGet, "/api/games/{gameId}/validmoves/{fromX:int}/{fromY:int}", h.getValidMoves)
 }
 
-var cachedRegex = map[string]*regexp.Regexp{}
-
-func mustCompileCached(pattern string) *regexp.Regexp {
-	if regex, ok := cachedRegex[pattern]; ok {
-		return regex
+// readPlayerToken returns the token that identifies the caller as a player, which is the subject of the signed
+// token verified by the AuthMiddleware. A request without a token has an empty player token,
+// a request with an unsigned, malformed or expired token is answered with 401.
+func readPlayerToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok {
+		return identity.Subject, true
 	}
-	regex := regexp.MustCompile(pattern)
-	cachedRegex[pattern] = regex
-	return regex
-}
-
-func match(path, pattern string, routeParams ...interface{}) bool {
-	regex := mustCompileCached(pattern)
-	matches := regex.FindStringSubmatch(path)
 
-	if len(matches) <= 0 {
-		return false
+	if err := auth.TokenErrorFromContext(r.Context()); err != nil {
+		writeJson(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
+		return "", false
 	}
 
-	for i, matchValue := range matches[1:] {
-		switch param := routeParams[i].(type) {
-		case *string:
-			*param = matchValue
-
-		case *int:
-			numberValue, err := strconv.Atoi(matchValue)
-			if err != nil {
-				return false
-			}
-			*param = numberValue
-
-		default:
-			panic("routeParams must be *string or *int")
-		}
+	if r.Header.Get("Authorization") != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return "", false
 	}
 
-	return true
+	return "", true
 }
 
 type newGameRequest struct {
@@ -96,6 +63,46 @@ type newGameRequest struct {
 	StartingPieces []StartingPiece `json:"startingPieces"`
 	StartingColor  string          `json:"startingColor"`
 	IsPublic       bool            `json:"isPublic"`
+	Private        bool            `json:"private"`     // private games can only be joined and watched with an invite or the password
+	Password       string          `json:"password"`    // optional for private games
+	TimeControl    *timeControlDto `json:"timeControl"` // null for games without clocks
+	Rated          bool            `json:"rated"`
+	Bot            *botDto         `json:"bot"`            // null for games between two players
+	Spectators     string          `json:"spectators"`     // allowed, disallowed or afterEnd, allowed if empty
+	BroadcastDelay *delayDto       `json:"broadcastDelay"` // null to show the game to spectators right away
+}
+
+// delayDto holds back each move from the spectators until the given number of moves were played after it
+// and the given number of seconds passed.
+type delayDto struct {
+	Moves   int `json:"moves"`
+	Seconds int `json:"seconds"`
+}
+
+// botDto configures the engine that takes the other seat. The depth and move time are optional.
+type botDto struct {
+	Skill      int `json:"skill"`
+	Depth      int `json:"depth"`
+	MoveTimeMs int `json:"moveTimeMs"`
+}
+
+const (
+	maxBotDepth    = 30
+	maxBotMoveTime = 60 * time.Second
+
+	maxDelayMoves    = 20
+	maxDelayDuration = time.Hour
+
+	maxDaysPerMove  = 14
+	maxVacationDays = 30
+)
+
+// timeControlDto describes either a clock or, with daysPerMove, a correspondence game.
+type timeControlDto struct {
+	InitialSeconds   int `json:"initialSeconds"`
+	IncrementSeconds int `json:"incrementSeconds"`
+	DaysPerMove      int `json:"daysPerMove"`
+	VacationDays     int `json:"vacationDays"` // how long each player of a correspondence game may pause their deadlines
 }
 
 type StartingPiece struct {
@@ -105,11 +112,28 @@ type StartingPiece struct {
 	Color string `json:"color"`
 }
 
+const errRatedGameNeedsAccount = "rated games can only be played with an account"
+
+const errPrivateGame = "the game is private"
+
+const errSpectatorsNotAllowed = "the game can not be watched"
+
+// inviteLifetime is how long invites to private games can be used.
+const inviteLifetime = 7 * 24 * time.Hour
+
 type newGameResponse struct {
-	GameId string `json:"gameId"`
+	GameId          string          `json:"gameId"`
+	SeatInvite      *inviteResponse `json:"seatInvite"` // null for games that are not private
+	SpectatorInvite *inviteResponse `json:"spectatorInvite"`
+}
+
+type inviteResponse struct {
+	Invite    string    `json:"invite"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
-func (h *GameHandler) newGame(w http.ResponseWriter, r *http.Request) {
+func (h *GameHandler) newGame(w http.ResponseWriter, r *http.Request, params routing.Params) {
 	var request newGameRequest
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
@@ -117,8 +141,132 @@ func (h *GameHandler) newGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startingPieces := make([]game.Piece, len(request.StartingPieces))
-	for i, startingPiece := range request.StartingPieces {
+	if !isColor(request.Color, true) || !isColor(request.StartingColor, false) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	startingPieces, ok := startingPiecesFromRequest(request.StartingPieces)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// games from a custom position can not be rated
+	if request.Rated && len(startingPieces) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if request.Private && request.IsPublic || request.Password != "" && !request.Private {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timeControl, ok := timeControlFromRequest(request.TimeControl)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	spectatorPolicy := constants.SpectatorsAllowed
+	if request.Spectators != "" {
+		spectatorPolicy, ok = constants.SpectatorPolicyFromString(request.Spectators)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	var broadcastDelay game.BroadcastDelay
+	if request.BroadcastDelay != nil {
+		broadcastDelay = game.BroadcastDelay{
+			Moves:    request.BroadcastDelay.Moves,
+			Duration: time.Duration(request.BroadcastDelay.Seconds) * time.Second,
+		}
+
+		if broadcastDelay.Moves < 0 || broadcastDelay.Moves > maxDelayMoves ||
+			broadcastDelay.Duration < 0 || broadcastDelay.Duration > maxDelayDuration {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	firstPlayerColor := constants.ColorFromString(request.Color)
+
+	var botLimits engines.Limits
+	if request.Bot != nil {
+		// games against the computer are never rated
+		if request.Rated {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !engines.CanPlay(startingPieces) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		botLimits = engines.Limits{
+			Skill:    request.Bot.Skill,
+			Depth:    request.Bot.Depth,
+			MoveTime: time.Duration(request.Bot.MoveTimeMs) * time.Millisecond,
+		}
+
+		if botLimits.Skill < engines.MinSkill || botLimits.Skill > engines.MaxSkill ||
+			botLimits.Depth < 0 || botLimits.Depth > maxBotDepth ||
+			botLimits.MoveTime < 0 || botLimits.MoveTime > maxBotMoveTime {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// the bot is seated first, so it gets the color the creator did not ask for
+		if firstPlayerColor != constants.RandomColor {
+			firstPlayerColor = constants.GetOppositeColor(firstPlayerColor)
+		}
+	}
+
+	game := h.manager.NewGame(game.Settings{
+		FirstPlayerColor: firstPlayerColor,
+		StartingPieces:   startingPieces,
+		StartingColor:    constants.ColorFromString(request.StartingColor),
+		Public:           request.IsPublic,
+		Private:          request.Private,
+		Password:         request.Password,
+		TimeControl:      timeControl,
+		Rated:            request.Rated,
+		SpectatorPolicy:  spectatorPolicy,
+		BroadcastDelay:   broadcastDelay,
+	})
+
+	if request.Bot != nil {
+		h.bots.Seat(game, botLimits)
+	}
+
+	response := newGameResponse{
+		GameId: string(game.Id()),
+	}
+
+	// the creator joins their private game with the seat invite and passes on whichever invite they like
+	if game.IsPrivate() {
+		seatInvite := h.issueInvite(game, constants.SeatInvite)
+		spectatorInvite := h.issueInvite(game, constants.SpectatorInvite)
+		response.SeatInvite = &seatInvite
+		response.SpectatorInvite = &spectatorInvite
+	}
+
+	writeJson(w, http.StatusCreated, response)
+}
+
+// startingPiecesFromRequest returns false if a piece has an unknown color or type or is off the board.
+func startingPiecesFromRequest(request []StartingPiece) ([]game.Piece, bool) {
+	startingPieces := make([]game.Piece, len(request))
+	for i, startingPiece := range request {
+		if !isColor(startingPiece.Color, false) || !isPieceType(startingPiece.Type) ||
+			startingPiece.X < 0 || startingPiece.X > 7 || startingPiece.Y < 0 || startingPiece.Y > 7 {
+			return nil, false
+		}
+
 		startingPieces[i] = game.NewPiece(
 			constants.ColorFromString(startingPiece.Color),
 			constants.TypeFromString(startingPiece.Type),
@@ -126,20 +274,36 @@ func (h *GameHandler) newGame(w http.ResponseWriter, r *http.Request) {
 			startingPiece.Y)
 	}
 
-	game := h.manager.NewGame(constants.ColorFromString(request.Color), startingPieces, constants.ColorFromString(request.StartingColor), request.IsPublic)
+	return startingPieces, true
+}
 
-	response := newGameResponse{
-		GameId: string(game.Id()),
+func isPieceType(pieceType string) bool {
+	return pieceType == "pawn" || pieceType == "king" || constants.IsPromotionType(pieceType)
+}
+
+// timeControlFromRequest returns an unlimited time control for null and false for invalid time controls.
+func timeControlFromRequest(request *timeControlDto) (game.TimeControl, bool) {
+	if request == nil {
+		return game.TimeControl{}, true
 	}
 
-	responseMessage, err := json.Marshal(response)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	if request.DaysPerMove != 0 {
+		if request.InitialSeconds != 0 || request.IncrementSeconds != 0 ||
+			request.DaysPerMove < 0 || request.DaysPerMove > maxDaysPerMove ||
+			request.VacationDays < 0 || request.VacationDays > maxVacationDays {
+			return game.TimeControl{}, false
+		}
+
+		return game.NewCorrespondenceTimeControl(request.DaysPerMove, request.VacationDays), true
+	}
+
+	if request.InitialSeconds <= 0 || request.IncrementSeconds < 0 || request.VacationDays != 0 {
+		return game.TimeControl{}, false
 	}
 
-	w.Write(responseMessage)
-	w.WriteHeader(http.StatusCreated)
+	return game.NewTimeControl(
+		time.Duration(request.InitialSeconds)*time.Second,
+		time.Duration(request.IncrementSeconds)*time.Second), true
 }
 
 type validMovesResponse struct {
@@ -151,7 +315,16 @@ type validMoveResponseItem struct {
 	ToY int `json:"toY"`
 }
 
-func (h *GameHandler) getValidMoves(w http.ResponseWriter, r *http.Request, token string, gameId game.Id, fromX, fromY int) {
+func (h *GameHandler) getValidMoves(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+	fromX := params.Int("fromX")
+	fromY := params.Int("fromY")
+
 	game := h.manager.GetGame(gameId)
 	if game == nil {
 		w.WriteHeader(http.StatusNotFound)
@@ -176,43 +349,536 @@ func (h *GameHandler) getValidMoves(w http.ResponseWriter, r *http.Request, toke
 		}
 	}
 
-	responseMessage, err := json.Marshal(response)
+	writeJson(w, http.StatusOK, response)
+}
+
+type getGamesResponse struct {
+	Games      []dtos.LobbyGame `json:"games"`
+	NextCursor *string          `json:"nextCursor"`
+}
+
+// getGames lists the lobby. Supported query parameters are openSeats=true, variant, timeControl (the category),
+// rated (true or false), sort (newest, oldest or spectators), cursor and limit.
+func (h *GameHandler) getGames(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	query, ok := readLobbyQuery(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	games, nextCursor, err := h.manager.QueryLobby(query)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	w.Write(responseMessage)
+	response := getGamesResponse{
+		Games: make([]dtos.LobbyGame, len(games)),
+	}
+
+	for i, game := range games {
+		response.Games[i] = dtos.GameAsLobbyGame(game)
+	}
+
+	if nextCursor != "" {
+		response.NextCursor = &nextCursor
+	}
+
+	writeJson(w, http.StatusOK, response)
 }
 
-type getGamesResponse struct {
-	Games []getGamesResponseItem `json:"games"`
+func readLobbyQuery(r *http.Request) (game.LobbyQuery, bool) {
+	values := r.URL.Query()
+
+	query := game.LobbyQuery{
+		OpenSeatsOnly: values.Get("openSeats") == "true",
+		Sort:          constants.NewestFirst,
+		Cursor:        values.Get("cursor"),
+	}
+
+	if value := values.Get("variant"); value != "" {
+		variant, ok := constants.VariantFromString(value)
+		if !ok {
+			return query, false
+		}
+		query.Variant = &variant
+	}
+
+	if value := values.Get("timeControl"); value != "" {
+		category, ok := constants.TimeControlCategoryFromString(value)
+		if !ok {
+			return query, false
+		}
+		query.Category = &category
+	}
+
+	if value := values.Get("rated"); value != "" {
+		rated, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, false
+		}
+		query.Rated = &rated
+	}
+
+	if value := values.Get("sort"); value != "" {
+		sort, ok := constants.LobbySortFromString(value)
+		if !ok {
+			return query, false
+		}
+		query.Sort = sort
+	}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return query, false
+		}
+		query.Limit = limit
+	}
+
+	return query, true
 }
 
-type getGamesResponseItem struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+type gameStateResponse struct {
+	Id              string           `json:"id"`
+	Board           []dtos.BoardItem `json:"board"`
+	InitialBoard    []dtos.BoardItem `json:"initialBoard"`
+	Moves           []dtos.MoveItem  `json:"moves"`
+	ActiveColor     string           `json:"activeColor"`
+	PlayerColor     *string          `json:"playerColor"`
+	WhitePlayerName string           `json:"whitePlayerName"`
+	BlackPlayerName string           `json:"blackPlayerName"`
+	StartingColor   string           `json:"startingColor"`
+	Result          string           `json:"result"`
+	Termination     string           `json:"termination"`
+	DrawOfferedBy   *string          `json:"drawOfferedBy"`
+	Variant         string           `json:"variant"`
+	TimeControl     dtos.TimeControl `json:"timeControl"`
+	Clock           *dtos.Clock      `json:"clock"`    // null for games without clocks
+	Deadline        *time.Time       `json:"deadline"` // when the active player of a correspondence game has to move
+	Rated           bool             `json:"rated"`
+	Private         bool             `json:"private"`
+	Armageddon      bool             `json:"armageddon"`  // a draw counts as a win for black
+	Berserkable     bool             `json:"berserkable"` // players may halve their clock before their first move
+	WhiteBerserk    bool             `json:"whiteBerserk"`
+	BlackBerserk    bool             `json:"blackBerserk"`
+	PreviousGameId  *string          `json:"previousGameId"` // set if the game is a rematch
+	RematchGameId   *string          `json:"rematchGameId"`
+	Spectators      string           `json:"spectators"`
+	BroadcastDelay  *dtos.Delay      `json:"broadcastDelay"`
 }
 
-func (h *GameHandler) getGames(w http.ResponseWriter, r *http.Request) {
-	games := h.manager.GetGames()
+func gameAsGameState(g *game.Game, token string) gameStateResponse {
+	response := gameStateResponse{
+		Id:              string(g.Id()),
+		Board:           dtos.PiecesAsBoardItems(g.Pieces()),
+		InitialBoard:    dtos.PiecesAsBoardItems(g.InitialPieces()),
+		Moves:           dtos.MovesAsMoveItems(g.History()),
+		ActiveColor:     constants.ColorAsString(g.ActiveColor()),
+		WhitePlayerName: g.OpponentName(constants.Black),
+		BlackPlayerName: g.OpponentName(constants.White),
+		StartingColor:   constants.ColorAsString(g.StartingColor()),
+		Result:          constants.ResultAsString(g.Result()),
+		Termination:     constants.TerminationAsString(g.Termination()),
+		Variant:         constants.VariantAsString(g.Variant()),
+		TimeControl:     dtos.TimeControlAsResponse(g.TimeControl()),
+		Rated:           g.IsRated(),
+		Private:         g.IsPrivate(),
+		Armageddon:      g.IsArmageddon(),
+		Berserkable:     g.AllowsBerserk(),
+		WhiteBerserk:    g.IsBerserk(constants.White),
+		BlackBerserk:    g.IsBerserk(constants.Black),
+		Spectators:      constants.SpectatorPolicyAsString(g.SpectatorPolicy()),
+	}
 
-	response := getGamesResponse{
-		Games: make([]getGamesResponseItem, len(games)),
+	if !g.TimeControl().IsUnlimited() {
+		clock := dtos.GameAsClock(g)
+		response.Clock = &clock
 	}
 
-	for i, game := range games {
-		response.Games[i] = getGamesResponseItem{
-			Id:   string(game.Id()),
-			Name: game.Name(),
+	if deadline, ok := g.Deadline(); ok {
+		response.Deadline = &deadline
+	}
+
+	if player := g.GetPlayerByToken(token); token != "" && player != nil {
+		playerColor := constants.ColorAsString(player.Color())
+		response.PlayerColor = &playerColor
+	}
+
+	if color, ok := g.DrawOfferedBy(); ok {
+		drawOfferedBy := constants.ColorAsString(color)
+		response.DrawOfferedBy = &drawOfferedBy
+	}
+
+	if g.PreviousGameId() != "" {
+		previousGameId := string(g.PreviousGameId())
+		response.PreviousGameId = &previousGameId
+	}
+
+	if g.RematchGameId() != "" {
+		rematchGameId := string(g.RematchGameId())
+		response.RematchGameId = &rematchGameId
+	}
+
+	response.BroadcastDelay = dtos.DelayAsResponse(g.BroadcastDelay())
+
+	return response
+}
+
+func (h *GameHandler) getGame(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	game := h.manager.GetGame(gameId)
+	if game == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	plies, ok := visiblePlies(w, r, game, token)
+	if !ok {
+		return
+	}
+
+	response := gameAsGameState(game, token)
+
+	// spectators of a game with a broadcast delay see the position they are allowed to see
+	if plies < len(game.History()) {
+		pieces, activeColor := game.PositionAfter(plies)
+		response.Board = dtos.PiecesAsBoardItems(pieces)
+		response.Moves = dtos.MovesAsMoveItems(game.History()[:plies])
+		response.ActiveColor = constants.ColorAsString(activeColor)
+		response.Clock = nil
+		response.Deadline = nil
+		response.DrawOfferedBy = nil
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+type joinGameRequest struct {
+	Password string `json:"password"` // private games need the password or a seat invite
+	Invite   string `json:"invite"`
+}
+
+func (h *GameHandler) joinGame(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	if token == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request joinGameRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
 		}
 	}
 
-	responseMessage, err := json.Marshal(response)
+	game := h.manager.GetGame(gameId)
+	if game == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if game.GetPlayerByToken(token) == nil {
+		if game.PlayerCount() == 2 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		if !game.Admits(constants.SeatInvite, request.Password, request.Invite) {
+			writeJson(w, http.StatusForbidden, errorResponse{Error: errPrivateGame})
+			return
+		}
+
+		// a non-empty player token always comes from a verified identity
+		identity, _ := auth.IdentityFromContext(r.Context())
+
+		if game.IsRated() && !identity.HasAccount() {
+			writeJson(w, http.StatusForbidden, errorResponse{Error: errRatedGameNeedsAccount})
+			return
+		}
+
+		if _, err := game.AddPlayer(identity.Name, token, ""); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	}
+
+	writeJson(w, http.StatusOK, gameAsGameState(game, token))
+}
+
+type moveRequest struct {
+	From          dtos.Position `json:"from"`
+	To            dtos.Position `json:"to"`
+	PromoteToType *string       `json:"promoteToType"`
+}
+
+type invalidMoveResponse struct {
+	Reason string `json:"reason"`
+}
+
+func (h *GameHandler) move(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	var request moveRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, gameId)
+	if player == nil {
+		return
+	}
+
+	playMove(w, game, player, request.From.X, request.From.Y, request.To.X, request.To.Y, request.PromoteToType)
+}
+
+// playMove plays the move for the player and answers with the move that was played or the reason it was rejected.
+func playMove(w http.ResponseWriter, game *game.Game, player *game.Player, fromX int, fromY int, toX int, toY int, promoteToType *string) {
+	move, reason := game.Move(player.Color(), fromX, fromY, toX, toY, promoteToType)
+	if move == nil {
+		writeJson(w, http.StatusUnprocessableEntity, invalidMoveResponse{
+			Reason: constants.MoveErrorAsString(reason),
+		})
+		return
+	}
+
+	writeJson(w, http.StatusCreated, dtos.MoveAsMoveItem(*move))
+}
+
+func (h *GameHandler) resign(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	game, player := getGameAndPlayer(w, h.manager, token, gameId)
+	if player == nil {
+		return
+	}
+
+	if !game.Resign(player.Color()) {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	writeJson(w, http.StatusOK, gameAsGameState(game, token))
+}
+
+// berserk halves the clock of the player in an arena game, which is only possible before their first move.
+func (h *GameHandler) berserk(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	game, player := getGameAndPlayer(w, h.manager, token, gameId)
+	if player == nil {
+		return
+	}
+
+	if !game.Berserk(player.Color()) {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	writeJson(w, http.StatusOK, gameAsGameState(game, token))
+}
+
+type drawRequest struct {
+	Action string `json:"action"` // offer, accept or decline
+}
+
+func (h *GameHandler) draw(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	var request drawRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, gameId)
+	if player == nil {
+		return
+	}
+
+	var succeeded bool
+	switch request.Action {
+	case "offer":
+		succeeded = game.OfferDraw(player.Color())
+	case "accept":
+		succeeded = game.AcceptDraw(player.Color())
+	case "decline":
+		succeeded = game.DeclineDraw(player.Color())
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !succeeded {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	writeJson(w, http.StatusOK, gameAsGameState(game, token))
+}
+
+type historyResponse struct {
+	Moves []dtos.MoveItem `json:"moves"`
+}
+
+func (h *GameHandler) getHistory(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	gameId := game.Id(params.String("gameId"))
+
+	game := h.manager.GetGame(gameId)
+	if game == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	plies, ok := visiblePlies(w, r, game, token)
+	if !ok {
+		return
+	}
+
+	writeJson(w, http.StatusOK, historyResponse{
+		Moves: dtos.MovesAsMoveItems(game.History()[:plies]),
+	})
+}
+
+type newInviteRequest struct {
+	Role string `json:"role"` // seat or spectator
+}
+
+// newInvite lets a player of a private game invite an opponent or spectators.
+func (h *GameHandler) newInvite(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return
+	}
+
+	var request newInviteRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	role, ok := constants.InviteRoleFromString(request.Role)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	game, player := getGameAndPlayer(w, h.manager, token, game.Id(params.String("gameId")))
+	if player == nil {
+		return
+	}
+
+	if !game.IsPrivate() {
+		writeJson(w, http.StatusConflict, errorResponse{Error: "only private games need invites"})
 		return
 	}
 
-	w.Write(responseMessage)
+	writeJson(w, http.StatusCreated, h.issueInvite(game, role))
+}
+
+func (h *GameHandler) issueInvite(g *game.Game, role int) inviteResponse {
+	invite, expiresAt := h.issuer.IssueInvite(string(g.Id()), role, inviteLifetime)
+
+	return inviteResponse{
+		Invite:    invite,
+		Role:      constants.InviteRoleAsString(role),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// mayWatch tells whether the caller may see the game. Private games can be seen by their players and by whoever
+// passes an invite for the game in the invite query parameter.
+func mayWatch(r *http.Request, g *game.Game, token string) bool {
+	if token != "" && g.GetPlayerByToken(token) != nil {
+		return true
+	}
+
+	return g.Admits(constants.SpectatorInvite, "", r.URL.Query().Get("invite"))
+}
+
+// visiblePlies returns how many moves of the game the caller may see and writes the error response if they
+// may not see the game at all. Players see every move, spectators depend on the policy and delay of the game.
+// Every endpoint that shows the moves of a game checks it.
+func visiblePlies(w http.ResponseWriter, r *http.Request, g *game.Game, token string) (int, bool) {
+	if !mayWatch(r, g, token) {
+		writeJson(w, http.StatusForbidden, errorResponse{Error: errPrivateGame})
+		return 0, false
+	}
+
+	if token != "" && g.GetPlayerByToken(token) != nil {
+		return len(g.History()), true
+	}
+
+	if !g.AdmitsSpectators() {
+		writeJson(w, http.StatusForbidden, errorResponse{Error: errSpectatorsNotAllowed})
+		return 0, false
+	}
+
+	return g.SpectatorPlies(), true
+}
+
+// getGameAndPlayer looks up the game and the player owning the token and writes the error response if either is missing.
+func getGameAndPlayer(w http.ResponseWriter, manager *game.Manager, token string, gameId game.Id) (*game.Game, *game.Player) {
+	game := manager.GetGame(gameId)
+	if game == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil
+	}
+
+	if token == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return game, nil
+	}
+
+	player := game.GetPlayerByToken(token)
+	if player == nil {
+		w.WriteHeader(http.StatusForbidden)
+		return game, nil
+	}
+
+	return game, player
 }
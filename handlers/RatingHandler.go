@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/routing"
+	"math"
+	"net/http"
+	"time"
+)
+
+type RatingHandler struct {
+	store    *ratings.Store
+	accounts *accounts.Store
+}
+
+func NewRatingHandler(store *ratings.Store, accounts *accounts.Store) *RatingHandler {
+	return &RatingHandler{store: store, accounts: accounts}
+}
+
+func (h *RatingHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodGet, "/api/players/{playerId}/ratings", h.getRatings)
+}
+
+type playerRatingsResponse struct {
+	PlayerId string                   `json:"playerId"`
+	Username string                   `json:"username"`
+	Ratings  []categoryRatingResponse `json:"ratings"`
+}
+
+type categoryRatingResponse struct {
+	Category    string                  `json:"category"`
+	Rating      int                     `json:"rating"`
+	Deviation   int                     `json:"deviation"`
+	Volatility  float64                 `json:"volatility"`
+	Provisional bool                    `json:"provisional"`
+	Games       int                     `json:"games"`
+	History     []ratingHistoryResponse `json:"history"`
+}
+
+type ratingHistoryResponse struct {
+	GameId    string    `json:"gameId"`
+	Rating    int       `json:"rating"`
+	Deviation int       `json:"deviation"`
+	Time      time.Time `json:"time"`
+}
+
+// getRatings lists the ratings of an account in every time control category it has played rated games in.
+func (h *RatingHandler) getRatings(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	account := h.accounts.GetAccount(params.String("playerId"))
+	if account == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	response := playerRatingsResponse{
+		PlayerId: account.Id(),
+		Username: account.Username(),
+		Ratings:  []categoryRatingResponse{},
+	}
+
+	for _, categoryRating := range h.store.GetPlayerRatings(account.Id()) {
+		rating := categoryRating.Rating()
+
+		item := categoryRatingResponse{
+			Category:    constants.TimeControlCategoryAsString(categoryRating.Category()),
+			Rating:      int(math.Round(rating.Rating())),
+			Deviation:   int(math.Round(rating.Deviation())),
+			Volatility:  rating.Volatility(),
+			Provisional: rating.IsProvisional(),
+			Games:       categoryRating.Games(),
+			History:     make([]ratingHistoryResponse, len(categoryRating.History())),
+		}
+
+		for i, entry := range categoryRating.History() {
+			item.History[i] = ratingHistoryResponse{
+				GameId:    string(entry.GameId()),
+				Rating:    int(math.Round(entry.Rating())),
+				Deviation: int(math.Round(entry.Deviation())),
+				Time:      entry.Time(),
+			}
+		}
+
+		response.Ratings = append(response.Ratings, item)
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
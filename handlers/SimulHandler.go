@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/routing"
+	"github.com/racccoooon/chess-be/simuls"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type SimulHandler struct {
+	manager *simuls.Manager
+}
+
+func NewSimulHandler(manager *simuls.Manager) *SimulHandler {
+	return &SimulHandler{manager: manager}
+}
+
+func (h *SimulHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/simuls", h.newSimul)
+	router.Handle(http.MethodGet, "/api/simuls", h.getSimuls)
+	router.Handle(http.MethodGet, "/api/simuls/{simulId}", h.getSimul)
+	router.Handle(http.MethodPost, "/api/simuls/{simulId}/join", h.join)
+	router.Handle(http.MethodPost, "/api/simuls/{simulId}/withdraw", h.withdraw)
+	router.Handle(http.MethodPost, "/api/simuls/{simulId}/start", h.start)
+}
+
+type newSimulRequest struct {
+	Name        string          `json:"name"`
+	HostColor   string          `json:"hostColor"`   // white or black
+	TimeControl *timeControlDto `json:"timeControl"` // null for games without clocks
+	MaxPlayers  int             `json:"maxPlayers"`  // 0 for no limit
+}
+
+type newSimulResponse struct {
+	SimulId string `json:"simulId"`
+}
+
+func (h *SimulHandler) newSimul(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	var request newSimulRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(request.Name) == "" || request.MaxPlayers < 0 ||
+		request.HostColor != "white" && request.HostColor != "black" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var timeControl game.TimeControl
+	if request.TimeControl != nil {
+		if request.TimeControl.InitialSeconds <= 0 || request.TimeControl.IncrementSeconds < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		timeControl = game.NewTimeControl(
+			time.Duration(request.TimeControl.InitialSeconds)*time.Second,
+			time.Duration(request.TimeControl.IncrementSeconds)*time.Second)
+	}
+
+	simul := h.manager.NewSimul(simuls.Settings{
+		Name:        strings.TrimSpace(request.Name),
+		HostId:      identity.Subject,
+		HostName:    identity.Name,
+		HostColor:   constants.ColorFromString(request.HostColor),
+		TimeControl: timeControl,
+		MaxPlayers:  request.MaxPlayers,
+	})
+
+	writeJson(w, http.StatusCreated, newSimulResponse{
+		SimulId: string(simul.Id()),
+	})
+}
+
+type getSimulsResponse struct {
+	Simuls []simulResponse `json:"simuls"`
+}
+
+type simulResponse struct {
+	Id           string                     `json:"id"`
+	Name         string                     `json:"name"`
+	Host         tournamentPlayerResponse   `json:"host"`
+	HostColor    string                     `json:"hostColor"`
+	Status       string                     `json:"status"`
+	TimeControl  dtos.TimeControl           `json:"timeControl"`
+	MaxPlayers   int                        `json:"maxPlayers"`
+	CreatedAt    time.Time                  `json:"createdAt"`
+	Participants []tournamentPlayerResponse `json:"participants"`
+	Boards       []simulBoardResponse       `json:"boards"`
+	HostScore    scoreResponse              `json:"hostScore"`
+}
+
+type simulBoardResponse struct {
+	GameId string                   `json:"gameId"`
+	Player tournamentPlayerResponse `json:"player"`
+	Result string                   `json:"result"`
+}
+
+func (h *SimulHandler) getSimuls(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	response := getSimulsResponse{
+		Simuls: []simulResponse{},
+	}
+
+	for _, simul := range h.manager.GetSimuls() {
+		response.Simuls = append(response.Simuls, simulAsResponse(simul))
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func simulAsResponse(simul *simuls.Simul) simulResponse {
+	response := simulResponse{
+		Id:           string(simul.Id()),
+		Name:         simul.Name(),
+		Host:         tournamentPlayerResponse{Id: simul.HostId(), Name: simul.HostName()},
+		HostColor:    constants.ColorAsString(simul.HostColor()),
+		Status:       constants.TournamentStatusAsString(simul.Status()),
+		TimeControl:  dtos.TimeControlAsResponse(simul.TimeControl()),
+		MaxPlayers:   simul.MaxPlayers(),
+		CreatedAt:    simul.CreateTime(),
+		Participants: []tournamentPlayerResponse{},
+		Boards:       []simulBoardResponse{},
+	}
+
+	for _, participant := range simul.Participants() {
+		response.Participants = append(response.Participants,
+			tournamentPlayerResponse{Id: participant.Id(), Name: participant.Name()})
+	}
+
+	for _, board := range simul.Boards() {
+		response.Boards = append(response.Boards, simulBoardResponse{
+			GameId: string(board.GameId()),
+			Player: tournamentPlayerResponse{Id: board.Participant().Id(), Name: board.Participant().Name()},
+			Result: constants.ResultAsString(board.Result()),
+		})
+
+		switch board.Result() {
+		case constants.WinFor(simul.HostColor()):
+			response.HostScore.Wins++
+		case constants.WinFor(constants.GetOppositeColor(simul.HostColor())):
+			response.HostScore.Losses++
+		case constants.Drawn:
+			response.HostScore.Draws++
+		}
+	}
+
+	response.HostScore.Games = response.HostScore.Wins + response.HostScore.Losses + response.HostScore.Draws
+
+	return response
+}
+
+func (h *SimulHandler) getSimul(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	simul := h.manager.GetSimul(simuls.Id(params.String("simulId")))
+	if simul == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, simulAsResponse(simul))
+}
+
+func (h *SimulHandler) join(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	simul := h.manager.GetSimul(simuls.Id(params.String("simulId")))
+	if simul == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := simul.Join(identity.Subject, identity.Name)
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, simulAsResponse(simul))
+}
+
+func (h *SimulHandler) withdraw(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	simul := h.manager.GetSimul(simuls.Id(params.String("simulId")))
+	if simul == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := simul.Withdraw(identity.Subject)
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, simulAsResponse(simul))
+}
+
+func (h *SimulHandler) start(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	simul := h.manager.GetSimul(simuls.Id(params.String("simulId")))
+	if simul == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if simul.HostId() != identity.Subject {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	err := simul.Start()
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, simulAsResponse(simul))
+}
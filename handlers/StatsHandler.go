@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/history"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/routing"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type StatsHandler struct {
+	archive  *history.Archive
+	ratings  *ratings.Store
+	accounts *accounts.Store
+}
+
+func NewStatsHandler(archive *history.Archive, ratings *ratings.Store, accounts *accounts.Store) *StatsHandler {
+	return &StatsHandler{archive: archive, ratings: ratings, accounts: accounts}
+}
+
+func (h *StatsHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodGet, "/api/players/{playerId}/stats", h.getStats)
+	router.Handle(http.MethodGet, "/api/players/{playerId}/games", h.getPlayerGames)
+	router.Handle(http.MethodGet, "/api/leaderboards/{category}", h.getLeaderboard)
+}
+
+type scoreResponse struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+	Games  int `json:"games"`
+}
+
+type openingScoreResponse struct {
+	Eco    string `json:"eco"`
+	Name   string `json:"name"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+	Games  int    `json:"games"`
+}
+
+type playerStatsResponse struct {
+	PlayerId          string                   `json:"playerId"`
+	Username          string                   `json:"username"`
+	Total             scoreResponse            `json:"total"`
+	ByColor           map[string]scoreResponse `json:"byColor"`
+	ByVariant         map[string]scoreResponse `json:"byVariant"`
+	ByTimeControl     map[string]scoreResponse `json:"byTimeControl"`
+	LongestWinStreak  int                      `json:"longestWinStreak"`
+	CurrentWinStreak  int                      `json:"currentWinStreak"`
+	FavouriteOpenings []openingScoreResponse   `json:"favouriteOpenings"`
+}
+
+func (h *StatsHandler) getStats(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	account := h.accounts.GetAccount(params.String("playerId"))
+	if account == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	stats := h.archive.GetPlayerStats(account.Id())
+
+	response := playerStatsResponse{
+		PlayerId:          account.Id(),
+		Username:          account.Username(),
+		Total:             scoreAsResponse(stats.Total),
+		ByColor:           make(map[string]scoreResponse),
+		ByVariant:         make(map[string]scoreResponse),
+		ByTimeControl:     make(map[string]scoreResponse),
+		LongestWinStreak:  stats.LongestWinStreak,
+		CurrentWinStreak:  stats.CurrentWinStreak,
+		FavouriteOpenings: []openingScoreResponse{},
+	}
+
+	for _, color := range []int{constants.White, constants.Black} {
+		response.ByColor[constants.ColorAsString(color)] = scoreAsResponse(stats.ByColor[color])
+	}
+
+	for variant, score := range stats.ByVariant {
+		response.ByVariant[constants.VariantAsString(variant)] = scoreAsResponse(score)
+	}
+
+	for category, score := range stats.ByCategory {
+		response.ByTimeControl[constants.TimeControlCategoryAsString(category)] = scoreAsResponse(score)
+	}
+
+	for _, openingScore := range stats.FavouriteOpenings {
+		response.FavouriteOpenings = append(response.FavouriteOpenings, openingScoreResponse{
+			Eco:    openingScore.Opening.Eco,
+			Name:   openingScore.Opening.Name,
+			Wins:   openingScore.Score.Wins,
+			Losses: openingScore.Score.Losses,
+			Draws:  openingScore.Score.Draws,
+			Games:  openingScore.Score.Games(),
+		})
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func scoreAsResponse(score history.Score) scoreResponse {
+	return scoreResponse{
+		Wins:   score.Wins,
+		Losses: score.Losses,
+		Draws:  score.Draws,
+		Games:  score.Games(),
+	}
+}
+
+type playerGamesResponse struct {
+	Games      []archivedGameResponse `json:"games"`
+	NextCursor *string                `json:"nextCursor"`
+}
+
+type archivedGameResponse struct {
+	GameId      string                 `json:"gameId"`
+	White       archivedPlayerResponse `json:"white"`
+	Black       archivedPlayerResponse `json:"black"`
+	Result      string                 `json:"result"`
+	Termination string                 `json:"termination"`
+	Variant     string                 `json:"variant"`
+	TimeControl string                 `json:"timeControl"` // the category
+	Rated       bool                   `json:"rated"`
+	Opening     *openingResponse       `json:"opening"` // null for games from a custom position and private games
+	MoveCount   int                    `json:"moveCount"`
+	EndedAt     time.Time              `json:"endedAt"`
+}
+
+type archivedPlayerResponse struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type openingResponse struct {
+	Eco  string `json:"eco"`
+	Name string `json:"name"`
+}
+
+// getPlayerGames lists the finished games of an account, newest first. Supported query parameters are cursor and limit.
+func (h *StatsHandler) getPlayerGames(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	account := h.accounts.GetAccount(params.String("playerId"))
+	if account == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	limit, ok := readLimit(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	records, nextCursor, err := h.archive.GetPlayerGames(account.Id(), r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response := playerGamesResponse{
+		Games: make([]archivedGameResponse, len(records)),
+	}
+
+	for i, record := range records {
+		response.Games[i] = recordAsResponse(record)
+	}
+
+	if nextCursor != "" {
+		response.NextCursor = &nextCursor
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func recordAsResponse(record *history.Record) archivedGameResponse {
+	response := archivedGameResponse{
+		GameId:      string(record.GameId),
+		White:       archivedPlayerResponse{Id: record.White.Id, Name: record.White.Name},
+		Black:       archivedPlayerResponse{Id: record.Black.Id, Name: record.Black.Name},
+		Result:      constants.ResultAsString(record.Result),
+		Termination: constants.TerminationAsString(record.Termination),
+		Variant:     constants.VariantAsString(record.Variant),
+		TimeControl: constants.TimeControlCategoryAsString(record.Category),
+		Rated:       record.Rated,
+		MoveCount:   len(record.Moves),
+		EndedAt:     record.EndTime,
+	}
+
+	// the opening would give away the first moves of a private game
+	if record.Variant == constants.Standard && !record.Private {
+		opening := history.FindOpening(record.Moves)
+		response.Opening = &openingResponse{Eco: opening.Eco, Name: opening.Name}
+	}
+
+	return response
+}
+
+type leaderboardResponse struct {
+	Category   string                    `json:"category"`
+	Players    []leaderboardItemResponse `json:"players"`
+	NextCursor *string                   `json:"nextCursor"`
+}
+
+type leaderboardItemResponse struct {
+	Rank      int    `json:"rank"`
+	PlayerId  string `json:"playerId"`
+	Username  string `json:"username"`
+	Rating    int    `json:"rating"`
+	Deviation int    `json:"deviation"`
+	Games     int    `json:"games"`
+}
+
+// getLeaderboard ranks the accounts with an established rating in the category. Supported query parameters
+// are cursor and limit.
+func (h *StatsHandler) getLeaderboard(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	category, ok := constants.TimeControlCategoryFromString(params.String("category"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	limit, ok := readLimit(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entries, nextCursor, err := h.ratings.GetLeaderboard(category, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response := leaderboardResponse{
+		Category: constants.TimeControlCategoryAsString(category),
+		Players:  []leaderboardItemResponse{},
+	}
+
+	for _, entry := range entries {
+		item := leaderboardItemResponse{
+			Rank:      entry.Rank(),
+			PlayerId:  entry.PlayerId(),
+			Rating:    int(math.Round(entry.Rating().Rating())),
+			Deviation: int(math.Round(entry.Rating().Deviation())),
+			Games:     entry.Games(),
+		}
+
+		if account := h.accounts.GetAccount(entry.PlayerId()); account != nil {
+			item.Username = account.Username()
+		}
+
+		response.Players = append(response.Players, item)
+	}
+
+	if nextCursor != "" {
+		response.NextCursor = &nextCursor
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+// readLimit reads the optional limit query parameter, which has to be positive.
+func readLimit(r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, false
+	}
+
+	return limit, true
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/routing"
+	"github.com/racccoooon/chess-be/tournaments"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type TournamentHandler struct {
+	manager *tournaments.Manager
+	ratings *ratings.Store
+}
+
+func NewTournamentHandler(manager *tournaments.Manager, ratings *ratings.Store) *TournamentHandler {
+	return &TournamentHandler{manager: manager, ratings: ratings}
+}
+
+func (h *TournamentHandler) RegisterRoutes(router *routing.Router) {
+	router.Handle(http.MethodPost, "/api/tournaments", h.newTournament)
+	router.Handle(http.MethodGet, "/api/tournaments", h.getTournaments)
+	router.Handle(http.MethodGet, "/api/tournaments/{tournamentId}", h.getTournament)
+	router.Handle(http.MethodPost, "/api/tournaments/{tournamentId}/join", h.join)
+	router.Handle(http.MethodPost, "/api/tournaments/{tournamentId}/withdraw", h.withdraw)
+	router.Handle(http.MethodPost, "/api/tournaments/{tournamentId}/start", h.start)
+	router.Handle(http.MethodGet, "/api/tournaments/{tournamentId}/rounds/{round:int}", h.getRound)
+}
+
+type newTournamentRequest struct {
+	Name        string          `json:"name"`
+	Format      string          `json:"format"`      // swiss, roundRobin, doubleRoundRobin or knockout
+	Rounds      int             `json:"rounds"`      // only for swiss, the other formats derive it from the player count
+	TimeControl *timeControlDto `json:"timeControl"` // null for games without clocks
+	Rated       bool            `json:"rated"`
+}
+
+type newTournamentResponse struct {
+	TournamentId string `json:"tournamentId"`
+}
+
+// readIdentity returns the verified identity of the caller and answers with 401 if there is none.
+func readIdentity(w http.ResponseWriter, r *http.Request) (auth.Identity, bool) {
+	token, ok := readPlayerToken(w, r)
+	if !ok {
+		return auth.Identity{}, false
+	}
+
+	if token == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return auth.Identity{}, false
+	}
+
+	identity, _ := auth.IdentityFromContext(r.Context())
+
+	return identity, true
+}
+
+func (h *TournamentHandler) newTournament(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	var request newTournamentRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	format, ok := constants.TournamentFormatFromString(request.Format)
+	if !ok || strings.TrimSpace(request.Name) == "" || format == constants.Swiss && request.Rounds < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var timeControl game.TimeControl
+	if request.TimeControl != nil {
+		if request.TimeControl.InitialSeconds <= 0 || request.TimeControl.IncrementSeconds < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		timeControl = game.NewTimeControl(
+			time.Duration(request.TimeControl.InitialSeconds)*time.Second,
+			time.Duration(request.TimeControl.IncrementSeconds)*time.Second)
+	}
+
+	tournament := h.manager.NewTournament(tournaments.Settings{
+		Name:        strings.TrimSpace(request.Name),
+		Format:      format,
+		Rounds:      request.Rounds,
+		TimeControl: timeControl,
+		Rated:       request.Rated,
+		OwnerId:     identity.Subject,
+	})
+
+	writeJson(w, http.StatusCreated, newTournamentResponse{
+		TournamentId: string(tournament.Id()),
+	})
+}
+
+type getTournamentsResponse struct {
+	Tournaments []tournamentSummaryResponse `json:"tournaments"`
+}
+
+type tournamentSummaryResponse struct {
+	Id           string           `json:"id"`
+	Name         string           `json:"name"`
+	Format       string           `json:"format"`
+	Status       string           `json:"status"`
+	Rounds       int              `json:"rounds"`
+	CurrentRound int              `json:"currentRound"`
+	PlayerCount  int              `json:"playerCount"`
+	TimeControl  dtos.TimeControl `json:"timeControl"`
+	Rated        bool             `json:"rated"`
+	CreatedAt    time.Time        `json:"createdAt"`
+}
+
+func (h *TournamentHandler) getTournaments(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	response := getTournamentsResponse{
+		Tournaments: []tournamentSummaryResponse{},
+	}
+
+	for _, tournament := range h.manager.GetTournaments() {
+		response.Tournaments = append(response.Tournaments, tournamentAsSummary(tournament))
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func tournamentAsSummary(tournament *tournaments.Tournament) tournamentSummaryResponse {
+	return tournamentSummaryResponse{
+		Id:           string(tournament.Id()),
+		Name:         tournament.Name(),
+		Format:       constants.TournamentFormatAsString(tournament.Format()),
+		Status:       constants.TournamentStatusAsString(tournament.Status()),
+		Rounds:       tournament.Rounds(),
+		CurrentRound: tournament.CurrentRound(),
+		PlayerCount:  tournament.PlayerCount(),
+		TimeControl:  dtos.TimeControlAsResponse(tournament.TimeControl()),
+		Rated:        tournament.IsRated(),
+		CreatedAt:    tournament.CreateTime(),
+	}
+}
+
+type tournamentResponse struct {
+	tournamentSummaryResponse
+	OwnerId   string              `json:"ownerId"`
+	Standings []standingResponse  `json:"standings"`
+	Pairings  []pairingResponse   `json:"pairings"` // of the current round
+	Bracket   [][]pairingResponse `json:"bracket"`  // all rounds of a knockout, null for other formats
+}
+
+type standingResponse struct {
+	Rank            int     `json:"rank"`
+	PlayerId        string  `json:"playerId"`
+	Name            string  `json:"name"`
+	Rating          int     `json:"rating"`
+	Score           float64 `json:"score"`
+	Buchholz        float64 `json:"buchholz"`
+	SonnebornBerger float64 `json:"sonnebornBerger"`
+	Withdrawn       bool    `json:"withdrawn"`
+}
+
+type pairingResponse struct {
+	Board            int                       `json:"board"`
+	White            tournamentPlayerResponse  `json:"white"`
+	Black            *tournamentPlayerResponse `json:"black"` // null for a bye
+	GameId           *string                   `json:"gameId"`
+	ArmageddonGameId *string                   `json:"armageddonGameId"` // only for a drawn knockout match
+	WinnerId         *string                   `json:"winnerId"`
+	Result           string                    `json:"result"`
+	Forfeit          bool                      `json:"forfeit"`
+}
+
+type tournamentPlayerResponse struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func tournamentAsResponse(tournament *tournaments.Tournament) tournamentResponse {
+	response := tournamentResponse{
+		tournamentSummaryResponse: tournamentAsSummary(tournament),
+		OwnerId:                   tournament.OwnerId(),
+		Standings:                 []standingResponse{},
+		Pairings:                  []pairingResponse{},
+	}
+
+	for _, standing := range tournament.Standings() {
+		response.Standings = append(response.Standings, standingResponse{
+			Rank:            standing.Rank,
+			PlayerId:        standing.PlayerId,
+			Name:            standing.Name,
+			Rating:          standing.Rating,
+			Score:           standing.Score,
+			Buchholz:        standing.Buchholz,
+			SonnebornBerger: standing.SonnebornBerger,
+			Withdrawn:       standing.Withdrawn,
+		})
+	}
+
+	if pairings, ok := tournament.Pairings(tournament.CurrentRound()); ok {
+		response.Pairings = pairingsAsResponse(pairings)
+	}
+
+	if tournament.Format() == constants.Knockout {
+		response.Bracket = [][]pairingResponse{}
+		for round := 1; ; round++ {
+			pairings, ok := tournament.Pairings(round)
+			if !ok {
+				break
+			}
+
+			response.Bracket = append(response.Bracket, pairingsAsResponse(pairings))
+		}
+	}
+
+	return response
+}
+
+func pairingsAsResponse(pairings []tournaments.Pairing) []pairingResponse {
+	response := make([]pairingResponse, len(pairings))
+
+	for i, pairing := range pairings {
+		response[i] = pairingResponse{
+			Board:   pairing.Board(),
+			White:   tournamentPlayerResponse{Id: pairing.White().Id(), Name: pairing.White().Name()},
+			Result:  "bye",
+			Forfeit: pairing.IsForfeit(),
+		}
+
+		if !pairing.IsBye() {
+			response[i].Result = constants.ResultAsString(pairing.Result())
+			response[i].Black = &tournamentPlayerResponse{Id: pairing.Black().Id(), Name: pairing.Black().Name()}
+		}
+
+		if pairing.GameId() != "" {
+			gameId := string(pairing.GameId())
+			response[i].GameId = &gameId
+		}
+
+		if pairing.ArmageddonGameId() != "" {
+			armageddonGameId := string(pairing.ArmageddonGameId())
+			response[i].ArmageddonGameId = &armageddonGameId
+		}
+
+		if pairing.Winner() != nil {
+			winnerId := pairing.Winner().Id()
+			response[i].WinnerId = &winnerId
+		}
+	}
+
+	return response
+}
+
+func (h *TournamentHandler) getTournament(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	tournament := h.manager.GetTournament(tournaments.Id(params.String("tournamentId")))
+	if tournament == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, tournamentAsResponse(tournament))
+}
+
+func (h *TournamentHandler) join(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	tournament := h.manager.GetTournament(tournaments.Id(params.String("tournamentId")))
+	if tournament == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if tournament.IsRated() && !identity.HasAccount() {
+		writeJson(w, http.StatusForbidden, errorResponse{Error: errRatedGameNeedsAccount})
+		return
+	}
+
+	rating := h.ratings.GetRating(identity.Subject, tournament.TimeControl().Category())
+
+	err := tournament.Join(identity.Subject, identity.Name, int(math.Round(rating.Rating())))
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, tournamentAsResponse(tournament))
+}
+
+func (h *TournamentHandler) withdraw(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	tournament := h.manager.GetTournament(tournaments.Id(params.String("tournamentId")))
+	if tournament == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := tournament.Withdraw(identity.Subject)
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, tournamentAsResponse(tournament))
+}
+
+func (h *TournamentHandler) start(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	identity, ok := readIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	tournament := h.manager.GetTournament(tournaments.Id(params.String("tournamentId")))
+	if tournament == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if tournament.OwnerId() != identity.Subject {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	err := tournament.Start()
+	if err != nil {
+		writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJson(w, http.StatusOK, tournamentAsResponse(tournament))
+}
+
+type roundResponse struct {
+	Round    int               `json:"round"`
+	Pairings []pairingResponse `json:"pairings"`
+}
+
+func (h *TournamentHandler) getRound(w http.ResponseWriter, r *http.Request, params routing.Params) {
+	tournament := h.manager.GetTournament(tournaments.Id(params.String("tournamentId")))
+	if tournament == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	round := params.Int("round")
+
+	pairings, ok := tournament.Pairings(round)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, roundResponse{
+		Round:    round,
+		Pairings: pairingsAsResponse(pairings),
+	})
+}
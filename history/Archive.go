@@ -0,0 +1,195 @@
+package history
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// PlayerRecord is a player as they were seated in an archived game.
+type PlayerRecord struct {
+	Id   string
+	Name string
+}
+
+// Record is a finished game. It stays in the archive after the manager removed the game.
+type Record struct {
+	GameId      game.Id
+	White       PlayerRecord
+	Black       PlayerRecord
+	Result      int
+	Termination int
+	Variant     int
+	Category    int
+	Rated       bool
+	Private     bool     // only the players may see the moves
+	Moves       []string // in coordinate notation, e.g. e2e4 or e7e8q
+	EndTime     time.Time
+}
+
+// ColorOf returns the color the player had in the game and whether they played in it at all.
+func (r *Record) ColorOf(playerId string) (int, bool) {
+	switch playerId {
+	case r.White.Id:
+		return constants.White, true
+	case r.Black.Id:
+		return constants.Black, true
+	}
+
+	return 0, false
+}
+
+type Archive struct {
+	byPlayer map[string][]*Record
+	mutex    sync.RWMutex
+}
+
+func NewArchive() *Archive {
+	return &Archive{
+		byPlayer: make(map[string][]*Record),
+	}
+}
+
+// RecordGame stores a game that ended with both players seated. It is meant to be registered as
+// a game ended listener of the manager.
+func (a *Archive) RecordGame(g *game.Game) {
+	white := g.GetPlayerByColor(constants.White)
+	black := g.GetPlayerByColor(constants.Black)
+	if !g.IsOver() || white == nil || black == nil {
+		return
+	}
+
+	record := &Record{
+		GameId:      g.Id(),
+		White:       PlayerRecord{Id: white.Token(), Name: white.Name()},
+		Black:       PlayerRecord{Id: black.Token(), Name: black.Name()},
+		Result:      g.Result(),
+		Termination: g.Termination(),
+		Variant:     g.Variant(),
+		Category:    g.TimeControl().Category(),
+		Rated:       g.IsRated(),
+		Private:     g.IsPrivate(),
+		EndTime:     time.Now(),
+	}
+
+	for _, move := range g.History() {
+		record.Moves = append(record.Moves, MoveAsCoordinates(move))
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.byPlayer[record.White.Id] = append(a.byPlayer[record.White.Id], record)
+	a.byPlayer[record.Black.Id] = append(a.byPlayer[record.Black.Id], record)
+}
+
+// GetPlayerRecords returns all archived games of the player, oldest first.
+func (a *Archive) GetPlayerRecords(playerId string) []*Record {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return append([]*Record(nil), a.byPlayer[playerId]...)
+}
+
+// GetPlayerGames returns one page of the games of the player, newest first, and the cursor of the next page,
+// which is empty on the last page.
+func (a *Archive) GetPlayerGames(playerId string, cursor string, limit int) ([]*Record, string, error) {
+	records := a.GetPlayerRecords(playerId)
+
+	sort.Slice(records, func(i, j int) bool {
+		return isBeforeInHistory(records[i].EndTime.UnixNano(), records[i].GameId, records[j].EndTime.UnixNano(), records[j].GameId)
+	})
+
+	if cursor != "" {
+		key, id, err := decodeHistoryCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		start := len(records)
+		for i, record := range records {
+			if isBeforeInHistory(key, id, record.EndTime.UnixNano(), record.GameId) {
+				start = i
+				break
+			}
+		}
+
+		records = records[start:]
+	}
+
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	if len(records) <= limit {
+		return records, "", nil
+	}
+
+	last := records[limit-1]
+
+	return records[:limit], encodeHistoryCursor(last.EndTime.UnixNano(), last.GameId), nil
+}
+
+// MoveAsCoordinates writes a move in coordinate notation, the start and target square followed by the
+// promotion piece if there is one.
+func MoveAsCoordinates(move game.Move) string {
+	coordinates := SquareName(move.FromX(), move.FromY()) + SquareName(move.ToX(), move.ToY())
+
+	if move.Kind() == constants.Promotion {
+		switch move.Type() {
+		case constants.Queen:
+			coordinates += "q"
+		case constants.Rook:
+			coordinates += "r"
+		case constants.Bishop:
+			coordinates += "b"
+		case constants.Knight:
+			coordinates += "n"
+		}
+	}
+
+	return coordinates
+}
+
+func SquareName(x int, y int) string {
+	return string(rune('a'+x)) + string(rune('1'+y))
+}
+
+func isBeforeInHistory(keyA int64, idA game.Id, keyB int64, idB game.Id) bool {
+	if keyA != keyB {
+		return keyA > keyB
+	}
+
+	return idA < idB
+}
+
+func encodeHistoryCursor(key int64, id game.Id) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", key, id)))
+}
+
+func decodeHistoryCursor(encoded string) (int64, game.Id, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var key int64
+	var id string
+	if _, err := fmt.Sscanf(string(decoded), "%d:%s", &key, &id); err != nil {
+		return 0, "", err
+	}
+
+	return key, game.Id(id), nil
+}
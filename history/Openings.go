@@ -0,0 +1,69 @@
+package history
+
+type Opening struct {
+	Eco   string
+	Name  string
+	moves []string
+}
+
+// openings is a small table of common openings. A game is named after the longest entry its first moves match.
+var openings = []Opening{
+	{"B00", "King's Pawn Opening", []string{"e2e4"}},
+	{"C20", "King's Pawn Game", []string{"e2e4", "e7e5"}},
+	{"C40", "King's Knight Opening", []string{"e2e4", "e7e5", "g1f3"}},
+	{"C44", "King's Pawn Game: Open", []string{"e2e4", "e7e5", "g1f3", "b8c6"}},
+	{"C60", "Ruy Lopez", []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5"}},
+	{"C50", "Italian Game", []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1c4"}},
+	{"C45", "Scotch Game", []string{"e2e4", "e7e5", "g1f3", "b8c6", "d2d4"}},
+	{"C42", "Petrov's Defense", []string{"e2e4", "e7e5", "g1f3", "g8f6"}},
+	{"C41", "Philidor Defense", []string{"e2e4", "e7e5", "g1f3", "d7d6"}},
+	{"C30", "King's Gambit", []string{"e2e4", "e7e5", "f2f4"}},
+	{"C25", "Vienna Game", []string{"e2e4", "e7e5", "b1c3"}},
+	{"B20", "Sicilian Defense", []string{"e2e4", "c7c5"}},
+	{"C00", "French Defense", []string{"e2e4", "e7e6"}},
+	{"B10", "Caro-Kann Defense", []string{"e2e4", "c7c6"}},
+	{"B01", "Scandinavian Defense", []string{"e2e4", "d7d5"}},
+	{"B07", "Pirc Defense", []string{"e2e4", "d7d6"}},
+	{"B02", "Alekhine's Defense", []string{"e2e4", "g8f6"}},
+	{"A40", "Queen's Pawn Opening", []string{"d2d4"}},
+	{"D00", "Queen's Pawn Game", []string{"d2d4", "d7d5"}},
+	{"D06", "Queen's Gambit", []string{"d2d4", "d7d5", "c2c4"}},
+	{"D30", "Queen's Gambit Declined", []string{"d2d4", "d7d5", "c2c4", "e7e6"}},
+	{"D20", "Queen's Gambit Accepted", []string{"d2d4", "d7d5", "c2c4", "d5c4"}},
+	{"D10", "Slav Defense", []string{"d2d4", "d7d5", "c2c4", "c7c6"}},
+	{"D02", "London System", []string{"d2d4", "d7d5", "c1f4"}},
+	{"A45", "Indian Defense", []string{"d2d4", "g8f6"}},
+	{"E60", "King's Indian Defense", []string{"d2d4", "g8f6", "c2c4", "g7g6"}},
+	{"E20", "Nimzo-Indian Defense", []string{"d2d4", "g8f6", "c2c4", "e7e6", "b1c3", "f8b4"}},
+	{"A80", "Dutch Defense", []string{"d2d4", "f7f5"}},
+	{"A10", "English Opening", []string{"c2c4"}},
+	{"A04", "Zukertort Opening", []string{"g1f3"}},
+	{"A00", "Uncommon Opening", []string{}},
+}
+
+// FindOpening returns the opening the moves start with. Games that match no entry are uncommon openings.
+func FindOpening(moves []string) Opening {
+	var found Opening
+
+	for _, opening := range openings {
+		if len(opening.moves) > len(moves) || found.Name != "" && len(opening.moves) <= len(found.moves) {
+			continue
+		}
+
+		if isPrefix(opening.moves, moves) {
+			found = opening
+		}
+	}
+
+	return found
+}
+
+func isPrefix(prefix []string, moves []string) bool {
+	for i := range prefix {
+		if prefix[i] != moves[i] {
+			return false
+		}
+	}
+
+	return true
+}
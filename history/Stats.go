@@ -0,0 +1,112 @@
+package history
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"sort"
+)
+
+// maxFavouriteOpenings is how many of the most played openings are part of the statistics
+const maxFavouriteOpenings = 5
+
+type Score struct {
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+func (s Score) Games() int {
+	return s.Wins + s.Losses + s.Draws
+}
+
+func (s *Score) add(record *Record, color int) {
+	switch record.Result {
+	case constants.WinFor(color):
+		s.Wins++
+	case constants.Drawn:
+		s.Draws++
+	default:
+		s.Losses++
+	}
+}
+
+type OpeningScore struct {
+	Opening Opening
+	Score   Score
+}
+
+type Stats struct {
+	Total             Score
+	ByColor           map[int]Score
+	ByVariant         map[int]Score
+	ByCategory        map[int]Score
+	LongestWinStreak  int
+	CurrentWinStreak  int
+	FavouriteOpenings []OpeningScore
+}
+
+// GetPlayerStats computes the statistics of the player from all archived games.
+func (a *Archive) GetPlayerStats(playerId string) Stats {
+	stats := Stats{
+		ByColor:    make(map[int]Score),
+		ByVariant:  make(map[int]Score),
+		ByCategory: make(map[int]Score),
+	}
+
+	openingScores := make(map[string]*OpeningScore)
+
+	for _, record := range a.GetPlayerRecords(playerId) {
+		color, _ := record.ColorOf(playerId)
+
+		stats.Total.add(record, color)
+		addTo(stats.ByColor, color, record, color)
+		addTo(stats.ByVariant, record.Variant, record, color)
+		addTo(stats.ByCategory, record.Category, record, color)
+
+		if record.Result == constants.WinFor(color) {
+			stats.CurrentWinStreak++
+			if stats.CurrentWinStreak > stats.LongestWinStreak {
+				stats.LongestWinStreak = stats.CurrentWinStreak
+			}
+		} else {
+			stats.CurrentWinStreak = 0
+		}
+
+		// openings only make sense for games from the starting position
+		if record.Variant != constants.Standard {
+			continue
+		}
+
+		opening := FindOpening(record.Moves)
+		openingScore, ok := openingScores[opening.Eco+opening.Name]
+		if !ok {
+			openingScore = &OpeningScore{Opening: opening}
+			openingScores[opening.Eco+opening.Name] = openingScore
+		}
+		openingScore.Score.add(record, color)
+	}
+
+	for _, openingScore := range openingScores {
+		stats.FavouriteOpenings = append(stats.FavouriteOpenings, *openingScore)
+	}
+
+	sort.Slice(stats.FavouriteOpenings, func(i, j int) bool {
+		a, b := stats.FavouriteOpenings[i], stats.FavouriteOpenings[j]
+		if a.Score.Games() != b.Score.Games() {
+			return a.Score.Games() > b.Score.Games()
+		}
+
+		return a.Opening.Eco < b.Opening.Eco
+	})
+
+	if len(stats.FavouriteOpenings) > maxFavouriteOpenings {
+		stats.FavouriteOpenings = stats.FavouriteOpenings[:maxFavouriteOpenings]
+	}
+
+	return stats
+}
+
+func addTo(scores map[int]Score, key int, record *Record, color int) {
+	score := scores[key]
+	score.add(record, color)
+	scores[key] = score
+}
@@ -0,0 +1,157 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/analysis"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+)
+
+type AnalysisRequest struct {
+	GameId   string `json:"gameId"`
+	Token    string `json:"token"`    // players of the game may always see its analysis
+	Password string `json:"password"` // spectators of private games need the password or an invite
+	Invite   string `json:"invite"`
+}
+
+type AnalysisUpdatedResponse struct {
+	GameId        string                   `json:"gameId"`
+	Status        string                   `json:"status"`
+	AnalyzedPlies int                      `json:"analyzedPlies"`
+	TotalPlies    int                      `json:"totalPlies"`
+	LastPly       *AnalysisPlyResponse     `json:"lastPly"`
+	White         *AnalysisSummaryResponse `json:"white"` // null until the analysis is finished
+	Black         *AnalysisSummaryResponse `json:"black"`
+}
+
+type AnalysisPlyResponse struct {
+	Ply            int                `json:"ply"`
+	Color          string             `json:"color"`
+	Move           string             `json:"move"`
+	BestMove       *string            `json:"bestMove"`
+	Evaluation     EvaluationResponse `json:"evaluation"`
+	Loss           int                `json:"loss"`
+	Classification string             `json:"classification"`
+}
+
+type EvaluationResponse struct {
+	Centipawns int  `json:"centipawns"`
+	Mate       *int `json:"mate"`
+}
+
+type AnalysisSummaryResponse struct {
+	AverageCentipawnLoss int `json:"averageCentipawnLoss"`
+	Inaccuracies         int `json:"inaccuracies"`
+	Mistakes             int `json:"mistakes"`
+	Blunders             int `json:"blunders"`
+}
+
+// JoinAnalysis subscribes the caller to the progress of the analysis of a game. The analysis shows every move,
+// so only those who may see every move of the game may join it.
+func (h *GameHub) JoinAnalysis(request AnalysisRequest) {
+	manager := h.Context().Value("analyses").(*analysis.Manager)
+	games := h.Context().Value("manager").(*game.Manager)
+
+	gameAnalysis := manager.GetAnalysis(game.Id(request.GameId))
+	game := games.GetGame(game.Id(request.GameId))
+	if game == nil || gameAnalysis == nil {
+		h.Clients().Caller().Send("analysisNotFound")
+		return
+	}
+
+	if !h.mayAnalyse(game, request) {
+		return
+	}
+
+	h.Groups().AddToGroup("analysis-"+request.GameId, h.ConnectionID())
+	h.Clients().Caller().Send("analysisUpdated", analysisAsUpdatedResponse(gameAnalysis))
+}
+
+// mayAnalyse tells whether the caller may see the analysis of the game and sends the reason if they may not.
+func (h *GameHub) mayAnalyse(g *game.Game, request AnalysisRequest) bool {
+	if _, ok := auth.IdentityFromContext(h.Context()); ok || request.Token != "" {
+		identity, ok := h.identity(request.Token)
+		if !ok {
+			return false
+		}
+
+		if g.GetPlayerByToken(identity.Subject) != nil {
+			return true
+		}
+	}
+
+	if !g.Admits(constants.SpectatorInvite, request.Password, request.Invite) {
+		h.Clients().Caller().Send("gameAccessDenied")
+		return false
+	}
+
+	if !g.AdmitsSpectators() || g.SpectatorPlies() < len(g.History()) {
+		h.Clients().Caller().Send("spectatingNotAllowed")
+		return false
+	}
+
+	return true
+}
+
+func (h *GameHub) LeaveAnalysis(request AnalysisRequest) {
+	h.Groups().RemoveFromGroup("analysis-"+request.GameId, h.ConnectionID())
+}
+
+// registerAnalysisListeners streams the progress of analyses to their group and to the players of the game.
+func registerAnalysisListeners(manager *analysis.Manager, clients signalr.HubClients) {
+	manager.OnAnalysisUpdated(func(gameAnalysis *analysis.Analysis) {
+		response := analysisAsUpdatedResponse(gameAnalysis)
+
+		clients.Group("analysis-"+string(gameAnalysis.GameId())).Send("analysisUpdated", response)
+		clients.Group("game-"+string(gameAnalysis.GameId())).Send("analysisUpdated", response)
+	})
+}
+
+func analysisAsUpdatedResponse(gameAnalysis *analysis.Analysis) AnalysisUpdatedResponse {
+	response := AnalysisUpdatedResponse{
+		GameId:        string(gameAnalysis.GameId()),
+		Status:        constants.AnalysisStatusAsString(gameAnalysis.Status()),
+		AnalyzedPlies: gameAnalysis.AnalyzedPlies(),
+		TotalPlies:    gameAnalysis.TotalPlies(),
+	}
+
+	if ply := gameAnalysis.LastPly(); ply != nil {
+		lastPly := AnalysisPlyResponse{
+			Ply:            ply.Number(),
+			Color:          constants.ColorAsString(ply.Color()),
+			Move:           ply.Move(),
+			Evaluation:     EvaluationResponse{Centipawns: ply.Evaluation().Centipawns()},
+			Loss:           ply.Loss(),
+			Classification: constants.MoveClassificationAsString(ply.Classification()),
+		}
+
+		if ply.BestMove() != "" {
+			bestMove := ply.BestMove()
+			lastPly.BestMove = &bestMove
+		}
+
+		if ply.Evaluation().IsMate() {
+			mate := ply.Evaluation().Mate()
+			lastPly.Evaluation.Mate = &mate
+		}
+
+		response.LastPly = &lastPly
+	}
+
+	if gameAnalysis.Status() == constants.AnalysisFinished {
+		response.White = analysisSummaryAsResponse(gameAnalysis.Summary(constants.White))
+		response.Black = analysisSummaryAsResponse(gameAnalysis.Summary(constants.Black))
+	}
+
+	return response
+}
+
+func analysisSummaryAsResponse(summary analysis.Summary) *AnalysisSummaryResponse {
+	return &AnalysisSummaryResponse{
+		AverageCentipawnLoss: summary.AverageLoss(),
+		Inaccuracies:         summary.Inaccuracies(),
+		Mistakes:             summary.Mistakes(),
+		Blunders:             summary.Blunders(),
+	}
+}
@@ -0,0 +1,110 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/arenas"
+	"github.com/racccoooon/chess-be/constants"
+	"time"
+)
+
+type ArenaRequest struct {
+	ArenaId string `json:"arenaId"`
+}
+
+type ArenaUpdatedResponse struct {
+	ArenaId     string                  `json:"arenaId"`
+	Status      string                  `json:"status"`
+	PlayerCount int                     `json:"playerCount"`
+	StartsAt    time.Time               `json:"startsAt"`
+	EndsAt      time.Time               `json:"endsAt"`
+	Leaderboard []ArenaStandingResponse `json:"leaderboard"`
+}
+
+type ArenaStandingResponse struct {
+	Rank     int                   `json:"rank"`
+	PlayerId string                `json:"playerId"`
+	Name     string                `json:"name"`
+	Rating   int                   `json:"rating"`
+	Score    int                   `json:"score"`
+	Games    int                   `json:"games"`
+	Wins     int                   `json:"wins"`
+	OnFire   bool                  `json:"onFire"`
+	Paused   bool                  `json:"paused"`
+	GameId   *string               `json:"gameId"` // the game the player is playing right now
+	Sheet    []ArenaResultResponse `json:"sheet"`
+}
+
+type ArenaResultResponse struct {
+	Points  int  `json:"points"`
+	Fire    bool `json:"fire"`
+	Berserk bool `json:"berserk"`
+}
+
+// JoinArena subscribes the caller to the arenaUpdated events of an arena and sends them the current leaderboard.
+// Players find their next game in the gameId of their standing.
+func (h *GameHub) JoinArena(request ArenaRequest) {
+	manager := h.Context().Value("arenas").(*arenas.Manager)
+
+	arena := manager.GetArena(arenas.Id(request.ArenaId))
+	if arena == nil {
+		h.Clients().Caller().Send("arenaNotFound")
+		return
+	}
+
+	h.Groups().AddToGroup("arena-"+request.ArenaId, h.ConnectionID())
+	h.Clients().Caller().Send("arenaUpdated", arenaAsUpdatedResponse(arena))
+}
+
+func (h *GameHub) LeaveArena(request ArenaRequest) {
+	h.Groups().RemoveFromGroup("arena-"+request.ArenaId, h.ConnectionID())
+}
+
+// registerArenaListeners sends the leaderboard to the arena group whenever it changes.
+func registerArenaListeners(manager *arenas.Manager, clients signalr.HubClients) {
+	manager.OnArenaUpdated(func(arena *arenas.Arena) {
+		clients.Group("arena-"+string(arena.Id())).Send("arenaUpdated", arenaAsUpdatedResponse(arena))
+	})
+}
+
+func arenaAsUpdatedResponse(arena *arenas.Arena) ArenaUpdatedResponse {
+	response := ArenaUpdatedResponse{
+		ArenaId:     string(arena.Id()),
+		Status:      constants.TournamentStatusAsString(arena.Status()),
+		PlayerCount: arena.PlayerCount(),
+		StartsAt:    arena.StartTime(),
+		EndsAt:      arena.EndTime(),
+		Leaderboard: []ArenaStandingResponse{},
+	}
+
+	for _, standing := range arena.Leaderboard() {
+		item := ArenaStandingResponse{
+			Rank:     standing.Rank,
+			PlayerId: standing.PlayerId,
+			Name:     standing.Name,
+			Rating:   standing.Rating,
+			Score:    standing.Score,
+			Games:    standing.Games,
+			Wins:     standing.Wins,
+			OnFire:   standing.OnFire,
+			Paused:   standing.Paused,
+			Sheet:    []ArenaResultResponse{},
+		}
+
+		if standing.GameId != "" {
+			gameId := string(standing.GameId)
+			item.GameId = &gameId
+		}
+
+		for _, result := range standing.Results {
+			item.Sheet = append(item.Sheet, ArenaResultResponse{
+				Points:  result.Points,
+				Fire:    result.Fire,
+				Berserk: result.Berserk,
+			})
+		}
+
+		response.Leaderboard = append(response.Leaderboard, item)
+	}
+
+	return response
+}
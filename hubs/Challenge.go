@@ -0,0 +1,95 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"time"
+)
+
+type ChallengesRequest struct {
+	Token string `json:"token"`
+}
+
+type ChallengesResponse struct {
+	Challenges []ChallengeResponse `json:"challenges"`
+}
+
+type ChallengeResponse struct {
+	Id          string                   `json:"id"`
+	Challenger  TournamentPlayerResponse `json:"challenger"`
+	Destination TournamentPlayerResponse `json:"destination"`
+	Color       string                   `json:"color"`
+	Variant     string                   `json:"variant"`
+	TimeControl dtos.TimeControl         `json:"timeControl"`
+	Rated       bool                     `json:"rated"`
+	Status      string                   `json:"status"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	ExpiresAt   time.Time                `json:"expiresAt"`
+	GameId      *string                  `json:"gameId"` // set once the challenge was accepted
+}
+
+// JoinChallenges subscribes the caller to the challenges they send and receive. The caller receives the pending
+// challenges right away and challengeUpdated whenever a challenge is created or answered.
+func (h *GameHub) JoinChallenges(request ChallengesRequest) {
+	manager := h.Context().Value("challenges").(*challenges.Manager)
+
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	h.Groups().AddToGroup("player-"+identity.Subject, h.ConnectionID())
+
+	response := ChallengesResponse{
+		Challenges: []ChallengeResponse{},
+	}
+
+	for _, challenge := range manager.GetChallengesFor(identity.Subject) {
+		response.Challenges = append(response.Challenges, challengeAsResponse(challenge))
+	}
+
+	h.Clients().Caller().Send("challenges", response)
+}
+
+func (h *GameHub) LeaveChallenges(request ChallengesRequest) {
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	h.Groups().RemoveFromGroup("player-"+identity.Subject, h.ConnectionID())
+}
+
+// registerChallengeListeners tells both sides of a challenge about every change of it.
+func registerChallengeListeners(manager *challenges.Manager, clients signalr.HubClients) {
+	manager.OnChallengeUpdated(func(challenge *challenges.Challenge) {
+		response := challengeAsResponse(challenge)
+
+		clients.Group("player-"+challenge.ChallengerId()).Send("challengeUpdated", response)
+		clients.Group("player-"+challenge.DestinationId()).Send("challengeUpdated", response)
+	})
+}
+
+func challengeAsResponse(challenge *challenges.Challenge) ChallengeResponse {
+	response := ChallengeResponse{
+		Id:          string(challenge.Id()),
+		Challenger:  TournamentPlayerResponse{Id: challenge.ChallengerId(), Name: challenge.ChallengerName()},
+		Destination: TournamentPlayerResponse{Id: challenge.DestinationId(), Name: challenge.DestinationName()},
+		Color:       constants.ColorAsString(challenge.Color()),
+		Variant:     constants.VariantAsString(challenge.Variant()),
+		TimeControl: dtos.TimeControlAsResponse(challenge.TimeControl()),
+		Rated:       challenge.IsRated(),
+		Status:      constants.ChallengeStatusAsString(challenge.Status()),
+		CreatedAt:   challenge.CreateTime(),
+		ExpiresAt:   challenge.ExpireTime(),
+	}
+
+	if challenge.GameId() != "" {
+		gameId := string(challenge.GameId())
+		response.GameId = &gameId
+	}
+
+	return response
+}
@@ -0,0 +1,160 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"time"
+)
+
+type ChatRequest struct {
+	GameId string `json:"gameId"`
+	Text   string `json:"text"`
+	Room   string `json:"room"`  // players or spectators, players if empty
+	Token  string `json:"token"` // spectators need a token for their name if the connection was not made with one
+}
+
+type ChatMessageResponse struct {
+	Room   string    `json:"room"`
+	Name   string    `json:"name"`
+	Color  string    `json:"color"` // None for messages of spectators
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sentAt"`
+}
+
+type ChatRejectedResponse struct {
+	Reason string `json:"reason"` // invalidMessage or rateLimited
+}
+
+type MuteRequest struct {
+	GameId string `json:"gameId"`
+	Muted  bool   `json:"muted"`
+}
+
+type MuteResponse struct {
+	Muted bool `json:"muted"`
+}
+
+// SendChat writes a message to a chat room of the game. Players write in the player room and receive its messages,
+// spectators write in the spectator room and receive its messages.
+func (h *GameHub) SendChat(request ChatRequest) {
+	room := constants.PlayerRoom
+	if request.Room != "" {
+		var ok bool
+		if room, ok = constants.ChatRoomFromString(request.Room); !ok {
+			h.chatRejected("invalidMessage")
+			return
+		}
+	}
+
+	if room == constants.PlayerRoom {
+		game, player := h.getGameAndPlayer(request.GameId)
+		if game == nil || player == nil {
+			return
+		}
+
+		if _, reason := game.Chat(player, request.Text); reason != constants.ChatAllowed {
+			h.chatRejected(constants.ChatErrorAsString(reason))
+		}
+
+		return
+	}
+
+	manager := h.Context().Value("manager").(*game.Manager)
+
+	game := manager.GetGame(game.Id(request.GameId))
+	if game == nil {
+		h.gameNotFound()
+		return
+	}
+
+	if !game.IsSpectator(h.ConnectionID()) {
+		h.Clients().Caller().Send("spectatorNotFound")
+		return
+	}
+
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	if _, reason := game.SpectatorChat(identity.Subject, identity.Name, request.Text); reason != constants.ChatAllowed {
+		h.chatRejected(constants.ChatErrorAsString(reason))
+	}
+}
+
+// MuteOpponent hides the chat messages of the opponent from the caller, or shows them again.
+func (h *GameHub) MuteOpponent(request MuteRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	game.MuteOpponent(player.Color(), request.Muted)
+
+	h.Clients().Caller().Send("opponentMuted", MuteResponse{Muted: request.Muted})
+}
+
+func (h *GameHub) chatRejected(reason string) {
+	h.Clients().Caller().Send("chatRejected", ChatRejectedResponse{Reason: reason})
+}
+
+// registerChatListeners sends messages of the player room to the players who did not mute the sender
+// and messages of the spectator room to the spectators.
+func registerChatListeners(manager *game.Manager, clients signalr.HubClients) {
+	manager.OnChat(func(game *game.Game, message game.ChatMessage) {
+		response := chatMessageAsResponse(message)
+
+		if message.Room() == constants.SpectatorRoom {
+			clients.Group("spectators-"+string(game.Id())).Send("chat", response)
+			return
+		}
+
+		for _, color := range []int{constants.White, constants.Black} {
+			player := game.GetPlayerByColor(color)
+			if player == nil || player.ConnectionId() == "" {
+				continue
+			}
+
+			if !game.SeesChatMessage(color, message) {
+				continue
+			}
+
+			clients.Client(player.ConnectionId()).Send("chat", response)
+		}
+	})
+}
+
+// playerChatHistory returns the messages of the player room the player of the given color can see, oldest first.
+func playerChatHistory(g *game.Game, color int) []ChatMessageResponse {
+	history := []ChatMessageResponse{}
+	for _, message := range g.PlayerChatMessages(color) {
+		history = append(history, chatMessageAsResponse(message))
+	}
+
+	return history
+}
+
+func spectatorChatHistory(g *game.Game) []ChatMessageResponse {
+	history := []ChatMessageResponse{}
+	for _, message := range g.ChatMessages(constants.SpectatorRoom) {
+		history = append(history, chatMessageAsResponse(message))
+	}
+
+	return history
+}
+
+func chatMessageAsResponse(message game.ChatMessage) ChatMessageResponse {
+	color := "None"
+	if message.Room() == constants.PlayerRoom {
+		color = constants.ColorAsString(message.Color())
+	}
+
+	return ChatMessageResponse{
+		Room:   constants.ChatRoomAsString(message.Room()),
+		Name:   message.Name(),
+		Color:  color,
+		Text:   message.Text(),
+		SentAt: message.SendTime(),
+	}
+}
@@ -4,23 +4,59 @@ import (
 	"context"
 	"github.com/go-kit/log"
 	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/analysis"
+	"github.com/racccoooon/chess-be/arenas"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/challenges"
 	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
 	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/matchmaking"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/simuls"
+	"github.com/racccoooon/chess-be/tournaments"
 	"net/http"
 	"os"
 	"time"
 )
 
+const errRatedGameNeedsAccount = "rated games can only be played with an account"
+
 type GameHub struct {
 	signalr.Hub
 }
 
-func SetupGameHub(manager *game.Manager, router *http.ServeMux) {
-	hub := &GameHub{}
+// Services are the subsystems the hub methods use. They are passed to the hub through the server context.
+type Services struct {
+	Manager     *game.Manager
+	Matchmaking *matchmaking.Queue
+	Issuer      *auth.Issuer
+	Ratings     *ratings.Store
+	Tournaments *tournaments.Manager
+	Arenas      *arenas.Manager
+	Simuls      *simuls.Manager
+	Analyses    *analysis.Manager
+	Challenges  *challenges.Manager
+}
 
-	context := context.WithValue(context.Background(), "manager", manager)
+func SetupGameHub(services Services, router *http.ServeMux) {
+	hub := &GameHub{}
 
-	server, err := signalr.NewServer(context,
+	hubContext := context.WithValue(context.Background(), "manager", services.Manager)
+	hubContext = context.WithValue(hubContext, "matchmaking", services.Matchmaking)
+	hubContext = context.WithValue(hubContext, "issuer", services.Issuer)
+	hubContext = context.WithValue(hubContext, "ratings", services.Ratings)
+	hubContext = context.WithValue(hubContext, "tournaments", services.Tournaments)
+	hubContext = context.WithValue(hubContext, "arenas", services.Arenas)
+	hubContext = context.WithValue(hubContext, "simuls", services.Simuls)
+	hubContext = context.WithValue(hubContext, "analyses", services.Analyses)
+	hubContext = context.WithValue(hubContext, "challenges", services.Challenges)
+
+	// the relay needs the clients of the server, which needs the context, so it is filled in afterwards
+	relay := newSpectatorRelay()
+	hubContext = context.WithValue(hubContext, "relay", relay)
+
+	server, err := signalr.NewServer(hubContext,
 		signalr.SimpleHubFactory(hub),
 		signalr.HTTPTransports("ServerSentEvents"),
 		signalr.KeepAliveInterval(2*time.Second),
@@ -32,51 +68,143 @@ func SetupGameHub(manager *game.Manager, router *http.ServeMux) {
 		panic(err)
 	}
 
+	relay.clients = server.HubClients()
+
+	registerGameListeners(services.Manager, server.HubClients(), relay)
+	registerLobbyListeners(services.Manager, server.HubClients())
+	registerMatchmakingListeners(services.Matchmaking, server.HubClients())
+	registerTournamentListeners(services.Tournaments, server.HubClients())
+	registerArenaListeners(services.Arenas, server.HubClients())
+	registerSimulListeners(services.Simuls, services.Manager, server.HubClients())
+	registerAnalysisListeners(services.Analyses, server.HubClients())
+	registerChallengeListeners(services.Challenges, server.HubClients())
+	registerChatListeners(services.Manager, server.HubClients())
+	registerPresenceListeners(services.Manager, server.HubClients())
+
 	server.MapHTTP(signalr.WithHTTPServeMux(router), "/gameHub")
 }
 
+// registerGameListeners forwards game events to the game and spectator groups, no matter if they
+// were caused by a hub method or by the REST api.
+func registerGameListeners(manager *game.Manager, clients signalr.HubClients, relay *spectatorRelay) {
+	manager.OnMove(func(game *game.Game, move game.Move) {
+		moveItemResponse := dtos.MoveAsMoveItem(move)
+		clients.Group("game-"+string(game.Id())).Send("move", moveItemResponse)
+		relay.Send(game, "move", moveItemResponse)
+	})
+
+	manager.OnPlayerJoined(func(game *game.Game, player *game.Player) {
+		if game.PlayerCount() != 2 {
+			return
+		}
+
+		// a player joining over the hub is added to the group afterwards and receives gameStarted from JoinGame
+		gameStartedResponse := GameStartedResponse{
+			WhitePlayerName: game.OpponentName(constants.Black),
+			BlackPlayerName: game.OpponentName(constants.White),
+		}
+
+		clients.Group("game-"+string(game.Id())).Send("gameStarted", gameStartedResponse)
+		relay.Send(game, "gameStarted", gameStartedResponse)
+	})
+
+	manager.OnDrawOffered(func(game *game.Game, color int) {
+		drawOfferedResponse := DrawOfferedResponse{
+			Color: constants.ColorAsString(color),
+		}
+
+		clients.Group("game-"+string(game.Id())).Send("drawOffered", drawOfferedResponse)
+		relay.Send(game, "drawOffered", drawOfferedResponse)
+	})
+
+	manager.OnBerserk(func(game *game.Game, color int) {
+		berserkResponse := BerserkResponse{
+			Color: constants.ColorAsString(color),
+		}
+
+		clients.Group("game-"+string(game.Id())).Send("berserk", berserkResponse)
+		relay.Send(game, "berserk", berserkResponse)
+
+		clockResponse := dtos.GameAsClock(game)
+		clients.Group("game-"+string(game.Id())).Send("clock", clockResponse)
+		relay.Send(game, "clock", clockResponse)
+	})
+
+	manager.OnMove(func(game *game.Game, move game.Move) {
+		if game.TimeControl().IsUnlimited() {
+			return
+		}
+
+		clockResponse := dtos.GameAsClock(game)
+		clients.Group("game-"+string(game.Id())).Send("clock", clockResponse)
+		relay.Send(game, "clock", clockResponse)
+	})
+
+	manager.OnGameEnded(func(game *game.Game) {
+		gameEndedResponse := GameEndedResponse{
+			Result:      constants.ResultAsString(game.Result()),
+			Termination: constants.TerminationAsString(game.Termination()),
+		}
+
+		clients.Group("game-"+string(game.Id())).Send("gameEnded", gameEndedResponse)
+		relay.Send(game, "gameEnded", gameEndedResponse)
+	})
+
+	manager.OnGameRemoved(relay.Forget)
+}
+
 type JoinGameRequest struct {
-	GameId     string `json:"gameId"`
-	PlayerName string `json:"playerName"`
-	Token      string `json:"token"`
+	GameId   string `json:"gameId"`
+	Token    string `json:"token"`    // only needed if the connection was not made with a token
+	Password string `json:"password"` // private games need the password or an invite to take a seat
+	Invite   string `json:"invite"`
 }
 
 type JoinGameResponse struct {
-	Board           []BoardItemResponse `json:"board"`
-	InitialBoard    []BoardItemResponse `json:"initialBoard"`
-	Moves           []MoveItemResponse  `json:"moves"`
-	ActiveColor     string              `json:"activeColor"`
-	PlayerColor     string              `json:"playerColor"`
-	WhitePlayerName string              `json:"whitePlayerName"`
-	BlackPlayerName string              `json:"blackPlayerName"`
-	StartingColor   string              `json:"startingColor"`
+	Board           []dtos.BoardItem      `json:"board"`
+	InitialBoard    []dtos.BoardItem      `json:"initialBoard"`
+	Moves           []dtos.MoveItem       `json:"moves"`
+	ActiveColor     string                `json:"activeColor"`
+	PlayerColor     string                `json:"playerColor"`
+	WhitePlayerName string                `json:"whitePlayerName"`
+	BlackPlayerName string                `json:"blackPlayerName"`
+	StartingColor   string                `json:"startingColor"`
+	Result          string                `json:"result"`
+	Termination     string                `json:"termination"`
+	Variant         string                `json:"variant"`
+	TimeControl     dtos.TimeControl      `json:"timeControl"`
+	Clock           *dtos.Clock           `json:"clock"`
+	Deadline        *time.Time            `json:"deadline"` // when the active player of a correspondence game has to move
+	Rated           bool                  `json:"rated"`
+	Private         bool                  `json:"private"`
+	Armageddon      bool                  `json:"armageddon"`  // a draw counts as a win for black
+	Berserkable     bool                  `json:"berserkable"` // players may halve their clock before their first move
+	WhiteBerserk    bool                  `json:"whiteBerserk"`
+	BlackBerserk    bool                  `json:"blackBerserk"`
+	Chat            []ChatMessageResponse `json:"chat"`           // the messages of the room the caller can see
+	ClaimableAt     *time.Time            `json:"claimableAt"`    // set while the opponent of a player is disconnected
+	PreviousGameId  *string               `json:"previousGameId"` // set if the game is a rematch
+	RematchGameId   *string               `json:"rematchGameId"`
+	Spectators      string                `json:"spectators"`
+	BroadcastDelay  *dtos.Delay           `json:"broadcastDelay"` // null if spectators see the game right away
 }
 
-type BoardItemResponse struct {
-	Color    string      `json:"color"`
-	Type     string      `json:"type"`
-	Position PositionDto `json:"position"`
+type GameStartedResponse struct {
+	WhitePlayerName string `json:"whitePlayerName"`
+	BlackPlayerName string `json:"blackPlayerName"`
 }
 
-type MoveItemResponse struct {
-	From          PositionDto `json:"from"`
-	To            PositionDto `json:"to"`
-	Color         string      `json:"color"`
-	Type          string      `json:"type"`
-	Kind          string      `json:"kind"`
-	Status        string      `json:"status"`
-	Captures      bool        `json:"captures"`
-	PromoteToType *string     `json:"promoteToType"`
+type GameEndedResponse struct {
+	Result      string `json:"result"`
+	Termination string `json:"termination"`
 }
 
-type PositionDto struct {
-	X int `json:"x"`
-	Y int `json:"y"`
+type DrawOfferedResponse struct {
+	Color string `json:"color"`
 }
 
-type GameStartedResponse struct {
-	WhitePlayerName string `json:"whitePlayerName"`
-	BlackPlayerName string `json:"blackPlayerName"`
+type BerserkResponse struct {
+	Color string `json:"color"`
 }
 
 func (h *GameHub) JoinGame(request JoinGameRequest) {
@@ -88,7 +216,14 @@ func (h *GameHub) JoinGame(request JoinGameRequest) {
 		return
 	}
 
-	player := game.GetPlayerByToken(request.Token)
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	token := identity.Subject
+
+	player := game.GetPlayerByToken(token)
 	if game.PlayerCount() == 2 {
 		if player == nil {
 			h.Clients().Caller().Send("gameFull")
@@ -96,46 +231,39 @@ func (h *GameHub) JoinGame(request JoinGameRequest) {
 		}
 	}
 
-	if player != nil {
-		player = game.RejoinPlayer(request.Token, h.ConnectionID())
-	} else {
-		player = game.AddPlayer(request.PlayerName, request.Token, h.ConnectionID())
+	if player == nil && !game.Admits(constants.SeatInvite, request.Password, request.Invite) {
+		h.Clients().Caller().Send("gameAccessDenied")
+		return
 	}
 
-	joinResponse := JoinGameResponse{
-		Board:           []BoardItemResponse{},
-		Moves:           []MoveItemResponse{},
-		ActiveColor:     constants.ColorAsString(game.ActiveColor()),
-		PlayerColor:     constants.ColorAsString(player.Color()),
-		WhitePlayerName: game.OpponentName(constants.Black),
-		BlackPlayerName: game.OpponentName(constants.White),
-		StartingColor:   constants.ColorAsString(game.StartingColor()),
+	if player == nil && game.IsRated() && !identity.HasAccount() {
+		h.Clients().Caller().Send("unauthorized", UnauthorizedResponse{Error: errRatedGameNeedsAccount})
+		return
 	}
 
-	for _, piece := range game.Pieces() {
-		joinResponse.Board = append(joinResponse.Board, BoardItemResponse{
-			Color: constants.ColorAsString(piece.Color()),
-			Type:  constants.TypeAsString(piece.Type()),
-			Position: PositionDto{
-				X: piece.X(),
-				Y: piece.Y(),
-			},
-		})
-	}
+	isRejoin := player != nil
+	if isRejoin {
+		wasDisconnected := !player.IsConnected()
+		player = game.RejoinPlayer(token, h.ConnectionID())
 
-	for _, piece := range game.InitialPieces() {
-		joinResponse.InitialBoard = append(joinResponse.InitialBoard, BoardItemResponse{
-			Color: constants.ColorAsString(piece.Color()),
-			Type:  constants.TypeAsString(piece.Type()),
-			Position: PositionDto{
-				X: piece.X(),
-				Y: piece.Y(),
-			},
-		})
+		if wasDisconnected {
+			h.opponentReconnected(game, player)
+		}
+	} else {
+		var err error
+		player, err = game.AddPlayer(identity.Name, token, h.ConnectionID())
+		if err != nil {
+			h.Clients().Caller().Send("gameFull")
+			return
+		}
 	}
 
-	for _, move := range game.History() {
-		joinResponse.Moves = append(joinResponse.Moves, moveAsMoveItem(move))
+	joinResponse := newJoinGameResponse(game, currentPosition)
+	joinResponse.PlayerColor = constants.ColorAsString(player.Color())
+	joinResponse.Chat = playerChatHistory(game, player.Color())
+
+	if claimableAt, ok := game.ClaimableTime(player.Color()); ok {
+		joinResponse.ClaimableAt = &claimableAt
 	}
 
 	h.Clients().Caller().Send("gameJoined", joinResponse)
@@ -148,43 +276,19 @@ func (h *GameHub) JoinGame(request JoinGameRequest) {
 			BlackPlayerName: game.OpponentName(constants.White),
 		}
 
-		h.Clients().Group("game-"+request.GameId).Send("gameStarted", gameStartedResponse)
-		h.Clients().Group("spectators-"+request.GameId).Send("gameStarted", gameStartedResponse)
-	}
-}
-
-func moveAsMoveItem(move game.Move) MoveItemResponse {
-	t := move.Type()
-	if move.Kind() == constants.Promotion {
-		t = constants.Pawn
-	}
-
-	var promotionType *string = nil
-	if move.Kind() == constants.Promotion {
-		temp := constants.TypeAsString(move.Type())
-		promotionType = &temp
-	}
-
-	return MoveItemResponse{
-		From: PositionDto{
-			X: move.FromX(),
-			Y: move.FromY(),
-		},
-		To: PositionDto{
-			X: move.ToX(),
-			Y: move.ToY(),
-		},
-		Color:         constants.ColorAsString(move.Color()),
-		Type:          constants.TypeAsString(t),
-		Kind:          constants.MoveKindAsString(move.Kind()),
-		Status:        constants.StatusAsString(move.Status()),
-		Captures:      move.Captures(),
-		PromoteToType: promotionType,
+		if isRejoin {
+			h.Clients().Group("game-"+request.GameId).Send("gameStarted", gameStartedResponse)
+			h.Context().Value("relay").(*spectatorRelay).Send(game, "gameStarted", gameStartedResponse)
+		} else {
+			h.Clients().Caller().Send("gameStarted", gameStartedResponse)
+		}
 	}
 }
 
 type JoinSpectatorRequest struct {
-	GameId string `json:"gameId"`
+	GameId   string `json:"gameId"`
+	Password string `json:"password"` // private games need the password or an invite to be watched
+	Invite   string `json:"invite"`
 }
 
 func (h *GameHub) JoinSpectator(request JoinSpectatorRequest) {
@@ -196,49 +300,111 @@ func (h *GameHub) JoinSpectator(request JoinSpectatorRequest) {
 		return
 	}
 
+	if !game.Admits(constants.SpectatorInvite, request.Password, request.Invite) {
+		h.Clients().Caller().Send("gameAccessDenied")
+		return
+	}
+
+	if !game.AdmitsSpectators() {
+		h.Clients().Caller().Send("spectatingNotAllowed")
+		return
+	}
+
+	relay := h.Context().Value("relay").(*spectatorRelay)
+
+	// spectators of a game with a broadcast delay start at the position they are allowed to see
+	relay.Watch(game, func(plies int) {
+		joinResponse := newJoinGameResponse(game, plies)
+		joinResponse.PlayerColor = "None"
+		joinResponse.Chat = spectatorChatHistory(game)
+
+		h.Clients().Caller().Send("gameJoined", joinResponse)
+
+		h.Groups().AddToGroup("spectators-"+request.GameId, h.ConnectionID())
+	})
+
+	game.AddSpectator(h.ConnectionID())
+}
+
+// currentPosition asks newJoinGameResponse for the game with all moves played so far.
+const currentPosition = -1
+
+// newJoinGameResponse describes the game after the given number of plies, which is less than all moves for
+// spectators of a game with a broadcast delay. The clock and the deadline are only sent for the current position.
+func newJoinGameResponse(game *game.Game, plies int) JoinGameResponse {
+	history := game.History()
+	pieces, activeColor := game.Pieces(), game.ActiveColor()
+	isDelayed := plies != currentPosition && plies < len(history)
+	if isDelayed {
+		pieces, activeColor = game.PositionAfter(plies)
+	} else {
+		plies = len(history)
+	}
+
 	joinResponse := JoinGameResponse{
-		Board:           []BoardItemResponse{},
-		Moves:           []MoveItemResponse{},
-		ActiveColor:     constants.ColorAsString(game.ActiveColor()),
-		PlayerColor:     "None",
+		ActiveColor:     constants.ColorAsString(activeColor),
 		WhitePlayerName: game.OpponentName(constants.Black),
 		BlackPlayerName: game.OpponentName(constants.White),
 		StartingColor:   constants.ColorAsString(game.StartingColor()),
+		Result:          constants.ResultAsString(game.Result()),
+		Termination:     constants.TerminationAsString(game.Termination()),
+		Variant:         constants.VariantAsString(game.Variant()),
+		TimeControl:     dtos.TimeControlAsResponse(game.TimeControl()),
+		Rated:           game.IsRated(),
+		Private:         game.IsPrivate(),
+		Armageddon:      game.IsArmageddon(),
+		Berserkable:     game.AllowsBerserk(),
+		WhiteBerserk:    game.IsBerserk(constants.White),
+		BlackBerserk:    game.IsBerserk(constants.Black),
+		Spectators:      constants.SpectatorPolicyAsString(game.SpectatorPolicy()),
+		BroadcastDelay:  dtos.DelayAsResponse(game.BroadcastDelay()),
+		Board:           dtos.PiecesAsBoardItems(pieces),
+		InitialBoard:    dtos.PiecesAsBoardItems(game.InitialPieces()),
+		Moves:           dtos.MovesAsMoveItems(history[:plies]),
 	}
 
-	for _, piece := range game.Pieces() {
-		joinResponse.Board = append(joinResponse.Board, BoardItemResponse{
-			Color: constants.ColorAsString(piece.Color()),
-			Type:  constants.TypeAsString(piece.Type()),
-			Position: PositionDto{
-				X: piece.X(),
-				Y: piece.Y(),
-			},
-		})
+	if !game.TimeControl().IsUnlimited() && !isDelayed {
+		clock := dtos.GameAsClock(game)
+		joinResponse.Clock = &clock
 	}
 
-	for _, piece := range game.InitialPieces() {
-		joinResponse.InitialBoard = append(joinResponse.InitialBoard, BoardItemResponse{
-			Color: constants.ColorAsString(piece.Color()),
-			Type:  constants.TypeAsString(piece.Type()),
-			Position: PositionDto{
-				X: piece.X(),
-				Y: piece.Y(),
-			},
-		})
+	if deadline, ok := game.Deadline(); ok && !isDelayed {
+		joinResponse.Deadline = &deadline
 	}
 
-	for _, move := range game.History() {
-		joinResponse.Moves = append(joinResponse.Moves, moveAsMoveItem(move))
+	if game.PreviousGameId() != "" {
+		previousGameId := string(game.PreviousGameId())
+		joinResponse.PreviousGameId = &previousGameId
 	}
 
-	h.Clients().Caller().Send("gameJoined", joinResponse)
+	if game.RematchGameId() != "" {
+		rematchGameId := string(game.RematchGameId())
+		joinResponse.RematchGameId = &rematchGameId
+	}
 
-	h.Groups().AddToGroup("spectators-"+request.GameId, h.ConnectionID())
+	return joinResponse
 }
 
 func (h *GameHub) LeaveSpectator(request JoinSpectatorRequest) {
 	h.Groups().RemoveFromGroup("spectators-"+request.GameId, h.ConnectionID())
+
+	manager := h.Context().Value("manager").(*game.Manager)
+
+	if game := manager.GetGame(game.Id(request.GameId)); game != nil {
+		game.RemoveSpectator(h.ConnectionID())
+	}
+}
+
+func (h *GameHub) OnDisconnected(connectionId string) {
+	manager := h.Context().Value("manager").(*game.Manager)
+
+	manager.RemoveSpectatorFromAllGames(connectionId)
+
+	h.disconnectPlayer(connectionId)
+
+	queue := h.Context().Value("matchmaking").(*matchmaking.Queue)
+
+	queue.LeaveByConnection(connectionId)
 }
 
 func (h *GameHub) gameNotFound() {
@@ -246,10 +412,14 @@ func (h *GameHub) gameNotFound() {
 }
 
 type MoveRequest struct {
-	GameId        string      `json:"gameId"`
-	From          PositionDto `json:"from"`
-	To            PositionDto `json:"to"`
-	PromoteToType *string     `json:"promoteToType"`
+	GameId        string        `json:"gameId"`
+	From          dtos.Position `json:"from"`
+	To            dtos.Position `json:"to"`
+	PromoteToType *string       `json:"promoteToType"`
+}
+
+type InvalidMoveResponse struct {
+	Reason string `json:"reason"`
 }
 
 func (h *GameHub) Move(request MoveRequest) {
@@ -264,23 +434,82 @@ func (h *GameHub) Move(request MoveRequest) {
 	player := game.GetPlayerByConnectionId(h.ConnectionID())
 	if player == nil {
 		h.Clients().Caller().Send("playerNotFound")
+		return
 	}
 
-	if game.ActiveColor() != player.Color() {
+	// the move is broadcast by the listener registered in SetupGameHub
+	move, reason := game.Move(player.Color(), request.From.X, request.From.Y, request.To.X, request.To.Y, request.PromoteToType)
+	if move == nil {
+		h.invalidMove(reason)
 		return
 	}
+}
 
-	// check if move is valid
+func (h *GameHub) invalidMove(reason int) {
+	h.Clients().Caller().Send("invalidMove", InvalidMoveResponse{
+		Reason: constants.MoveErrorAsString(reason),
+	})
+}
 
-	if !game.Move(request.From.X, request.From.Y, request.To.X, request.To.Y, request.PromoteToType) {
-		h.Clients().Caller().Send("invalidMove")
+type GameActionRequest struct {
+	GameId string `json:"gameId"`
+}
+
+func (h *GameHub) Resign(request GameActionRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
 	}
 
-	lastMove := game.LastMove()
+	game.Resign(player.Color())
+}
 
-	moveItemResponse := moveAsMoveItem(*lastMove)
-	h.Clients().Group("game-"+request.GameId).Send("move", moveItemResponse)
-	h.Clients().Group("spectators-"+request.GameId).Send("move", moveItemResponse)
+// Berserk halves the clock of the caller in an arena game, which is only possible before their first move.
+func (h *GameHub) Berserk(request GameActionRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	game.Berserk(player.Color())
+}
+
+func (h *GameHub) OfferDraw(request GameActionRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	game.OfferDraw(player.Color())
+}
+
+func (h *GameHub) DeclineDraw(request GameActionRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	if game.DeclineDraw(player.Color()) {
+		h.Clients().Group("game-" + request.GameId).Send("drawDeclined")
+	}
+}
+
+func (h *GameHub) getGameAndPlayer(gameId string) (*game.Game, *game.Player) {
+	manager := h.Context().Value("manager").(*game.Manager)
+
+	game := manager.GetGame(game.Id(gameId))
+	if game == nil {
+		h.gameNotFound()
+		return nil, nil
+	}
+
+	player := game.GetPlayerByConnectionId(h.ConnectionID())
+	if player == nil {
+		h.Clients().Caller().Send("playerNotFound")
+		return game, nil
+	}
+
+	return game, player
 }
 
 type ChangeNameRequest struct {
@@ -293,10 +522,18 @@ type ChangeNameResponse struct {
 	Color string `json:"color"`
 }
 
+// ChangeName renames the caller in all of their games.
 func (h *GameHub) ChangeName(request ChangeNameRequest) {
 	manager := h.Context().Value("manager").(*game.Manager)
 
-	playerGames := manager.GetGamesForPlayer(request.Token)
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	relay := h.Context().Value("relay").(*spectatorRelay)
+
+	playerGames := manager.RenamePlayer(identity.Subject, request.Name)
 
 	for _, playerGame := range playerGames {
 		h.Clients().Group("game-"+string(playerGame.Id())).Send("playerNameChanged", ChangeNameResponse{
@@ -304,9 +541,11 @@ func (h *GameHub) ChangeName(request ChangeNameRequest) {
 			Color: constants.ColorAsString(playerGame.Color()),
 		})
 
-		h.Clients().Group("spectators-"+string(playerGame.Id())).Send("playerNameChanged", ChangeNameResponse{
-			Name:  request.Name,
-			Color: constants.ColorAsString(playerGame.Color()),
-		})
+		if game := manager.GetGame(playerGame.Id()); game != nil {
+			relay.Send(game, "playerNameChanged", ChangeNameResponse{
+				Name:  request.Name,
+				Color: constants.ColorAsString(playerGame.Color()),
+			})
+		}
 	}
 }
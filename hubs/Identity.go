@@ -0,0 +1,30 @@
+package hubs
+
+import (
+	"github.com/racccoooon/chess-be/auth"
+)
+
+// identity returns who the caller is. A connection made with a signed token is bound to its identity.
+// Otherwise the signed token sent with the request is verified. Unsigned and expired tokens are rejected
+// and the caller receives unauthorized.
+func (h *GameHub) identity(requestToken string) (auth.Identity, bool) {
+	if identity, ok := auth.IdentityFromContext(h.Context()); ok {
+		return identity, true
+	}
+
+	issuer := h.Context().Value("issuer").(*auth.Issuer)
+
+	identity, err := issuer.Verify(requestToken)
+	if err != nil {
+		h.Clients().Caller().Send("unauthorized", UnauthorizedResponse{
+			Error: err.Error(),
+		})
+		return auth.Identity{}, false
+	}
+
+	return identity, true
+}
+
+type UnauthorizedResponse struct {
+	Error string `json:"error"`
+}
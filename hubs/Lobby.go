@@ -0,0 +1,55 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/game"
+)
+
+type LobbyUpdatedResponse struct {
+	Game    dtos.LobbyGame `json:"game"`
+	Removed bool           `json:"removed"`
+}
+
+// JoinLobby subscribes the caller to lobbyUpdated events.
+func (h *GameHub) JoinLobby() {
+	h.Groups().AddToGroup("lobby", h.ConnectionID())
+}
+
+func (h *GameHub) LeaveLobby() {
+	h.Groups().RemoveFromGroup("lobby", h.ConnectionID())
+}
+
+// registerLobbyListeners sends a lobbyUpdated event to the lobby group whenever a public game changes
+// in a way that is visible in the lobby.
+func registerLobbyListeners(manager *game.Manager, clients signalr.HubClients) {
+	sendLobbyUpdated := func(game *game.Game) {
+		if !game.IsPublic() {
+			return
+		}
+
+		clients.Group("lobby").Send("lobbyUpdated", LobbyUpdatedResponse{
+			Game:    dtos.GameAsLobbyGame(game),
+			Removed: game.IsOver(),
+		})
+	}
+
+	manager.OnGameCreated(sendLobbyUpdated)
+	manager.OnGameEnded(sendLobbyUpdated)
+	manager.OnSpectatorsChanged(sendLobbyUpdated)
+
+	manager.OnPlayerJoined(func(game *game.Game, player *game.Player) {
+		sendLobbyUpdated(game)
+	})
+
+	manager.OnGameRemoved(func(game *game.Game) {
+		if !game.IsPublic() {
+			return
+		}
+
+		clients.Group("lobby").Send("lobbyUpdated", LobbyUpdatedResponse{
+			Game:    dtos.GameAsLobbyGame(game),
+			Removed: true,
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/matchmaking"
+	"github.com/racccoooon/chess-be/ratings"
+	"math"
+	"time"
+)
+
+const defaultRating = 1500
+
+type EnterMatchmakingRequest struct {
+	Token       string              `json:"token"`
+	Variant     string              `json:"variant"`
+	TimeControl *TimeControlRequest `json:"timeControl"` // null for games without clocks
+	Rated       bool                `json:"rated"`
+	Rating      *int                `json:"rating"` // ignored for rated games, which use the rating of the account
+	RatingRange int                 `json:"ratingRange"`
+}
+
+type TimeControlRequest struct {
+	InitialSeconds   int `json:"initialSeconds"`
+	IncrementSeconds int `json:"incrementSeconds"`
+}
+
+type MatchFoundResponse struct {
+	GameId       string `json:"gameId"`
+	Color        string `json:"color"`
+	OpponentName string `json:"opponentName"`
+}
+
+// EnterMatchmaking puts the caller into the matchmaking queue. The caller receives matchFound once an opponent
+// was found and then joins the game with JoinGame and the same token.
+func (h *GameHub) EnterMatchmaking(request EnterMatchmakingRequest) {
+	queue := h.Context().Value("matchmaking").(*matchmaking.Queue)
+	ratingStore := h.Context().Value("ratings").(*ratings.Store)
+
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	variant, ok := constants.VariantFromString(request.Variant)
+	if !ok || variant != constants.Standard || request.RatingRange < 0 {
+		h.Clients().Caller().Send("invalidMatchmakingRequest")
+		return
+	}
+
+	var timeControl game.TimeControl
+	if request.TimeControl != nil {
+		if request.TimeControl.InitialSeconds <= 0 || request.TimeControl.IncrementSeconds < 0 {
+			h.Clients().Caller().Send("invalidMatchmakingRequest")
+			return
+		}
+
+		timeControl = game.NewTimeControl(
+			time.Duration(request.TimeControl.InitialSeconds)*time.Second,
+			time.Duration(request.TimeControl.IncrementSeconds)*time.Second)
+	}
+
+	if request.Rated && !identity.HasAccount() {
+		h.Clients().Caller().Send("unauthorized", UnauthorizedResponse{Error: errRatedGameNeedsAccount})
+		return
+	}
+
+	rating := defaultRating
+	if request.Rated {
+		rating = int(math.Round(ratingStore.GetRating(identity.Subject, timeControl.Category()).Rating()))
+	} else if request.Rating != nil {
+		rating = *request.Rating
+	}
+
+	queue.Enter(matchmaking.Ticket{
+		PlayerName:   identity.Name,
+		Token:        identity.Subject,
+		ConnectionId: h.ConnectionID(),
+		Variant:      variant,
+		TimeControl:  timeControl,
+		Rated:        request.Rated,
+		Rating:       rating,
+		RatingRange:  request.RatingRange,
+	})
+
+	h.Clients().Caller().Send("matchmakingEntered")
+}
+
+type LeaveMatchmakingRequest struct {
+	Token string `json:"token"`
+}
+
+func (h *GameHub) LeaveMatchmaking(request LeaveMatchmakingRequest) {
+	queue := h.Context().Value("matchmaking").(*matchmaking.Queue)
+
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	if queue.Leave(identity.Subject) {
+		h.Clients().Caller().Send("matchmakingLeft")
+	}
+}
+
+func registerMatchmakingListeners(queue *matchmaking.Queue, clients signalr.HubClients) {
+	queue.OnMatch(func(match matchmaking.Match) {
+		gameId := string(match.Game.Id())
+
+		clients.Client(match.White.ConnectionId).Send("matchFound", MatchFoundResponse{
+			GameId:       gameId,
+			Color:        constants.ColorAsString(constants.White),
+			OpponentName: match.Black.PlayerName,
+		})
+
+		clients.Client(match.Black.ConnectionId).Send("matchFound", MatchFoundResponse{
+			GameId:       gameId,
+			Color:        constants.ColorAsString(constants.Black),
+			OpponentName: match.White.PlayerName,
+		})
+	})
+}
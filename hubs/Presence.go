@@ -0,0 +1,110 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"time"
+)
+
+type OpponentDisconnectedResponse struct {
+	Color       string     `json:"color"`       // the color of the player that disconnected
+	ClaimableAt *time.Time `json:"claimableAt"` // when the opponent may claim the game, null in correspondence games
+}
+
+type OpponentReconnectedResponse struct {
+	Color string `json:"color"`
+}
+
+type ClaimAvailableResponse struct {
+	Color string `json:"color"` // the color of the player that may claim the game
+}
+
+// OnConnected puts a connection made with a token back into the games its player was disconnected from.
+func (h *GameHub) OnConnected(connectionId string) {
+	identity, ok := auth.IdentityFromContext(h.Context())
+	if !ok {
+		return
+	}
+
+	manager := h.Context().Value("manager").(*game.Manager)
+
+	for _, playerGame := range manager.GetGamesForPlayer(identity.Subject) {
+		game := manager.GetGame(playerGame.Id())
+		if game == nil || game.IsOver() {
+			continue
+		}
+
+		player := game.GetPlayerByToken(identity.Subject)
+		if player == nil || player.IsConnected() {
+			continue
+		}
+
+		game.RejoinPlayer(identity.Subject, connectionId)
+
+		h.Groups().AddToGroup("game-"+string(game.Id()), connectionId)
+		h.opponentReconnected(game, player)
+	}
+}
+
+// disconnectPlayer tells the opponents of the player using the connection that they are gone.
+func (h *GameHub) disconnectPlayer(connectionId string) {
+	manager := h.Context().Value("manager").(*game.Manager)
+
+	for _, playerGame := range manager.DisconnectPlayerFromAllGames(connectionId) {
+		game := manager.GetGame(playerGame.Id())
+		if game == nil || game.IsOver() || game.PlayerCount() != 2 {
+			continue
+		}
+
+		response := OpponentDisconnectedResponse{
+			Color: constants.ColorAsString(playerGame.Color()),
+		}
+
+		if claimableAt, ok := game.ClaimableTime(constants.GetOppositeColor(playerGame.Color())); ok {
+			response.ClaimableAt = &claimableAt
+		}
+
+		h.Clients().Group("game-"+string(game.Id())).Send("opponentDisconnected", response)
+	}
+}
+
+func (h *GameHub) opponentReconnected(game *game.Game, player *game.Player) {
+	h.Clients().Group("game-"+string(game.Id())).Send("opponentReconnected", OpponentReconnectedResponse{
+		Color: constants.ColorAsString(player.Color()),
+	})
+}
+
+// ClaimVictory ends the game as a win for the caller once their opponent was gone for longer than the grace period.
+func (h *GameHub) ClaimVictory(request GameActionRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	if !game.ClaimVictory(player.Color()) {
+		h.Clients().Caller().Send("claimRejected")
+	}
+}
+
+// ClaimDraw ends the game as a draw once the opponent of the caller was gone for longer than the grace period.
+func (h *GameHub) ClaimDraw(request GameActionRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	if !game.ClaimDraw(player.Color()) {
+		h.Clients().Caller().Send("claimRejected")
+	}
+}
+
+// registerPresenceListeners offers the claim to the remaining player once the grace period of the opponent is over.
+func registerPresenceListeners(manager *game.Manager, clients signalr.HubClients) {
+	manager.OnAbandoned(func(game *game.Game, player *game.Player) {
+		clients.Group("game-"+string(game.Id())).Send("claimAvailable", ClaimAvailableResponse{
+			Color: constants.ColorAsString(constants.GetOppositeColor(player.Color())),
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/game"
+	"sync"
+	"time"
+)
+
+// spectatorRelay sends game events to the spectators of a game. Events of games with a broadcast delay are held
+// back until the delay releases them, so spectators can not pass the moves to the players while they are relevant.
+type spectatorRelay struct {
+	clients signalr.HubClients
+	games   map[game.Id]*delayedBroadcast
+	mutex   sync.Mutex
+}
+
+// delayedBroadcast keeps its own copy of what it needs to know about the game, so the timers that release
+// events never read the game.
+type delayedBroadcast struct {
+	id       game.Id
+	delay    game.BroadcastDelay
+	played   int  // the number of moves played when the last event happened
+	over     bool // the game ended, so everything is released
+	pending  []delayedEvent
+	released int // the number of moves the spectators have seen
+}
+
+type delayedEvent struct {
+	target  string
+	payload interface{}
+	plies   int // the number of moves played when the event happened
+	time    time.Time
+}
+
+func newSpectatorRelay() *spectatorRelay {
+	return &spectatorRelay{
+		games: make(map[game.Id]*delayedBroadcast),
+	}
+}
+
+// Send sends the event to the spectators of the game as soon as the broadcast delay of the game allows it.
+func (r *spectatorRelay) Send(g *game.Game, target string, payload interface{}) {
+	delayed := g.IsDelayed()
+	plies := len(g.History())
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	broadcast := r.games[g.Id()]
+	if broadcast == nil && !delayed {
+		r.clients.Group("spectators-"+string(g.Id())).Send(target, payload)
+		return
+	}
+
+	if broadcast == nil {
+		broadcast = &delayedBroadcast{
+			id:    g.Id(),
+			delay: g.BroadcastDelay(),
+		}
+		r.games[g.Id()] = broadcast
+	}
+
+	broadcast.played = plies
+	broadcast.over = !delayed
+	broadcast.pending = append(broadcast.pending, delayedEvent{
+		target:  target,
+		payload: payload,
+		plies:   plies,
+		time:    time.Now(),
+	})
+
+	r.release(broadcast)
+
+	if broadcast.delay.Duration > 0 && len(broadcast.pending) > 0 {
+		time.AfterFunc(broadcast.delay.Duration, func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+
+			if r.games[broadcast.id] == broadcast {
+				r.release(broadcast)
+			}
+		})
+	}
+}
+
+// Watch runs join with the number of moves the spectators have seen. Nothing is released while join runs,
+// so a spectator that joins the group in it neither misses nor repeats a move.
+func (r *spectatorRelay) Watch(g *game.Game, join func(plies int)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if broadcast := r.games[g.Id()]; broadcast != nil {
+		join(broadcast.released)
+		return
+	}
+
+	join(g.SpectatorPlies())
+}
+
+// Forget drops the held back events of a removed game.
+func (r *spectatorRelay) Forget(g *game.Game) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.games, g.Id())
+}
+
+// release sends the pending events the delay releases, in the order they happened.
+func (r *spectatorRelay) release(broadcast *delayedBroadcast) {
+	for len(broadcast.pending) > 0 {
+		event := broadcast.pending[0]
+		if !broadcast.over && !broadcast.delay.Releases(broadcast.played, event.plies, event.time) {
+			return
+		}
+
+		broadcast.pending = broadcast.pending[1:]
+		broadcast.released = event.plies
+
+		r.clients.Group("spectators-"+string(broadcast.id)).Send(event.target, event.payload)
+	}
+
+	if broadcast.over {
+		delete(r.games, broadcast.id)
+	}
+}
@@ -0,0 +1,88 @@
+package hubs
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+)
+
+type RematchRequest struct {
+	GameId string `json:"gameId"`
+}
+
+type RematchOfferedResponse struct {
+	Color string `json:"color"`
+}
+
+type RematchStartedResponse struct {
+	PreviousGameId  string  `json:"previousGameId"`
+	GameId          string  `json:"gameId"`
+	PlayerColor     *string `json:"playerColor"` // null for spectators
+	WhitePlayerName string  `json:"whitePlayerName"`
+	BlackPlayerName string  `json:"blackPlayerName"`
+}
+
+// RequestRematch offers the opponent a rematch of a finished game. If the opponent already offered one,
+// the rematch starts right away.
+func (h *GameHub) RequestRematch(request RematchRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	rematch, ok := game.OfferRematch(player.Color())
+	if !ok {
+		h.Clients().Caller().Send("rematchRejected")
+		return
+	}
+
+	if rematch == nil {
+		h.Clients().Group("game-"+request.GameId).Send("rematchOffered", RematchOfferedResponse{
+			Color: constants.ColorAsString(player.Color()),
+		})
+		return
+	}
+
+	h.startRematch(game, rematch)
+}
+
+func (h *GameHub) AcceptRematch(request RematchRequest) {
+	game, player := h.getGameAndPlayer(request.GameId)
+	if game == nil || player == nil {
+		return
+	}
+
+	rematch, ok := game.AcceptRematch(player.Color())
+	if !ok {
+		h.Clients().Caller().Send("rematchRejected")
+		return
+	}
+
+	h.startRematch(game, rematch)
+}
+
+// startRematch moves the connections of both players into the group of the rematch and tells them
+// their new colors. Spectators of the previous game are told where the players went.
+func (h *GameHub) startRematch(previous *game.Game, rematch *game.Game) {
+	response := RematchStartedResponse{
+		PreviousGameId:  string(previous.Id()),
+		GameId:          string(rematch.Id()),
+		WhitePlayerName: rematch.OpponentName(constants.Black),
+		BlackPlayerName: rematch.OpponentName(constants.White),
+	}
+
+	h.Context().Value("relay").(*spectatorRelay).Send(previous, "rematchStarted", response)
+
+	for _, color := range []int{constants.White, constants.Black} {
+		player := rematch.GetPlayerByColor(color)
+		if player.ConnectionId() == "" {
+			continue
+		}
+
+		h.Groups().RemoveFromGroup("game-"+string(previous.Id()), player.ConnectionId())
+		h.Groups().AddToGroup("game-"+string(rematch.Id()), player.ConnectionId())
+
+		playerColor := constants.ColorAsString(color)
+		response.PlayerColor = &playerColor
+		h.Clients().Client(player.ConnectionId()).Send("rematchStarted", response)
+	}
+}
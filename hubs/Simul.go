@@ -0,0 +1,102 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/dtos"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/simuls"
+)
+
+type SimulRequest struct {
+	SimulId string `json:"simulId"`
+	Token   string `json:"token"`
+}
+
+type SimulUpdatedResponse struct {
+	SimulId          string               `json:"simulId"`
+	Status           string               `json:"status"`
+	ParticipantCount int                  `json:"participantCount"`
+	Boards           []SimulBoardResponse `json:"boards"`
+}
+
+type SimulBoardResponse struct {
+	GameId string                   `json:"gameId"`
+	Player TournamentPlayerResponse `json:"player"`
+	Result string                   `json:"result"`
+}
+
+type SimulMoveResponse struct {
+	SimulId string        `json:"simulId"`
+	GameId  string        `json:"gameId"`
+	Move    dtos.MoveItem `json:"move"`
+}
+
+// JoinSimul subscribes the caller to the events of a simul, including the moves of all boards, so the host
+// can follow every game with a single subscription. Only the host and the participants may join, as the moves
+// would otherwise bypass the spectator settings of the boards.
+func (h *GameHub) JoinSimul(request SimulRequest) {
+	manager := h.Context().Value("simuls").(*simuls.Manager)
+
+	simul := manager.GetSimul(simuls.Id(request.SimulId))
+	if simul == nil {
+		h.Clients().Caller().Send("simulNotFound")
+		return
+	}
+
+	identity, ok := h.identity(request.Token)
+	if !ok {
+		return
+	}
+
+	if !simul.IsMember(identity.Subject) {
+		h.Clients().Caller().Send("simulAccessDenied")
+		return
+	}
+
+	h.Groups().AddToGroup("simul-"+request.SimulId, h.ConnectionID())
+	h.Clients().Caller().Send("simulUpdated", simulAsUpdatedResponse(simul))
+}
+
+func (h *GameHub) LeaveSimul(request SimulRequest) {
+	h.Groups().RemoveFromGroup("simul-"+request.SimulId, h.ConnectionID())
+}
+
+// registerSimulListeners forwards the moves of all simul games and the board results to the simul group.
+func registerSimulListeners(manager *simuls.Manager, games *game.Manager, clients signalr.HubClients) {
+	games.OnMove(func(game *game.Game, move game.Move) {
+		simul := manager.GetSimulByGame(game.Id())
+		if simul == nil {
+			return
+		}
+
+		clients.Group("simul-"+string(simul.Id())).Send("simulMove", SimulMoveResponse{
+			SimulId: string(simul.Id()),
+			GameId:  string(game.Id()),
+			Move:    dtos.MoveAsMoveItem(move),
+		})
+	})
+
+	manager.OnSimulUpdated(func(simul *simuls.Simul) {
+		clients.Group("simul-"+string(simul.Id())).Send("simulUpdated", simulAsUpdatedResponse(simul))
+	})
+}
+
+func simulAsUpdatedResponse(simul *simuls.Simul) SimulUpdatedResponse {
+	response := SimulUpdatedResponse{
+		SimulId:          string(simul.Id()),
+		Status:           constants.TournamentStatusAsString(simul.Status()),
+		ParticipantCount: len(simul.Participants()),
+		Boards:           []SimulBoardResponse{},
+	}
+
+	for _, board := range simul.Boards() {
+		response.Boards = append(response.Boards, SimulBoardResponse{
+			GameId: string(board.GameId()),
+			Player: TournamentPlayerResponse{Id: board.Participant().Id(), Name: board.Participant().Name()},
+			Result: constants.ResultAsString(board.Result()),
+		})
+	}
+
+	return response
+}
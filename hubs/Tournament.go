@@ -0,0 +1,153 @@
+package hubs
+
+import (
+	"github.com/philippseith/signalr"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/tournaments"
+)
+
+type TournamentRequest struct {
+	TournamentId string `json:"tournamentId"`
+}
+
+type TournamentUpdatedResponse struct {
+	TournamentId string              `json:"tournamentId"`
+	Status       string              `json:"status"`
+	Rounds       int                 `json:"rounds"`
+	CurrentRound int                 `json:"currentRound"`
+	PlayerCount  int                 `json:"playerCount"`
+	Standings    []StandingResponse  `json:"standings"`
+	Pairings     []PairingResponse   `json:"pairings"` // of the current round
+	Bracket      [][]PairingResponse `json:"bracket"`  // all rounds of a knockout, null for other formats
+}
+
+type StandingResponse struct {
+	Rank            int     `json:"rank"`
+	PlayerId        string  `json:"playerId"`
+	Name            string  `json:"name"`
+	Rating          int     `json:"rating"`
+	Score           float64 `json:"score"`
+	Buchholz        float64 `json:"buchholz"`
+	SonnebornBerger float64 `json:"sonnebornBerger"`
+	Withdrawn       bool    `json:"withdrawn"`
+}
+
+type PairingResponse struct {
+	Board            int                       `json:"board"`
+	White            TournamentPlayerResponse  `json:"white"`
+	Black            *TournamentPlayerResponse `json:"black"` // null for a bye
+	GameId           *string                   `json:"gameId"`
+	ArmageddonGameId *string                   `json:"armageddonGameId"`
+	WinnerId         *string                   `json:"winnerId"`
+	Result           string                    `json:"result"`
+	Forfeit          bool                      `json:"forfeit"`
+}
+
+type TournamentPlayerResponse struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// JoinTournament subscribes the caller to the tournamentUpdated events of a tournament and sends them the current state.
+func (h *GameHub) JoinTournament(request TournamentRequest) {
+	manager := h.Context().Value("tournaments").(*tournaments.Manager)
+
+	tournament := manager.GetTournament(tournaments.Id(request.TournamentId))
+	if tournament == nil {
+		h.Clients().Caller().Send("tournamentNotFound")
+		return
+	}
+
+	h.Groups().AddToGroup("tournament-"+request.TournamentId, h.ConnectionID())
+	h.Clients().Caller().Send("tournamentUpdated", tournamentAsUpdatedResponse(tournament))
+}
+
+func (h *GameHub) LeaveTournament(request TournamentRequest) {
+	h.Groups().RemoveFromGroup("tournament-"+request.TournamentId, h.ConnectionID())
+}
+
+// registerTournamentListeners sends the standings and pairings to the tournament group whenever they change.
+func registerTournamentListeners(manager *tournaments.Manager, clients signalr.HubClients) {
+	manager.OnTournamentUpdated(func(tournament *tournaments.Tournament) {
+		clients.Group("tournament-"+string(tournament.Id())).Send("tournamentUpdated",
+			tournamentAsUpdatedResponse(tournament))
+	})
+}
+
+func tournamentAsUpdatedResponse(tournament *tournaments.Tournament) TournamentUpdatedResponse {
+	response := TournamentUpdatedResponse{
+		TournamentId: string(tournament.Id()),
+		Status:       constants.TournamentStatusAsString(tournament.Status()),
+		Rounds:       tournament.Rounds(),
+		CurrentRound: tournament.CurrentRound(),
+		PlayerCount:  tournament.PlayerCount(),
+		Standings:    []StandingResponse{},
+		Pairings:     []PairingResponse{},
+	}
+
+	for _, standing := range tournament.Standings() {
+		response.Standings = append(response.Standings, StandingResponse{
+			Rank:            standing.Rank,
+			PlayerId:        standing.PlayerId,
+			Name:            standing.Name,
+			Rating:          standing.Rating,
+			Score:           standing.Score,
+			Buchholz:        standing.Buchholz,
+			SonnebornBerger: standing.SonnebornBerger,
+			Withdrawn:       standing.Withdrawn,
+		})
+	}
+
+	if pairings, ok := tournament.Pairings(tournament.CurrentRound()); ok {
+		response.Pairings = pairingsAsPairingResponses(pairings)
+	}
+
+	if tournament.Format() == constants.Knockout {
+		response.Bracket = [][]PairingResponse{}
+		for round := 1; ; round++ {
+			pairings, ok := tournament.Pairings(round)
+			if !ok {
+				break
+			}
+
+			response.Bracket = append(response.Bracket, pairingsAsPairingResponses(pairings))
+		}
+	}
+
+	return response
+}
+
+func pairingsAsPairingResponses(pairings []tournaments.Pairing) []PairingResponse {
+	response := make([]PairingResponse, len(pairings))
+
+	for i, pairing := range pairings {
+		response[i] = PairingResponse{
+			Board:   pairing.Board(),
+			White:   TournamentPlayerResponse{Id: pairing.White().Id(), Name: pairing.White().Name()},
+			Result:  "bye",
+			Forfeit: pairing.IsForfeit(),
+		}
+
+		if !pairing.IsBye() {
+			response[i].Result = constants.ResultAsString(pairing.Result())
+			response[i].Black = &TournamentPlayerResponse{Id: pairing.Black().Id(), Name: pairing.Black().Name()}
+		}
+
+		if pairing.GameId() != "" {
+			gameId := string(pairing.GameId())
+			response[i].GameId = &gameId
+		}
+
+		if pairing.ArmageddonGameId() != "" {
+			armageddonGameId := string(pairing.ArmageddonGameId())
+			response[i].ArmageddonGameId = &armageddonGameId
+		}
+
+		if pairing.Winner() != nil {
+			winnerId := pairing.Winner().Id()
+			response[i].WinnerId = &winnerId
+		}
+	}
+
+	return response
+}
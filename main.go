@@ -1,11 +1,28 @@
 package main
 
 import (
+	"crypto/rand"
+	"github.com/racccoooon/chess-be/accounts"
+	"github.com/racccoooon/chess-be/analysis"
+	"github.com/racccoooon/chess-be/arenas"
+	"github.com/racccoooon/chess-be/auth"
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/engines"
 	"github.com/racccoooon/chess-be/game"
 	"github.com/racccoooon/chess-be/handlers"
+	"github.com/racccoooon/chess-be/history"
 	"github.com/racccoooon/chess-be/hubs"
+	"github.com/racccoooon/chess-be/matchmaking"
 	"github.com/racccoooon/chess-be/middlewares"
+	"github.com/racccoooon/chess-be/notifications"
+	"github.com/racccoooon/chess-be/ratings"
+	"github.com/racccoooon/chess-be/simuls"
+	"github.com/racccoooon/chess-be/streams"
+	"github.com/racccoooon/chess-be/tournaments"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +30,8 @@ func main() {
 	router := http.NewServeMux()
 
 	gameManager := game.NewGameManager()
+	gameManager.SetChatFilter(chatFilter())
+	gameManager.SetDisconnectGrace(disconnectGrace())
 
 	ticket := time.NewTicker(1 * time.Hour)
 	go func() {
@@ -21,15 +40,195 @@ func main() {
 		}
 	}()
 
-	hubs.SetupGameHub(gameManager, router)
+	clockTicker := time.NewTicker(1 * time.Second)
+	go func() {
+		for range clockTicker.C {
+			gameManager.CheckTimeouts()
+			gameManager.CheckDisconnects()
+		}
+	}()
+
+	ratingStore := ratings.NewStore()
+	gameManager.OnGameEnded(ratingStore.RateGame)
+
+	archive := history.NewArchive()
+	gameManager.OnGameEnded(archive.RecordGame)
+
+	tournamentManager := tournaments.NewManager(gameManager)
+	gameManager.OnGameEnded(tournamentManager.RecordResult)
+	gameManager.OnGameRemoved(tournamentManager.HandleGameRemoved)
+
+	arenaManager := arenas.NewManager(gameManager)
+	gameManager.OnGameEnded(arenaManager.RecordResult)
+	gameManager.OnGameRemoved(arenaManager.HandleGameRemoved)
+
+	arenaTicker := time.NewTicker(1 * time.Second)
+	go func() {
+		for range arenaTicker.C {
+			arenaManager.Tick()
+		}
+	}()
+
+	simulManager := simuls.NewManager(gameManager)
+	gameManager.OnGameEnded(simulManager.RecordResult)
+	gameManager.OnGameRemoved(simulManager.HandleGameRemoved)
+
+	bots := engines.NewBots(chessEngine())
+	gameManager.OnMove(bots.HandleMove)
+	gameManager.OnPlayerJoined(bots.HandlePlayerJoined)
+	gameManager.OnGameRemoved(bots.HandleGameRemoved)
+
+	broker := streams.NewBroker()
+	gameManager.OnPlayerJoined(broker.HandlePlayerJoined)
+	gameManager.OnMove(broker.HandleMove)
+	gameManager.OnGameEnded(broker.HandleGameEnded)
+	gameManager.OnGameRemoved(broker.HandleGameRemoved)
+	gameManager.OnDrawOffered(broker.HandleDrawOffered)
+	gameManager.OnBerserk(broker.HandleBerserk)
+	gameManager.OnChat(broker.HandleChat)
+
+	if notifier := turnNotifier(); notifier != nil {
+		gameManager.OnTurn(notifications.NewDispatcher(notifier).HandleTurn)
+	}
 
-	router.Handle("/api/games/", handlers.NewGameHandler(gameManager))
-	router.Handle("/api/health", handlers.NewHealthHandler())
+	analysisManager := analysis.NewManager(engines.NewNativeEngine())
+	gameManager.OnGameRemoved(analysisManager.Remove)
 
-	corsMiddleware := &middlewares.CorsMiddleware{Handler: router}
+	challengeManager := challenges.NewManager(gameManager)
+	challengeManager.OnChallengeUpdated(broker.HandleChallengeUpdated)
+
+	challengeTicker := time.NewTicker(1 * time.Second)
+	go func() {
+		for range challengeTicker.C {
+			challengeManager.ExpireChallenges()
+		}
+	}()
+
+	matchmakingQueue := matchmaking.NewQueue(gameManager)
+
+	matchmakingTicker := time.NewTicker(1 * time.Second)
+	go func() {
+		for range matchmakingTicker.C {
+			matchmakingQueue.Pair()
+		}
+	}()
+
+	issuer := auth.NewIssuer(tokenSecret())
+	gameManager.SetInviteVerifier(func(token string) (game.Id, int, bool) {
+		invite, err := issuer.VerifyInvite(token)
+		return game.Id(invite.GameId), invite.Role, err == nil
+	})
+	accountStore := accounts.NewStore()
+
+	hubs.SetupGameHub(hubs.Services{
+		Manager:     gameManager,
+		Matchmaking: matchmakingQueue,
+		Issuer:      issuer,
+		Ratings:     ratingStore,
+		Tournaments: tournamentManager,
+		Arenas:      arenaManager,
+		Simuls:      simulManager,
+		Analyses:    analysisManager,
+		Challenges:  challengeManager,
+	}, router)
+
+	apiRouter := handlers.NewApiRouter(handlers.Services{
+		Manager:     gameManager,
+		Bots:        bots,
+		Issuer:      issuer,
+		Accounts:    accountStore,
+		Ratings:     ratingStore,
+		Archive:     archive,
+		Tournaments: tournamentManager,
+		Arenas:      arenaManager,
+		Simuls:      simulManager,
+		Analyses:    analysisManager,
+		Challenges:  challengeManager,
+		Broker:      broker,
+	}, debugRoutes())
+
+	router.Handle("/api/", apiRouter)
+
+	authMiddleware := &middlewares.AuthMiddleware{Handler: router, Issuer: issuer}
+	corsMiddleware := &middlewares.CorsMiddleware{Handler: authMiddleware}
 
 	err := http.ListenAndServe(":8080", corsMiddleware)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// tokenSecret reads the secret tokens are signed with from TOKEN_SECRET. Without it a random secret is used,
+// so issued tokens stop working when the server restarts.
+func tokenSecret() []byte {
+	if secret := os.Getenv("TOKEN_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+
+	return secret
+}
+
+// debugRoutes lists the registered routes at /api/routes when DEBUG_ROUTES is set.
+func debugRoutes() bool {
+	return os.Getenv("DEBUG_ROUTES") != ""
+}
+
+// chessEngine starts the UCI engine binary at CHESS_ENGINE_PATH for bot games. Without it the native engine plays.
+func chessEngine() engines.Engine {
+	if path := os.Getenv("CHESS_ENGINE_PATH"); path != "" {
+		return engines.NewUciEngine(path)
+	}
+
+	return engines.NewNativeEngine()
+}
+
+// chatFilter censors the words listed in the file at CHAT_BLOCKLIST_PATH, one per line. Without it chat is not filtered.
+func chatFilter() game.ChatFilter {
+	path := os.Getenv("CHAT_BLOCKLIST_PATH")
+	if path == "" {
+		return nil
+	}
+
+	words, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	return game.NewWordFilter(strings.Split(string(words), "\n"))
+}
+
+// disconnectGrace reads from DISCONNECT_GRACE_SECONDS how long a disconnected player may take to come back before
+// the opponent may claim the game. Without it players have a minute.
+func disconnectGrace() time.Duration {
+	seconds := os.Getenv("DISCONNECT_GRACE_SECONDS")
+	if seconds == "" {
+		return game.DefaultDisconnectGrace
+	}
+
+	grace, err := strconv.Atoi(seconds)
+	if err != nil || grace < 0 {
+		panic("invalid DISCONNECT_GRACE_SECONDS")
+	}
+
+	return time.Duration(grace) * time.Second
+}
+
+// turnNotifier tells players of correspondence games that it is their turn. It posts to the webhook at
+// TURN_WEBHOOK_URL or, standing in for mail, writes to a file per player in TURN_MAILBOX_DIR.
+// Without either players are not notified.
+func turnNotifier() notifications.Notifier {
+	if url := os.Getenv("TURN_WEBHOOK_URL"); url != "" {
+		return notifications.NewWebhookNotifier(url)
+	}
+
+	if directory := os.Getenv("TURN_MAILBOX_DIR"); directory != "" {
+		return notifications.NewMailboxNotifier(directory)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package matchmaking
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// the rating window of a ticket grows by widenStep every widenInterval, up to maxRatingWindow
+	widenStep       = 50
+	widenInterval   = 5 * time.Second
+	maxRatingWindow = 1000
+)
+
+// Ticket is a player waiting in the queue for an opponent.
+type Ticket struct {
+	PlayerName   string
+	Token        string
+	ConnectionId string
+	Variant      int
+	TimeControl  game.TimeControl
+	Rated        bool
+	Rating       int
+	RatingRange  int
+
+	enteredAt time.Time
+}
+
+type Match struct {
+	Game  *game.Game
+	White Ticket
+	Black Ticket
+}
+
+type MatchListener func(match Match)
+
+type Queue struct {
+	manager *game.Manager
+
+	tickets   []*Ticket
+	listeners []MatchListener
+	mutex     sync.Mutex
+}
+
+func NewQueue(manager *game.Manager) *Queue {
+	return &Queue{
+		manager: manager,
+	}
+}
+
+// OnMatch registers a listener that is called for every pairing after the game was created.
+func (q *Queue) OnMatch(listener MatchListener) {
+	q.listeners = append(q.listeners, listener)
+}
+
+// Enter puts a ticket into the queue. A player can only wait with one ticket at a time,
+// so an older ticket with the same token is replaced.
+func (q *Queue) Enter(ticket Ticket) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.removeByToken(ticket.Token)
+
+	ticket.enteredAt = time.Now()
+	q.tickets = append(q.tickets, &ticket)
+}
+
+func (q *Queue) Leave(token string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.removeByToken(token)
+}
+
+// LeaveByConnection removes all tickets of a connection, e.g. when it disconnects.
+func (q *Queue) LeaveByConnection(connectionId string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	tickets := q.tickets[:0]
+	for _, ticket := range q.tickets {
+		if ticket.ConnectionId != connectionId {
+			tickets = append(tickets, ticket)
+		}
+	}
+	q.tickets = tickets
+}
+
+func (q *Queue) Size() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.tickets)
+}
+
+// Pair matches waiting tickets, oldest first, with the closest rated compatible opponent and creates their games.
+func (q *Queue) Pair() {
+	q.mutex.Lock()
+
+	var pairs [][2]*Ticket
+	paired := make(map[*Ticket]bool)
+	now := time.Now()
+
+	for i, ticket := range q.tickets {
+		if paired[ticket] {
+			continue
+		}
+
+		var opponent *Ticket
+		for _, candidate := range q.tickets[i+1:] {
+			if paired[candidate] || !isCompatible(ticket, candidate, now) {
+				continue
+			}
+
+			if opponent == nil || abs(candidate.Rating-ticket.Rating) < abs(opponent.Rating-ticket.Rating) {
+				opponent = candidate
+			}
+		}
+
+		if opponent != nil {
+			paired[ticket] = true
+			paired[opponent] = true
+			pairs = append(pairs, [2]*Ticket{ticket, opponent})
+		}
+	}
+
+	tickets := q.tickets[:0]
+	for _, ticket := range q.tickets {
+		if !paired[ticket] {
+			tickets = append(tickets, ticket)
+		}
+	}
+	q.tickets = tickets
+
+	q.mutex.Unlock()
+
+	for _, pair := range pairs {
+		q.createMatch(*pair[0], *pair[1])
+	}
+}
+
+func (q *Queue) createMatch(a Ticket, b Ticket) {
+	white, black := a, b
+	if rand.Intn(2) == 1 {
+		white, black = b, a
+	}
+
+	matchGame := q.manager.NewGame(game.Settings{
+		FirstPlayerColor: constants.White,
+		StartingColor:    constants.White,
+		TimeControl:      a.TimeControl,
+		Rated:            a.Rated,
+	})
+
+	// the first player gets FirstPlayerColor, the second one the other color
+	matchGame.AddPlayer(white.PlayerName, white.Token, "")
+	matchGame.AddPlayer(black.PlayerName, black.Token, "")
+
+	match := Match{
+		Game:  matchGame,
+		White: white,
+		Black: black,
+	}
+
+	for _, listener := range q.listeners {
+		listener(match)
+	}
+}
+
+func isCompatible(a *Ticket, b *Ticket, now time.Time) bool {
+	if a.Token == b.Token {
+		return false
+	}
+
+	if a.Variant != b.Variant || a.TimeControl != b.TimeControl || a.Rated != b.Rated {
+		return false
+	}
+
+	difference := abs(a.Rating - b.Rating)
+
+	return difference <= a.ratingWindow(now) && difference <= b.ratingWindow(now)
+}
+
+// ratingWindow is the rating difference the ticket accepts, widened the longer it waits.
+func (t *Ticket) ratingWindow(now time.Time) int {
+	window := t.RatingRange + widenStep*int(now.Sub(t.enteredAt)/widenInterval)
+	if window > maxRatingWindow {
+		return maxRatingWindow
+	}
+
+	return window
+}
+
+func (q *Queue) removeByToken(token string) bool {
+	for i, ticket := range q.tickets {
+		if ticket.Token == token {
+			q.tickets = append(q.tickets[:i], q.tickets[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
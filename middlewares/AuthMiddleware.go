@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"github.com/racccoooon/chess-be/auth"
+	"net/http"
+	"strings"
+)
+
+// AuthMiddleware verifies the token of a request and stores its identity in the request context.
+// The token is read from the Authorization header or, for SignalR transports that can not set headers,
+// from the access_token query parameter. Requests without a valid token are passed on without an identity and,
+// if a token was sent, with the reason it was rejected, so each handler decides whether it needs an identity.
+type AuthMiddleware struct {
+	Handler http.Handler
+	Issuer  *auth.Issuer
+}
+
+func (a *AuthMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("access_token")
+	if tokenHeader := r.Header.Get("Authorization"); strings.HasPrefix(tokenHeader, "Bearer ") {
+		token = tokenHeader[7:]
+	}
+
+	if token != "" {
+		identity, err := a.Issuer.Verify(token)
+		if err != nil {
+			r = r.WithContext(auth.WithTokenError(r.Context(), err))
+		} else {
+			r = r.WithContext(auth.WithIdentity(r.Context(), identity))
+		}
+	}
+
+	a.Handler.ServeHTTP(w, r)
+}
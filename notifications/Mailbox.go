@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MailboxNotifier stands in for mail. It appends every notification as a mail to a file per player in a directory.
+type MailboxNotifier struct {
+	directory string
+	mutex     sync.Mutex
+}
+
+func NewMailboxNotifier(directory string) *MailboxNotifier {
+	return &MailboxNotifier{directory: directory}
+}
+
+func (n *MailboxNotifier) NotifyTurn(notification TurnNotification) error {
+	deadline := "You are on vacation, so there is no deadline until you are back."
+	if notification.Deadline != nil {
+		deadline = "Please move before " + notification.Deadline.Format(time.RFC1123) + "."
+	}
+
+	mail := fmt.Sprintf("To: %s\nDate: %s\nSubject: Your turn against %s\n\nIt is your turn in game %s after %d moves. %s\n\n",
+		notification.PlayerName, time.Now().Format(time.RFC1123Z), notification.OpponentName,
+		notification.GameId, notification.Moves, deadline)
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if err := os.MkdirAll(n.directory, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(n.directory, filepath.Base(notification.PlayerId)+".txt")
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(mail)
+
+	return err
+}
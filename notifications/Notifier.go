@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"log"
+	"time"
+)
+
+// TurnNotification tells a player that it became their turn in a correspondence game.
+type TurnNotification struct {
+	PlayerId     string     `json:"playerId"`
+	PlayerName   string     `json:"playerName"`
+	GameId       string     `json:"gameId"`
+	Color        string     `json:"color"`
+	OpponentName string     `json:"opponentName"`
+	Moves        int        `json:"moves"`    // the number of moves played so far
+	Deadline     *time.Time `json:"deadline"` // null while the player is on vacation
+}
+
+// Notifier delivers notifications to the players, for example by mail or to a webhook.
+type Notifier interface {
+	NotifyTurn(notification TurnNotification) error
+}
+
+// Dispatcher hands the notifications of games to a notifier. Notifications are sent in the background,
+// so a slow notifier does not hold up the games.
+type Dispatcher struct {
+	notifier Notifier
+}
+
+func NewDispatcher(notifier Notifier) *Dispatcher {
+	return &Dispatcher{notifier: notifier}
+}
+
+func (d *Dispatcher) HandleTurn(g *game.Game, player *game.Player) {
+	notification := TurnNotification{
+		PlayerId:     player.Token(),
+		PlayerName:   player.Name(),
+		GameId:       string(g.Id()),
+		Color:        constants.ColorAsString(player.Color()),
+		OpponentName: g.OpponentName(player.Color()),
+		Moves:        len(g.History()),
+	}
+
+	if deadline, ok := g.Deadline(); ok {
+		notification.Deadline = &deadline
+	}
+
+	go func() {
+		if err := d.notifier.NotifyTurn(notification); err != nil {
+			log.Printf("could not notify %s about game %s: %v", notification.PlayerId, notification.GameId, err)
+		}
+	}()
+}
@@ -0,0 +1,126 @@
+package ratings
+
+import "math"
+
+const (
+	defaultRating     = 1500
+	defaultDeviation  = 350
+	defaultVolatility = 0.06
+
+	// tau constrains how much the volatility can change after a game
+	tau = 0.5
+
+	// glickoScale converts between the Glicko scale and the internal Glicko-2 scale
+	glickoScale = 173.7178
+
+	convergenceTolerance = 0.000001
+
+	// a rating with a deviation above provisionalDeviation is not reliable yet
+	provisionalDeviation = 110
+)
+
+// Rating is a Glicko-2 rating on the Glicko scale, where a new player starts at 1500 with a deviation of 350.
+type Rating struct {
+	rating     float64
+	deviation  float64
+	volatility float64
+}
+
+func NewRating() Rating {
+	return Rating{
+		rating:     defaultRating,
+		deviation:  defaultDeviation,
+		volatility: defaultVolatility,
+	}
+}
+
+func (r Rating) Rating() float64 {
+	return r.rating
+}
+
+func (r Rating) Deviation() float64 {
+	return r.deviation
+}
+
+func (r Rating) Volatility() float64 {
+	return r.volatility
+}
+
+func (r Rating) IsProvisional() bool {
+	return r.deviation > provisionalDeviation
+}
+
+// Update returns the rating after a single game against the opponent, treating the game as its own rating period.
+// The score is 1 for a win, 0.5 for a draw and 0 for a loss.
+func (r Rating) Update(opponent Rating, score float64) Rating {
+	mu := (r.rating - defaultRating) / glickoScale
+	phi := r.deviation / glickoScale
+	opponentMu := (opponent.rating - defaultRating) / glickoScale
+	opponentPhi := opponent.deviation / glickoScale
+
+	g := reduceImpact(opponentPhi)
+	expected := 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+
+	variance := 1 / (g * g * expected * (1 - expected))
+	delta := variance * g * (score - expected)
+
+	volatility := newVolatility(phi, r.volatility, variance, delta)
+
+	preRatingPhi := math.Sqrt(phi*phi + volatility*volatility)
+	newPhi := 1 / math.Sqrt(1/(preRatingPhi*preRatingPhi)+1/variance)
+	newMu := mu + newPhi*newPhi*g*(score-expected)
+
+	return Rating{
+		rating:     newMu*glickoScale + defaultRating,
+		deviation:  math.Min(newPhi*glickoScale, defaultDeviation),
+		volatility: volatility,
+	}
+}
+
+func reduceImpact(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// newVolatility finds the new volatility with the Illinois algorithm as described in the Glicko-2 paper.
+func newVolatility(phi float64, volatility float64, variance float64, delta float64) float64 {
+	a := math.Log(volatility * volatility)
+
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	first := a
+	var second float64
+	if delta*delta > phi*phi+variance {
+		second = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		second = a - k*tau
+	}
+
+	fFirst := f(first)
+	fSecond := f(second)
+
+	for math.Abs(second-first) > convergenceTolerance {
+		c := first + (first-second)*fFirst/(fSecond-fFirst)
+		fC := f(c)
+
+		if fC*fSecond <= 0 {
+			first = second
+			fFirst = fSecond
+		} else {
+			fFirst = fFirst / 2
+		}
+
+		second = c
+		fSecond = fC
+	}
+
+	return math.Exp(first / 2)
+}
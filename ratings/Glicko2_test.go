@@ -0,0 +1,131 @@
+package ratings
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRatingUpdate(t *testing.T) {
+	tests := []struct {
+		name          string
+		player        Rating
+		opponent      Rating
+		score         float64
+		wantRating    float64
+		wantDeviation float64
+	}{
+		// the opponents of the example in the Glicko-2 paper, each game rated as its own period
+		{
+			name:          "win against a weaker, established opponent",
+			player:        Rating{rating: 1500, deviation: 200, volatility: 0.06},
+			opponent:      Rating{rating: 1400, deviation: 30, volatility: 0.06},
+			score:         1,
+			wantRating:    1563.56,
+			wantDeviation: 175.40,
+		},
+		{
+			name:          "loss against a slightly stronger opponent",
+			player:        Rating{rating: 1500, deviation: 200, volatility: 0.06},
+			opponent:      Rating{rating: 1550, deviation: 100, volatility: 0.06},
+			score:         0,
+			wantRating:    1426.69,
+			wantDeviation: 175.90,
+		},
+		{
+			name:          "loss against a much stronger, uncertain opponent",
+			player:        Rating{rating: 1500, deviation: 200, volatility: 0.06},
+			opponent:      Rating{rating: 1700, deviation: 300, volatility: 0.06},
+			score:         0,
+			wantRating:    1455.86,
+			wantDeviation: 186.98,
+		},
+		{
+			name:          "new players, win",
+			player:        NewRating(),
+			opponent:      NewRating(),
+			score:         1,
+			wantRating:    1662.31,
+			wantDeviation: 290.32,
+		},
+		{
+			name:          "new players, draw",
+			player:        NewRating(),
+			opponent:      NewRating(),
+			score:         0.5,
+			wantRating:    1500,
+			wantDeviation: 290.32,
+		},
+		{
+			name:          "new players, loss",
+			player:        NewRating(),
+			opponent:      NewRating(),
+			score:         0,
+			wantRating:    1337.69,
+			wantDeviation: 290.32,
+		},
+		{
+			name:          "upset loss of an established favourite",
+			player:        Rating{rating: 2000, deviation: 50, volatility: 0.06},
+			opponent:      Rating{rating: 1500, deviation: 50, volatility: 0.06},
+			score:         0,
+			wantRating:    1986.05,
+			wantDeviation: 50.96,
+		},
+		{
+			name:          "draw between equal established players",
+			player:        Rating{rating: 1800, deviation: 80, volatility: 0.06},
+			opponent:      Rating{rating: 1800, deviation: 80, volatility: 0.06},
+			score:         0.5,
+			wantRating:    1800,
+			wantDeviation: 78.71,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updated := test.player.Update(test.opponent, test.score)
+
+			if math.Abs(updated.Rating()-test.wantRating) > 0.01 {
+				t.Errorf("rating = %.2f, want %.2f", updated.Rating(), test.wantRating)
+			}
+
+			if math.Abs(updated.Deviation()-test.wantDeviation) > 0.01 {
+				t.Errorf("deviation = %.2f, want %.2f", updated.Deviation(), test.wantDeviation)
+			}
+
+			if math.Abs(updated.Volatility()-test.player.Volatility()) > 0.001 {
+				t.Errorf("volatility = %.5f, changed too much from %.5f", updated.Volatility(), test.player.Volatility())
+			}
+		})
+	}
+}
+
+func TestRatingDeviationIsCapped(t *testing.T) {
+	rating := Rating{rating: 1500, deviation: defaultDeviation, volatility: 0.5}
+
+	updated := rating.Update(NewRating(), 1)
+
+	if updated.Deviation() > defaultDeviation {
+		t.Errorf("deviation = %.2f, want at most %d", updated.Deviation(), defaultDeviation)
+	}
+}
+
+func TestRatingIsProvisional(t *testing.T) {
+	tests := []struct {
+		deviation float64
+		want      bool
+	}{
+		{deviation: defaultDeviation, want: true},
+		{deviation: provisionalDeviation + 1, want: true},
+		{deviation: provisionalDeviation, want: false},
+		{deviation: 50, want: false},
+	}
+
+	for _, test := range tests {
+		rating := Rating{rating: 1500, deviation: test.deviation, volatility: defaultVolatility}
+
+		if got := rating.IsProvisional(); got != test.want {
+			t.Errorf("IsProvisional() with deviation %.0f = %v, want %v", test.deviation, got, test.want)
+		}
+	}
+}
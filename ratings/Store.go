@@ -0,0 +1,266 @@
+package ratings
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HistoryEntry is the rating of a player right after a rated game.
+type HistoryEntry struct {
+	gameId    game.Id
+	rating    float64
+	deviation float64
+	time      time.Time
+}
+
+func (e HistoryEntry) GameId() game.Id {
+	return e.gameId
+}
+
+func (e HistoryEntry) Rating() float64 {
+	return e.rating
+}
+
+func (e HistoryEntry) Deviation() float64 {
+	return e.deviation
+}
+
+func (e HistoryEntry) Time() time.Time {
+	return e.time
+}
+
+// CategoryRating is the rating of a player in one time control category.
+type CategoryRating struct {
+	category int
+	rating   Rating
+	games    int
+	history  []HistoryEntry
+}
+
+func (c CategoryRating) Category() int {
+	return c.category
+}
+
+func (c CategoryRating) Rating() Rating {
+	return c.rating
+}
+
+func (c CategoryRating) Games() int {
+	return c.games
+}
+
+func (c CategoryRating) History() []HistoryEntry {
+	return c.history
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 100
+)
+
+// LeaderboardEntry is the rank of a player in the leaderboard of a category.
+type LeaderboardEntry struct {
+	rank     int
+	playerId string
+	rating   Rating
+	games    int
+}
+
+func (e LeaderboardEntry) Rank() int {
+	return e.rank
+}
+
+func (e LeaderboardEntry) PlayerId() string {
+	return e.playerId
+}
+
+func (e LeaderboardEntry) Rating() Rating {
+	return e.rating
+}
+
+func (e LeaderboardEntry) Games() int {
+	return e.games
+}
+
+type Store struct {
+	players map[string]map[int]*CategoryRating
+	mutex   sync.RWMutex
+}
+
+func NewStore() *Store {
+	return &Store{
+		players: make(map[string]map[int]*CategoryRating),
+	}
+}
+
+// GetRating returns the rating of the player in the category, or the rating of a new player if they have not
+// played a rated game in it yet.
+func (s *Store) GetRating(playerId string, category int) Rating {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if categoryRating, ok := s.players[playerId][category]; ok {
+		return categoryRating.rating
+	}
+
+	return NewRating()
+}
+
+// GetPlayerRatings returns the ratings of all categories the player has played rated games in.
+func (s *Store) GetPlayerRatings(playerId string) []CategoryRating {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var categoryRatings []CategoryRating
+	for _, categoryRating := range s.players[playerId] {
+		categoryRatings = append(categoryRatings, CategoryRating{
+			category: categoryRating.category,
+			rating:   categoryRating.rating,
+			games:    categoryRating.games,
+			history:  append([]HistoryEntry(nil), categoryRating.history...),
+		})
+	}
+
+	sort.Slice(categoryRatings, func(i, j int) bool {
+		return categoryRatings[i].category < categoryRatings[j].category
+	})
+
+	return categoryRatings
+}
+
+// GetLeaderboard returns one page of the players of the category ordered by rating and the cursor of the next page,
+// which is empty on the last page. Players with a provisional rating are not ranked.
+func (s *Store) GetLeaderboard(category int, cursor string, limit int) ([]LeaderboardEntry, string, error) {
+	offset := 0
+	if cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		offset, err = strconv.Atoi(string(decoded))
+		if err != nil || offset < 0 {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+
+	var entries []LeaderboardEntry
+
+	s.mutex.RLock()
+	for playerId, categoryRatings := range s.players {
+		if categoryRating, ok := categoryRatings[category]; ok && !categoryRating.rating.IsProvisional() {
+			entries = append(entries, LeaderboardEntry{
+				playerId: playerId,
+				rating:   categoryRating.rating,
+				games:    categoryRating.games,
+			})
+		}
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].rating.rating != entries[j].rating.rating {
+			return entries[i].rating.rating > entries[j].rating.rating
+		}
+
+		return entries[i].playerId < entries[j].playerId
+	})
+
+	for i := range entries {
+		entries[i].rank = i + 1
+	}
+
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit > maxLeaderboardLimit {
+		limit = maxLeaderboardLimit
+	}
+
+	if offset >= len(entries) {
+		return nil, "", nil
+	}
+
+	entries = entries[offset:]
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+
+	nextCursor := base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset + limit)))
+
+	return entries[:limit], nextCursor, nil
+}
+
+// RateGame updates the ratings of both players after a rated game ended. It is meant to be registered as
+// a game ended listener of the manager.
+func (s *Store) RateGame(g *game.Game) {
+	if !g.IsRated() || !g.IsOver() {
+		return
+	}
+
+	white := g.GetPlayerByColor(constants.White)
+	black := g.GetPlayerByColor(constants.Black)
+	if white == nil || black == nil {
+		return
+	}
+
+	var whiteScore float64
+	switch g.Result() {
+	case constants.WhiteWon:
+		whiteScore = 1
+	case constants.Drawn:
+		whiteScore = 0.5
+	}
+
+	category := g.TimeControl().Category()
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	whiteRating := s.categoryRating(white.Token(), category)
+	blackRating := s.categoryRating(black.Token(), category)
+
+	// both players are rated against the rating their opponent had before the game
+	whiteBefore, blackBefore := whiteRating.rating, blackRating.rating
+	whiteRating.record(g.Id(), whiteBefore.Update(blackBefore, whiteScore), now)
+	blackRating.record(g.Id(), blackBefore.Update(whiteBefore, 1-whiteScore), now)
+}
+
+func (s *Store) categoryRating(playerId string, category int) *CategoryRating {
+	categoryRatings, ok := s.players[playerId]
+	if !ok {
+		categoryRatings = make(map[int]*CategoryRating)
+		s.players[playerId] = categoryRatings
+	}
+
+	categoryRating, ok := categoryRatings[category]
+	if !ok {
+		categoryRating = &CategoryRating{
+			category: category,
+			rating:   NewRating(),
+		}
+		categoryRatings[category] = categoryRating
+	}
+
+	return categoryRating
+}
+
+func (c *CategoryRating) record(gameId game.Id, rating Rating, time time.Time) {
+	c.rating = rating
+	c.games++
+	c.history = append(c.history, HistoryEntry{
+		gameId:    gameId,
+		rating:    rating.rating,
+		deviation: rating.deviation,
+		time:      time,
+	})
+}
@@ -0,0 +1,223 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Router matches requests against a table of routes made of a method and a path pattern.
+//
+// Patterns consist of literal segments and parameters, e.g. "/api/games/{gameId}/validmoves/{fromX:int}/{fromY:int}".
+// Parameters without a type match any non-empty segment, parameters of type int only match integers.
+// Routes are registered at startup and only read afterwards, so the router can serve requests concurrently.
+type Router struct {
+	routes []*route
+}
+
+type HandlerFunc func(w http.ResponseWriter, r *http.Request, params Params)
+
+type Params struct {
+	strings map[string]string
+	ints    map[string]int
+}
+
+func (p Params) String(name string) string {
+	return p.strings[name]
+}
+
+func (p Params) Int(name string) int {
+	return p.ints[name]
+}
+
+type Route struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+type route struct {
+	method   string
+	pattern  string
+	segments []segment
+	handler  HandlerFunc
+}
+
+const (
+	literalSegment = 0
+	stringSegment  = 1
+	intSegment     = 2
+)
+
+type segment struct {
+	kind  int
+	value string
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+func (router *Router) Handle(method string, pattern string, handler HandlerFunc) {
+	router.routes = append(router.routes, &route{
+		method:   method,
+		pattern:  pattern,
+		segments: parsePattern(pattern),
+		handler:  handler,
+	})
+}
+
+// HandleHttp registers a plain http.Handler that does not need path parameters.
+func (router *Router) HandleHttp(method string, pattern string, handler http.Handler) {
+	router.Handle(method, pattern, func(w http.ResponseWriter, r *http.Request, params Params) {
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (router *Router) Routes() []Route {
+	routes := make([]Route, len(router.routes))
+
+	for i, route := range router.routes {
+		routes[i] = Route{
+			Method:  route.method,
+			Pattern: route.pattern,
+		}
+	}
+
+	return routes
+}
+
+// ListRoutes is a handler that lists all registered routes for debugging.
+func (router *Router) ListRoutes(w http.ResponseWriter, r *http.Request, params Params) {
+	responseMessage, err := json.Marshal(router.Routes())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseMessage)
+}
+
+func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pathSegments := splitPath(r.URL.Path)
+
+	var allowedMethods []string
+
+	for _, route := range router.routes {
+		params, ok := route.match(pathSegments)
+		if !ok {
+			continue
+		}
+
+		if route.method == r.Method {
+			route.handler(w, r, params)
+			return
+		}
+
+		allowedMethods = appendUnique(allowedMethods, route.method)
+	}
+
+	if len(allowedMethods) > 0 {
+		sort.Strings(allowedMethods)
+		w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (route *route) match(pathSegments []string) (Params, bool) {
+	params := Params{}
+
+	if len(pathSegments) != len(route.segments) {
+		return params, false
+	}
+
+	for i, segment := range route.segments {
+		value := pathSegments[i]
+
+		switch segment.kind {
+		case literalSegment:
+			if value != segment.value {
+				return params, false
+			}
+
+		case stringSegment:
+			if value == "" {
+				return params, false
+			}
+
+			if params.strings == nil {
+				params.strings = map[string]string{}
+			}
+			params.strings[segment.value] = value
+
+		case intSegment:
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return params, false
+			}
+
+			if params.ints == nil {
+				params.ints = map[string]int{}
+			}
+			params.ints[segment.value] = number
+		}
+	}
+
+	return params, true
+}
+
+func parsePattern(pattern string) []segment {
+	var segments []segment
+
+	for _, part := range splitPath(pattern) {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			segments = append(segments, segment{kind: literalSegment, value: part})
+			continue
+		}
+
+		name := part[1 : len(part)-1]
+		kind := stringSegment
+
+		if index := strings.Index(name, ":"); index >= 0 {
+			switch name[index+1:] {
+			case "int":
+				kind = intSegment
+			case "string":
+				kind = stringSegment
+			default:
+				panic("invalid parameter type in pattern " + pattern)
+			}
+
+			name = name[:index]
+		}
+
+		segments = append(segments, segment{kind: kind, value: name})
+	}
+
+	return segments
+}
+
+// splitPath splits a path into its segments, ignoring leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
@@ -0,0 +1,179 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestRouter(matched *string, params *Params) *Router {
+	router := NewRouter()
+
+	for _, r := range []Route{
+		{Method: http.MethodGet, Pattern: "/api/games"},
+		{Method: http.MethodPost, Pattern: "/api/games"},
+		{Method: http.MethodGet, Pattern: "/api/games/{gameId}"},
+		{Method: http.MethodDelete, Pattern: "/api/games/{gameId}"},
+		{Method: http.MethodGet, Pattern: "/api/games/{gameId}/validmoves/{fromX:int}/{fromY:int}"},
+		{Method: http.MethodGet, Pattern: "/api/tournaments/{tournamentId:string}/rounds/{round:int}"},
+	} {
+		pattern := r.Method + " " + r.Pattern
+		router.Handle(r.Method, r.Pattern, func(w http.ResponseWriter, r *http.Request, p Params) {
+			*matched = pattern
+			*params = p
+		})
+	}
+
+	return router
+}
+
+func TestRouterMatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		status  int
+		pattern string
+		strings map[string]string
+		ints    map[string]int
+		allow   string
+	}{
+		{
+			name:    "literal route",
+			method:  http.MethodGet,
+			path:    "/api/games",
+			status:  http.StatusOK,
+			pattern: "GET /api/games",
+		},
+		{
+			name:    "method selects the route",
+			method:  http.MethodPost,
+			path:    "/api/games",
+			status:  http.StatusOK,
+			pattern: "POST /api/games",
+		},
+		{
+			name:    "trailing slash is ignored",
+			method:  http.MethodGet,
+			path:    "/api/games/",
+			status:  http.StatusOK,
+			pattern: "GET /api/games",
+		},
+		{
+			name:    "string parameter",
+			method:  http.MethodGet,
+			path:    "/api/games/abc123",
+			status:  http.StatusOK,
+			pattern: "GET /api/games/{gameId}",
+			strings: map[string]string{"gameId": "abc123"},
+		},
+		{
+			name:    "int parameters",
+			method:  http.MethodGet,
+			path:    "/api/games/abc123/validmoves/4/-1",
+			status:  http.StatusOK,
+			pattern: "GET /api/games/{gameId}/validmoves/{fromX:int}/{fromY:int}",
+			strings: map[string]string{"gameId": "abc123"},
+			ints:    map[string]int{"fromX": 4, "fromY": -1},
+		},
+		{
+			name:    "explicit string parameter",
+			method:  http.MethodGet,
+			path:    "/api/tournaments/t1/rounds/3",
+			status:  http.StatusOK,
+			pattern: "GET /api/tournaments/{tournamentId:string}/rounds/{round:int}",
+			strings: map[string]string{"tournamentId": "t1"},
+			ints:    map[string]int{"round": 3},
+		},
+		{
+			name:   "int parameter does not match text",
+			method: http.MethodGet,
+			path:   "/api/games/abc123/validmoves/e/2",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "empty parameter does not match",
+			method: http.MethodGet,
+			path:   "/api/games//validmoves/4/1",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "too many segments",
+			method: http.MethodGet,
+			path:   "/api/games/abc123/moves/extra",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unknown path",
+			method: http.MethodGet,
+			path:   "/api/unknown",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "wrong method on a literal route",
+			method: http.MethodDelete,
+			path:   "/api/games",
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET, POST",
+		},
+		{
+			name:   "wrong method on a parameter route",
+			method: http.MethodPut,
+			path:   "/api/games/abc123",
+			status: http.StatusMethodNotAllowed,
+			allow:  "DELETE, GET",
+		},
+		{
+			name:   "wrong method on an int route",
+			method: http.MethodPost,
+			path:   "/api/tournaments/t1/rounds/1",
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var matched string
+			var params Params
+			router := newTestRouter(&matched, &params)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.status)
+			}
+
+			if matched != test.pattern {
+				t.Errorf("matched %q, want %q", matched, test.pattern)
+			}
+
+			if allow := recorder.Header().Get("Allow"); allow != test.allow {
+				t.Errorf("Allow = %q, want %q", allow, test.allow)
+			}
+
+			for name, want := range test.strings {
+				if got := params.String(name); got != want {
+					t.Errorf("param %s = %q, want %q", name, got, want)
+				}
+			}
+
+			for name, want := range test.ints {
+				if got := params.Int(name); got != want {
+					t.Errorf("param %s = %d, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePatternPanicsOnUnknownType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unknown parameter type")
+		}
+	}()
+
+	parsePattern("/api/games/{gameId:uuid}")
+}
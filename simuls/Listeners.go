@@ -0,0 +1,15 @@
+package simuls
+
+type SimulListener func(simul *Simul)
+
+// OnSimulUpdated registers a listener that is called after players joined or withdrew, the simul started
+// or a board finished. It is called without holding the lock of the simul.
+func (m *Manager) OnSimulUpdated(listener SimulListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (s *Simul) notifyUpdated() {
+	for _, listener := range s.manager.listeners {
+		listener(s)
+	}
+}
@@ -0,0 +1,123 @@
+package simuls
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Manager struct {
+	games     *game.Manager
+	simuls    map[Id]*Simul
+	byGame    map[game.Id]*Simul
+	listeners []SimulListener
+	mutex     sync.RWMutex
+}
+
+func NewManager(games *game.Manager) *Manager {
+	return &Manager{
+		games:  games,
+		simuls: make(map[Id]*Simul),
+		byGame: make(map[game.Id]*Simul),
+	}
+}
+
+func (m *Manager) NewSimul(settings Settings) *Simul {
+	simul := &Simul{
+		settings:   settings,
+		createTime: time.Now(),
+		manager:    m,
+	}
+
+	m.mutex.Lock()
+	simul.id = m.newSimulId()
+	m.simuls[simul.id] = simul
+	m.mutex.Unlock()
+
+	return simul
+}
+
+func (m *Manager) GetSimul(id Id) *Simul {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.simuls[id]
+}
+
+// GetSimulByGame returns the simul a game is played in, or nil if it is not a simul game.
+func (m *Manager) GetSimulByGame(gameId game.Id) *Simul {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.byGame[gameId]
+}
+
+// GetSimuls returns all simuls, newest first.
+func (m *Manager) GetSimuls() []*Simul {
+	m.mutex.RLock()
+	simuls := make([]*Simul, 0, len(m.simuls))
+	for _, simul := range m.simuls {
+		simuls = append(simuls, simul)
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(simuls, func(i, j int) bool {
+		return simuls[i].createTime.After(simuls[j].createTime)
+	})
+
+	return simuls
+}
+
+// RecordResult updates the board of the game if it belongs to a simul. It is meant to be registered as a game
+// ended listener of the game manager.
+func (m *Manager) RecordResult(g *game.Game) {
+	simul := m.GetSimulByGame(g.Id())
+	if simul == nil {
+		return
+	}
+
+	simul.mutex.Lock()
+	simul.recordResult(g.Id(), g.Result())
+	simul.mutex.Unlock()
+
+	simul.notifyUpdated()
+}
+
+// HandleGameRemoved scores a removed board that did not end as a draw, so the simul can finish. It is meant
+// to be registered as a game removed listener of the game manager.
+func (m *Manager) HandleGameRemoved(g *game.Game) {
+	simul := m.GetSimulByGame(g.Id())
+	if simul == nil || g.IsOver() {
+		return
+	}
+
+	simul.mutex.Lock()
+	simul.recordResult(g.Id(), constants.Drawn)
+	simul.mutex.Unlock()
+
+	simul.notifyUpdated()
+}
+
+func (m *Manager) link(gameId game.Id, simul *Simul) {
+	m.mutex.Lock()
+	m.byGame[gameId] = simul
+	m.mutex.Unlock()
+}
+
+func (m *Manager) newSimulId() Id {
+	var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+	for {
+		b := make([]rune, 8)
+		for i := range b {
+			b[i] = letters[rand.Intn(len(letters))]
+		}
+
+		if _, ok := m.simuls[Id(b)]; !ok {
+			return Id(b)
+		}
+	}
+}
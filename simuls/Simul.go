@@ -0,0 +1,283 @@
+package simuls
+
+import (
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotOpen        = errors.New("the simul does not accept players anymore")
+	ErrAlreadyJoined  = errors.New("the player already joined")
+	ErrNotJoined      = errors.New("the player did not join")
+	ErrHostCannotJoin = errors.New("the host can not play against themselves")
+	ErrSimulFull      = errors.New("the simul is full")
+	ErrNoPlayers      = errors.New("a simul needs at least one player")
+	ErrAlreadyStarted = errors.New("the simul was already started")
+)
+
+type Id string
+
+// Settings describe a simul when it is created. The host plays every board with HostColor.
+type Settings struct {
+	Name        string
+	HostId      string
+	HostName    string
+	HostColor   int
+	TimeControl game.TimeControl
+	MaxPlayers  int // 0 for no limit
+}
+
+// Simul is a simultaneous exhibition, in which one host plays a game against every participant at once.
+type Simul struct {
+	id       Id
+	settings Settings
+
+	status     int
+	createTime time.Time
+
+	participants []*Participant
+	boards       []*Board
+
+	manager *Manager
+	mutex   sync.Mutex
+}
+
+type Participant struct {
+	id   string
+	name string
+}
+
+func (p *Participant) Id() string {
+	return p.id
+}
+
+func (p *Participant) Name() string {
+	return p.name
+}
+
+// Board is the game of the host against one participant.
+type Board struct {
+	participant *Participant
+	gameId      game.Id
+	result      int
+}
+
+func (b *Board) Participant() *Participant {
+	return b.participant
+}
+
+func (b *Board) GameId() game.Id {
+	return b.gameId
+}
+
+func (b *Board) Result() int {
+	return b.result
+}
+
+func (s *Simul) Id() Id {
+	return s.id
+}
+
+func (s *Simul) Name() string {
+	return s.settings.Name
+}
+
+func (s *Simul) HostId() string {
+	return s.settings.HostId
+}
+
+func (s *Simul) HostName() string {
+	return s.settings.HostName
+}
+
+func (s *Simul) HostColor() int {
+	return s.settings.HostColor
+}
+
+func (s *Simul) TimeControl() game.TimeControl {
+	return s.settings.TimeControl
+}
+
+func (s *Simul) MaxPlayers() int {
+	return s.settings.MaxPlayers
+}
+
+func (s *Simul) CreateTime() time.Time {
+	return s.createTime
+}
+
+func (s *Simul) Status() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.status
+}
+
+func (s *Simul) Participants() []Participant {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	participants := make([]Participant, len(s.participants))
+	for i, participant := range s.participants {
+		participants[i] = *participant
+	}
+
+	return participants
+}
+
+func (s *Simul) Boards() []Board {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	boards := make([]Board, len(s.boards))
+	for i, board := range s.boards {
+		boards[i] = *board
+	}
+
+	return boards
+}
+
+// Join registers a player for a board while the simul has not started.
+func (s *Simul) Join(playerId string, name string) error {
+	s.mutex.Lock()
+	err := s.join(playerId, name)
+	s.mutex.Unlock()
+
+	if err == nil {
+		s.notifyUpdated()
+	}
+
+	return err
+}
+
+func (s *Simul) join(playerId string, name string) error {
+	if s.status != constants.Registration {
+		return ErrNotOpen
+	}
+
+	if playerId == s.settings.HostId {
+		return ErrHostCannotJoin
+	}
+
+	if s.participant(playerId) != nil {
+		return ErrAlreadyJoined
+	}
+
+	if s.settings.MaxPlayers > 0 && len(s.participants) >= s.settings.MaxPlayers {
+		return ErrSimulFull
+	}
+
+	s.participants = append(s.participants, &Participant{id: playerId, name: name})
+
+	return nil
+}
+
+// Withdraw removes a player before the simul starts.
+func (s *Simul) Withdraw(playerId string) error {
+	s.mutex.Lock()
+	err := s.withdraw(playerId)
+	s.mutex.Unlock()
+
+	if err == nil {
+		s.notifyUpdated()
+	}
+
+	return err
+}
+
+func (s *Simul) withdraw(playerId string) error {
+	if s.status != constants.Registration {
+		return ErrNotOpen
+	}
+
+	for i, participant := range s.participants {
+		if participant.id == playerId {
+			s.participants = append(s.participants[:i], s.participants[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNotJoined
+}
+
+// Start creates a game against every participant, in which the host has the configured color.
+func (s *Simul) Start() error {
+	s.mutex.Lock()
+	err := s.start()
+	s.mutex.Unlock()
+
+	if err == nil {
+		s.notifyUpdated()
+	}
+
+	return err
+}
+
+func (s *Simul) start() error {
+	if s.status != constants.Registration {
+		return ErrAlreadyStarted
+	}
+
+	if len(s.participants) == 0 {
+		return ErrNoPlayers
+	}
+
+	s.status = constants.Running
+
+	for _, participant := range s.participants {
+		boardGame := s.manager.games.NewGame(game.Settings{
+			FirstPlayerColor: s.settings.HostColor,
+			StartingColor:    constants.White,
+			TimeControl:      s.settings.TimeControl,
+		})
+
+		// the first player gets FirstPlayerColor, the second one the other color
+		boardGame.AddPlayer(s.settings.HostName, s.settings.HostId, "")
+		boardGame.AddPlayer(participant.name, participant.id, "")
+
+		s.boards = append(s.boards, &Board{participant: participant, gameId: boardGame.Id()})
+		s.manager.link(boardGame.Id(), s)
+	}
+
+	return nil
+}
+
+// IsMember tells whether the player hosts the simul or plays in it.
+func (s *Simul) IsMember(playerId string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return playerId == s.settings.HostId || s.participant(playerId) != nil
+}
+
+func (s *Simul) participant(playerId string) *Participant {
+	for _, participant := range s.participants {
+		if participant.id == playerId {
+			return participant
+		}
+	}
+
+	return nil
+}
+
+// recordResult remembers the result of a board and finishes the simul after the last game.
+func (s *Simul) recordResult(gameId game.Id, result int) {
+	finished := true
+
+	for _, board := range s.boards {
+		if board.gameId == gameId {
+			board.result = result
+		}
+
+		if board.result == constants.InProgress {
+			finished = false
+		}
+	}
+
+	if finished {
+		s.status = constants.Finished
+	}
+}
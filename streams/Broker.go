@@ -0,0 +1,139 @@
+package streams
+
+import (
+	"github.com/racccoooon/chess-be/game"
+	"sync"
+)
+
+// subscriptionBuffer is how many events a slow client may fall behind before further events are dropped.
+// The subscription is done after a drop, so the client can catch up by reading the state directly.
+const subscriptionBuffer = 64
+
+// Broker delivers events to the clients of the streaming api, either to everything a player is interested in
+// or to everyone following a game.
+type Broker struct {
+	players map[string]map[*Subscription]bool
+	games   map[game.Id]map[*Subscription]bool
+	mutex   sync.RWMutex
+}
+
+type Subscription struct {
+	events chan interface{}
+	done   chan struct{}
+	finish sync.Once
+	player string
+	gameId game.Id
+}
+
+func newSubscription(player string, gameId game.Id) *Subscription {
+	return &Subscription{
+		events: make(chan interface{}, subscriptionBuffer),
+		done:   make(chan struct{}),
+		player: player,
+		gameId: gameId,
+	}
+}
+
+func (s *Subscription) Events() <-chan interface{} {
+	return s.events
+}
+
+// Done is closed when the subscription dropped an event or its game ended or was removed.
+// No further events are guaranteed to arrive afterwards.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		players: make(map[string]map[*Subscription]bool),
+		games:   make(map[game.Id]map[*Subscription]bool),
+	}
+}
+
+// SubscribePlayer subscribes to the events of the player with the given token, like games starting and finishing.
+func (b *Broker) SubscribePlayer(token string) *Subscription {
+	subscription := newSubscription(token, "")
+
+	b.mutex.Lock()
+	if b.players[token] == nil {
+		b.players[token] = make(map[*Subscription]bool)
+	}
+	b.players[token][subscription] = true
+	b.mutex.Unlock()
+
+	return subscription
+}
+
+// SubscribeGame subscribes to the state and the chat of a game.
+func (b *Broker) SubscribeGame(id game.Id) *Subscription {
+	subscription := newSubscription("", id)
+
+	b.mutex.Lock()
+	if b.games[id] == nil {
+		b.games[id] = make(map[*Subscription]bool)
+	}
+	b.games[id][subscription] = true
+	b.mutex.Unlock()
+
+	return subscription
+}
+
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if subscription.player != "" {
+		delete(b.players[subscription.player], subscription)
+		if len(b.players[subscription.player]) == 0 {
+			delete(b.players, subscription.player)
+		}
+	} else {
+		delete(b.games[subscription.gameId], subscription)
+		if len(b.games[subscription.gameId]) == 0 {
+			delete(b.games, subscription.gameId)
+		}
+	}
+}
+
+func (b *Broker) PublishToPlayer(token string, event interface{}) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for subscription := range b.players[token] {
+		subscription.send(event)
+	}
+}
+
+func (b *Broker) PublishToGame(id game.Id, event interface{}) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for subscription := range b.games[id] {
+		subscription.send(event)
+	}
+}
+
+// endGame finishes the subscriptions of a game that ended or was removed.
+func (b *Broker) endGame(id game.Id) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for subscription := range b.games[id] {
+		subscription.end()
+	}
+}
+
+func (s *Subscription) send(event interface{}) {
+	select {
+	case s.events <- event:
+	default:
+		s.end()
+	}
+}
+
+func (s *Subscription) end() {
+	s.finish.Do(func() {
+		close(s.done)
+	})
+}
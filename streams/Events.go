@@ -0,0 +1,238 @@
+package streams
+
+import (
+	"github.com/racccoooon/chess-be/challenges"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"github.com/racccoooon/chess-be/history"
+	"strings"
+)
+
+type GameEvent struct {
+	Type string     `json:"type"` // gameStart or gameFinish
+	Game GameSketch `json:"game"`
+}
+
+// GameSketch is what a player needs to know about one of their games to decide whether to follow it.
+type GameSketch struct {
+	Id          string `json:"id"`
+	Color       string `json:"color"`
+	Opponent    string `json:"opponent"`
+	Rated       bool   `json:"rated"`
+	Result      string `json:"result"`
+	Termination string `json:"termination"`
+}
+
+type GameFullEvent struct {
+	Type        string               `json:"type"` // gameFull
+	Id          string               `json:"id"`
+	White       PlayerResponse       `json:"white"`
+	Black       PlayerResponse       `json:"black"`
+	InitialFen  string               `json:"initialFen"`
+	Rated       bool                 `json:"rated"`
+	TimeControl *TimeControlResponse `json:"timeControl"` // null for games without clocks
+	State       GameStateEvent       `json:"state"`
+}
+
+type PlayerResponse struct {
+	Name string `json:"name"`
+}
+
+type TimeControlResponse struct {
+	InitialSeconds   int `json:"initialSeconds"`
+	IncrementSeconds int `json:"incrementSeconds"`
+}
+
+type GameStateEvent struct {
+	Type          string  `json:"type"`  // gameState
+	Moves         string  `json:"moves"` // all moves in coordinate notation, separated by spaces
+	WhiteTimeMs   *int64  `json:"whiteTimeMs"`
+	BlackTimeMs   *int64  `json:"blackTimeMs"`
+	Result        string  `json:"result"`
+	Termination   string  `json:"termination"`
+	DrawOfferedBy *string `json:"drawOfferedBy"`
+}
+
+type ChatLineEvent struct {
+	Type  string `json:"type"` // chatLine
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Text  string `json:"text"`
+}
+
+func GameStart(g *game.Game, color int) GameEvent {
+	return GameEvent{Type: "gameStart", Game: gameAsSketch(g, color)}
+}
+
+func GameFinish(g *game.Game, color int) GameEvent {
+	return GameEvent{Type: "gameFinish", Game: gameAsSketch(g, color)}
+}
+
+func gameAsSketch(g *game.Game, color int) GameSketch {
+	return GameSketch{
+		Id:          string(g.Id()),
+		Color:       constants.ColorAsString(color),
+		Opponent:    g.OpponentName(color),
+		Rated:       g.IsRated(),
+		Result:      constants.ResultAsString(g.Result()),
+		Termination: constants.TerminationAsString(g.Termination()),
+	}
+}
+
+func GameFull(g *game.Game) GameFullEvent {
+	event := GameFullEvent{
+		Type:       "gameFull",
+		Id:         string(g.Id()),
+		White:      PlayerResponse{Name: g.OpponentName(constants.Black)},
+		Black:      PlayerResponse{Name: g.OpponentName(constants.White)},
+		InitialFen: g.InitialFen(),
+		Rated:      g.IsRated(),
+		State:      GameState(g),
+	}
+
+	if !g.TimeControl().IsUnlimited() {
+		event.TimeControl = &TimeControlResponse{
+			InitialSeconds:   int(g.TimeControl().Initial().Seconds()),
+			IncrementSeconds: int(g.TimeControl().Increment().Seconds()),
+		}
+	}
+
+	return event
+}
+
+func GameState(g *game.Game) GameStateEvent {
+	moves := make([]string, len(g.History()))
+	for i, move := range g.History() {
+		moves[i] = history.MoveAsCoordinates(move)
+	}
+
+	event := GameStateEvent{
+		Type:        "gameState",
+		Moves:       strings.Join(moves, " "),
+		Result:      constants.ResultAsString(g.Result()),
+		Termination: constants.TerminationAsString(g.Termination()),
+	}
+
+	if !g.TimeControl().IsUnlimited() {
+		whiteTimeMs := g.TimeLeft(constants.White).Milliseconds()
+		blackTimeMs := g.TimeLeft(constants.Black).Milliseconds()
+		event.WhiteTimeMs = &whiteTimeMs
+		event.BlackTimeMs = &blackTimeMs
+	}
+
+	if color, ok := g.DrawOfferedBy(); ok {
+		drawOfferedBy := constants.ColorAsString(color)
+		event.DrawOfferedBy = &drawOfferedBy
+	}
+
+	return event
+}
+
+// HandlePlayerJoined tells both players that their game started once the second player took a seat.
+func (b *Broker) HandlePlayerJoined(g *game.Game, _ *game.Player) {
+	if g.PlayerCount() != 2 {
+		return
+	}
+
+	for _, color := range []int{constants.White, constants.Black} {
+		if player := g.GetPlayerByColor(color); player != nil {
+			b.PublishToPlayer(player.Token(), GameStart(g, color))
+		}
+	}
+}
+
+func (b *Broker) HandleGameEnded(g *game.Game) {
+	b.PublishToGame(g.Id(), GameState(g))
+	b.endGame(g.Id())
+
+	for _, color := range []int{constants.White, constants.Black} {
+		if player := g.GetPlayerByColor(color); player != nil {
+			b.PublishToPlayer(player.Token(), GameFinish(g, color))
+		}
+	}
+}
+
+func (b *Broker) HandleGameRemoved(g *game.Game) {
+	b.endGame(g.Id())
+}
+
+func (b *Broker) HandleMove(g *game.Game, _ game.Move) {
+	b.PublishToGame(g.Id(), GameState(g))
+}
+
+func (b *Broker) HandleDrawOffered(g *game.Game, _ int) {
+	b.PublishToGame(g.Id(), GameState(g))
+}
+
+// HandleChat forwards the messages of the player room, bots do not see the spectators talk.
+func (b *Broker) HandleChat(g *game.Game, message game.ChatMessage) {
+	if message.Room() != constants.PlayerRoom {
+		return
+	}
+
+	b.PublishToGame(g.Id(), ChatLineEvent{
+		Type:  "chatLine",
+		Name:  message.Name(),
+		Color: constants.ColorAsString(message.Color()),
+		Text:  message.Text(),
+	})
+}
+
+func (b *Broker) HandleBerserk(g *game.Game, _ int) {
+	b.PublishToGame(g.Id(), GameState(g))
+}
+
+type ChallengeEvent struct {
+	Type      string            `json:"type"` // challenge
+	Challenge ChallengeResponse `json:"challenge"`
+}
+
+type ChallengeResponse struct {
+	Id          string               `json:"id"`
+	Challenger  PlayerResponse       `json:"challenger"`
+	Destination PlayerResponse       `json:"destination"`
+	Color       string               `json:"color"` // the color of the challenger, or randomColor
+	Variant     string               `json:"variant"`
+	TimeControl *TimeControlResponse `json:"timeControl"` // null for games without clocks
+	Rated       bool                 `json:"rated"`
+	Status      string               `json:"status"`
+	GameId      *string              `json:"gameId"` // set once the challenge was accepted
+}
+
+func ChallengeUpdate(challenge *challenges.Challenge) ChallengeEvent {
+	event := ChallengeEvent{
+		Type: "challenge",
+		Challenge: ChallengeResponse{
+			Id:          string(challenge.Id()),
+			Challenger:  PlayerResponse{Name: challenge.ChallengerName()},
+			Destination: PlayerResponse{Name: challenge.DestinationName()},
+			Color:       constants.ColorAsString(challenge.Color()),
+			Variant:     constants.VariantAsString(challenge.Variant()),
+			Rated:       challenge.IsRated(),
+			Status:      constants.ChallengeStatusAsString(challenge.Status()),
+		},
+	}
+
+	if !challenge.TimeControl().IsUnlimited() {
+		event.Challenge.TimeControl = &TimeControlResponse{
+			InitialSeconds:   int(challenge.TimeControl().Initial().Seconds()),
+			IncrementSeconds: int(challenge.TimeControl().Increment().Seconds()),
+		}
+	}
+
+	if challenge.GameId() != "" {
+		gameId := string(challenge.GameId())
+		event.Challenge.GameId = &gameId
+	}
+
+	return event
+}
+
+// HandleChallengeUpdated sends every change of a challenge to both sides. A bot accepts a challenge by
+// answering it over the challenge api, which starts the game.
+func (b *Broker) HandleChallengeUpdated(challenge *challenges.Challenge) {
+	event := ChallengeUpdate(challenge)
+
+	b.PublishToPlayer(challenge.ChallengerId(), event)
+	b.PublishToPlayer(challenge.DestinationId(), event)
+}
@@ -0,0 +1,73 @@
+package tournaments
+
+// knockoutRounds is the number of rounds until one player is left.
+func knockoutRounds(players int) int {
+	rounds := 0
+	for size := 1; size < players; size *= 2 {
+		rounds++
+	}
+
+	return rounds
+}
+
+// bracketOrder returns the seeds in the order they are placed in a bracket of the given size,
+// so the best seeds can only meet in the last rounds, e.g. 1, 8, 4, 5, 2, 7, 3, 6 for eight players.
+func bracketOrder(size int) []int {
+	order := []int{1}
+
+	for length := 2; length <= size; length *= 2 {
+		next := make([]int, 0, length)
+		for _, seed := range order {
+			next = append(next, seed, length+1-seed)
+		}
+		order = next
+	}
+
+	return order
+}
+
+// pairKnockout pairs the first round by the bracket order of the seeds, where seeds without an opponent get
+// a bye, and every later round with the winners of neighbouring matches. The higher seed has white.
+func pairKnockout(participants []*Participant, rounds [][]*Pairing, round int) []*Pairing {
+	var players []*Participant
+
+	if round == 1 {
+		size := 1
+		for size < len(participants) {
+			size *= 2
+		}
+
+		for _, seed := range bracketOrder(size) {
+			if seed <= len(participants) {
+				players = append(players, participants[seed-1])
+			} else {
+				players = append(players, nil)
+			}
+		}
+	} else {
+		for _, pairing := range rounds[round-2] {
+			players = append(players, pairing.winner)
+		}
+	}
+
+	if len(players) < 2 {
+		return nil
+	}
+
+	var pairings []*Pairing
+	for i := 0; i+1 < len(players); i += 2 {
+		white, black := players[i], players[i+1]
+		if white == nil || black != nil && black.seed < white.seed {
+			white, black = black, white
+		}
+
+		pairings = append(pairings, &Pairing{
+			round: round,
+			board: len(pairings) + 1,
+			white: white,
+			black: black,
+		})
+	}
+
+	return pairings
+}
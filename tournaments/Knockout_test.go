@@ -0,0 +1,130 @@
+package tournaments
+
+import (
+	"fmt"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"testing"
+)
+
+func TestBracketOrder(t *testing.T) {
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{size: 1, want: []int{1}},
+		{size: 2, want: []int{1, 2}},
+		{size: 4, want: []int{1, 4, 2, 3}},
+		{size: 8, want: []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+
+	for _, test := range tests {
+		if got := bracketOrder(test.size); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("bracketOrder(%d) = %v, want %v", test.size, got, test.want)
+		}
+	}
+}
+
+func TestKnockoutRounds(t *testing.T) {
+	tests := []struct {
+		players int
+		want    int
+	}{
+		{players: 2, want: 1},
+		{players: 3, want: 2},
+		{players: 4, want: 2},
+		{players: 5, want: 3},
+		{players: 8, want: 3},
+		{players: 9, want: 4},
+	}
+
+	for _, test := range tests {
+		if got := knockoutRounds(test.players); got != test.want {
+			t.Errorf("knockoutRounds(%d) = %d, want %d", test.players, got, test.want)
+		}
+	}
+}
+
+func TestPairKnockout(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		winners []int // the seeds winning the matches of the first round, in board order
+		round   int
+		want    []string
+	}{
+		{name: "full bracket", players: 4, round: 1, want: []string{"p1-p4", "p2-p3"}},
+		{name: "byes for the top seeds", players: 5, round: 1, want: []string{"p1-bye", "p4-p5", "p2-bye", "p3-bye"}},
+		{name: "second round pairs neighbouring winners", players: 4, winners: []int{4, 2}, round: 2, want: []string{"p2-p4"}},
+		{name: "second round after byes", players: 5, winners: []int{1, 5, 2, 3}, round: 2, want: []string{"p1-p5", "p2-p3"}},
+		{name: "final", players: 2, winners: []int{2}, round: 2, want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			participants := newParticipants(test.players)
+
+			var rounds [][]*Pairing
+			if test.winners != nil {
+				first := pairKnockout(participants, nil, 1)
+				for i, seed := range test.winners {
+					first[i].winner = participants[seed-1]
+				}
+				rounds = append(rounds, first)
+			}
+
+			got := describePairings(pairKnockout(participants, rounds, test.round))
+
+			if !equalPairings(got, test.want) {
+				t.Errorf("pairings = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemovedKnockoutGame(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(games *game.Manager, pairing Pairing, tournament *Tournament) *game.Game
+	}{
+		{
+			name: "match game",
+			remove: func(games *game.Manager, pairing Pairing, tournament *Tournament) *game.Game {
+				return games.GetGame(pairing.GameId())
+			},
+		},
+		{
+			name: "armageddon game",
+			remove: func(games *game.Manager, pairing Pairing, tournament *Tournament) *game.Game {
+				match := games.GetGame(pairing.GameId())
+				match.OfferDraw(constants.White)
+				match.AcceptDraw(constants.Black)
+
+				pairings, _ := tournament.Pairings(1)
+				return games.GetGame(pairings[0].ArmageddonGameId())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, games, tournament := startTournament(t, constants.Knockout, 4)
+
+			pairings, _ := tournament.Pairings(1)
+			removed, played := pairings[0], pairings[1]
+
+			m.HandleGameRemoved(test.remove(games, removed, tournament))
+			games.GetGame(played.GameId()).Resign(constants.Black)
+
+			if tournament.CurrentRound() != 2 {
+				t.Fatalf("round = %d, want the final to start", tournament.CurrentRound())
+			}
+
+			// the player with white in the match advances, as the black player of the armageddon game would
+			pairings, _ = tournament.Pairings(1)
+			if pairings[0].Winner() != removed.White() {
+				t.Errorf("winner = %v, want %s", pairings[0].Winner(), removed.White().Name())
+			}
+		})
+	}
+}
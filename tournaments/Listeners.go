@@ -0,0 +1,15 @@
+package tournaments
+
+type TournamentListener func(tournament *Tournament)
+
+// OnTournamentUpdated registers a listener that is called after players joined or withdrew, a round was
+// paired or a result was recorded. It is called without holding the lock of the tournament.
+func (m *Manager) OnTournamentUpdated(listener TournamentListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (t *Tournament) notifyUpdated() {
+	for _, listener := range t.manager.listeners {
+		listener(t)
+	}
+}
@@ -0,0 +1,115 @@
+package tournaments
+
+import (
+	"github.com/racccoooon/chess-be/game"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Manager struct {
+	games       *game.Manager
+	tournaments map[Id]*Tournament
+	listeners   []TournamentListener
+	mutex       sync.RWMutex
+}
+
+func NewManager(games *game.Manager) *Manager {
+	return &Manager{
+		games:       games,
+		tournaments: make(map[Id]*Tournament),
+	}
+}
+
+func (m *Manager) NewTournament(settings Settings) *Tournament {
+	tournament := &Tournament{
+		settings:   settings,
+		createTime: time.Now(),
+		pairings:   make(map[game.Id]*Pairing),
+		manager:    m,
+	}
+
+	m.mutex.Lock()
+	tournament.id = m.newTournamentId()
+	m.tournaments[tournament.id] = tournament
+	m.mutex.Unlock()
+
+	return tournament
+}
+
+func (m *Manager) GetTournament(id Id) *Tournament {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.tournaments[id]
+}
+
+// GetTournaments returns all tournaments, newest first.
+func (m *Manager) GetTournaments() []*Tournament {
+	m.mutex.RLock()
+	tournaments := make([]*Tournament, 0, len(m.tournaments))
+	for _, tournament := range m.tournaments {
+		tournaments = append(tournaments, tournament)
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(tournaments, func(i, j int) bool {
+		return tournaments[i].createTime.After(tournaments[j].createTime)
+	})
+
+	return tournaments
+}
+
+// RecordResult scores the game if it belongs to a tournament. It is meant to be registered as a game ended
+// listener of the game manager.
+func (m *Manager) RecordResult(g *game.Game) {
+	for _, tournament := range m.GetTournaments() {
+		tournament.mutex.Lock()
+		pairing, ok := tournament.pairings[g.Id()]
+		if ok {
+			tournament.recordResult(pairing, g.Id(), g.Result())
+		}
+		tournament.mutex.Unlock()
+
+		if ok {
+			tournament.notifyUpdated()
+		}
+	}
+}
+
+// HandleGameRemoved scores a removed game that did not end. It is meant to be registered as a game removed
+// listener of the game manager.
+func (m *Manager) HandleGameRemoved(g *game.Game) {
+	if g.IsOver() {
+		return
+	}
+
+	for _, tournament := range m.GetTournaments() {
+		tournament.mutex.Lock()
+		pairing, ok := tournament.pairings[g.Id()]
+		if ok {
+			tournament.abandon(pairing, g.Id())
+		}
+		tournament.mutex.Unlock()
+
+		if ok {
+			tournament.notifyUpdated()
+		}
+	}
+}
+
+func (m *Manager) newTournamentId() Id {
+	var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+	for {
+		b := make([]rune, 8)
+		for i := range b {
+			b[i] = letters[rand.Intn(len(letters))]
+		}
+
+		if _, ok := m.tournaments[Id(b)]; !ok {
+			return Id(b)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package tournaments
+
+// bergerRounds is the number of rounds of a single round-robin. With an odd number of players
+// everybody sits out once.
+func bergerRounds(players int) int {
+	if players%2 == 1 {
+		return players
+	}
+
+	return players - 1
+}
+
+// pairBerger pairs a round of a round-robin with the Berger tables, using the seeds as Berger numbers.
+// With an odd number of players the highest number is a bye. The second cycle of a double round-robin
+// repeats the first one with reversed colors.
+func pairBerger(participants []*Participant, round int) []*Pairing {
+	n := len(participants)
+	if n%2 == 1 {
+		n++
+	}
+
+	cycleRound := (round-1)%(n-1) + 1
+	secondCycle := round > n-1
+
+	// number returns the participant with the Berger number, or nil for the bye
+	number := func(number int) *Participant {
+		if number > len(participants) {
+			return nil
+		}
+
+		return participants[number-1]
+	}
+
+	// the player whose number doubled gives the round number plays the last number
+	last := 0
+	for k := 1; k < n; k++ {
+		if (2*k)%(n-1) == (cycleRound+1)%(n-1) {
+			last = k
+		}
+	}
+
+	// the last number has black in odd and white in even rounds
+	var pairs [][2]int
+	if cycleRound%2 == 1 {
+		pairs = append(pairs, [2]int{last, n})
+	} else {
+		pairs = append(pairs, [2]int{n, last})
+	}
+
+	// all other players meet the player whose number adds up to the round number, the lower number
+	// has white if the sum is odd
+	for i := 1; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if i == last || j == last || (i+j)%(n-1) != (cycleRound+1)%(n-1) {
+				continue
+			}
+
+			if (i+j)%2 == 1 {
+				pairs = append(pairs, [2]int{i, j})
+			} else {
+				pairs = append(pairs, [2]int{j, i})
+			}
+		}
+	}
+
+	var pairings []*Pairing
+	var bye *Pairing
+
+	for _, pair := range pairs {
+		white, black := number(pair[0]), number(pair[1])
+		if secondCycle {
+			white, black = black, white
+		}
+
+		if white == nil || black == nil {
+			player := white
+			if player == nil {
+				player = black
+			}
+
+			bye = &Pairing{round: round, white: player}
+			continue
+		}
+
+		pairings = append(pairings, &Pairing{
+			round: round,
+			board: len(pairings) + 1,
+			white: white,
+			black: black,
+		})
+	}
+
+	if bye != nil {
+		bye.board = len(pairings) + 1
+		pairings = append(pairings, bye)
+	}
+
+	return pairings
+}
@@ -0,0 +1,78 @@
+package tournaments
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestPairBerger(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		round   int
+		want    []string
+	}{
+		// the Berger tables for four and six players
+		{name: "4 players, round 1", players: 4, round: 1, want: []string{"p1-p4", "p2-p3"}},
+		{name: "4 players, round 2", players: 4, round: 2, want: []string{"p4-p3", "p1-p2"}},
+		{name: "4 players, round 3", players: 4, round: 3, want: []string{"p2-p4", "p3-p1"}},
+		{name: "6 players, round 1", players: 6, round: 1, want: []string{"p1-p6", "p2-p5", "p3-p4"}},
+		{name: "6 players, round 2", players: 6, round: 2, want: []string{"p6-p4", "p5-p3", "p1-p2"}},
+		{name: "6 players, round 3", players: 6, round: 3, want: []string{"p2-p6", "p3-p1", "p4-p5"}},
+		{name: "6 players, round 4", players: 6, round: 4, want: []string{"p6-p5", "p1-p4", "p2-p3"}},
+		{name: "6 players, round 5", players: 6, round: 5, want: []string{"p3-p6", "p4-p2", "p5-p1"}},
+		{name: "second cycle reverses the colors", players: 4, round: 4, want: []string{"p4-p1", "p3-p2"}},
+		{name: "the missing sixth player is a bye", players: 5, round: 1, want: []string{"p1-bye", "p2-p5", "p3-p4"}},
+		{name: "bye in a later round", players: 5, round: 2, want: []string{"p4-bye", "p5-p3", "p1-p2"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := describePairings(pairBerger(newParticipants(test.players), test.round))
+
+			if !equalPairings(got, test.want) {
+				t.Errorf("pairings = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPairBergerPlaysEveryoneOnce(t *testing.T) {
+	for _, players := range []int{2, 3, 4, 5, 8, 9} {
+		t.Run(fmt.Sprintf("%d players", players), func(t *testing.T) {
+			participants := newParticipants(players)
+			met := map[string]int{}
+			byes := map[string]int{}
+
+			for round := 1; round <= bergerRounds(players); round++ {
+				for _, pairing := range pairBerger(participants, round) {
+					if pairing.IsBye() {
+						byes[pairing.white.id]++
+						continue
+					}
+
+					ids := []string{pairing.white.id, pairing.black.id}
+					sort.Strings(ids)
+					met[ids[0]+"-"+ids[1]]++
+				}
+			}
+
+			if want := players * (players - 1) / 2; len(met) != want {
+				t.Errorf("%d different games, want %d", len(met), want)
+			}
+
+			for pair, count := range met {
+				if count != 1 {
+					t.Errorf("%s met %d times", pair, count)
+				}
+			}
+
+			for id, count := range byes {
+				if players%2 == 0 || count != 1 {
+					t.Errorf("%s had %d byes", id, count)
+				}
+			}
+		})
+	}
+}
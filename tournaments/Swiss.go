@@ -0,0 +1,266 @@
+package tournaments
+
+import (
+	"github.com/racccoooon/chess-be/constants"
+	"sort"
+)
+
+// maxPairingAttempts bounds the backtracking of pairRanked, which takes exponential time in the worst case.
+// A round that is not paired within the attempts ends the tournament.
+const maxPairingAttempts = 10000
+
+const (
+	noPreference       = 0
+	mildPreference     = 1
+	strongPreference   = 2
+	absolutePreference = 3
+)
+
+type colorPreference struct {
+	color    int
+	strength int
+}
+
+// pairSwiss pairs a round with the Dutch system. Players are ranked by score and seed, each score group is split
+// in halves and the top half plays the bottom half. Players who can not be paired in their group float down.
+// Opponents never meet twice and nobody gets a color that breaks an absolute color preference. If the pairing
+// fails with one player getting the bye, the next one in line gets it.
+func pairSwiss(players []*Participant, round int) ([]*Pairing, bool) {
+	ranked := append([]*Participant(nil), players...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+
+		return ranked[i].seed < ranked[j].seed
+	})
+
+	if len(ranked)%2 == 0 {
+		return pairingsForRound(ranked, nil, round)
+	}
+
+	// the bye goes to the lowest ranked player who did not have one yet, as long as the others can still be paired
+	for _, allowSecondBye := range []bool{false, true} {
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if ranked[i].hadBye && !allowSecondBye {
+				continue
+			}
+
+			rest := append(append([]*Participant(nil), ranked[:i]...), ranked[i+1:]...)
+			if pairings, ok := pairingsForRound(rest, ranked[i], round); ok {
+				return pairings, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func pairingsForRound(ranked []*Participant, bye *Participant, round int) ([]*Pairing, bool) {
+	pairs, ok := pairRanked(ranked)
+	if !ok {
+		return nil, false
+	}
+
+	var pairings []*Pairing
+	for i, pair := range pairs {
+		white, black := allocateColors(pair[0], pair[1], i+1)
+		pairings = append(pairings, &Pairing{
+			round: round,
+			board: i + 1,
+			white: white,
+			black: black,
+		})
+	}
+
+	if bye != nil {
+		pairings = append(pairings, &Pairing{
+			round: round,
+			board: len(pairings) + 1,
+			white: bye,
+		})
+	}
+
+	return pairings, true
+}
+
+// pairingSearch is the state of pairRanked.
+type pairingSearch struct {
+	attempts int
+}
+
+// pairRanked pairs the highest ranked player with the first acceptable opponent in Dutch order and backtracks
+// when the remaining players can not be paired. A search that runs out of attempts finds no pairing, the same
+// as a round that can not be paired at all.
+func pairRanked(ranked []*Participant) ([][2]*Participant, bool) {
+	search := &pairingSearch{}
+	return search.pair(ranked, nil)
+}
+
+func (s *pairingSearch) pair(ranked []*Participant, paired [][2]*Participant) ([][2]*Participant, bool) {
+	if len(ranked) == 0 {
+		return paired, true
+	}
+
+	if hasOddGroup(ranked) {
+		return nil, false
+	}
+
+	first := ranked[0]
+
+	for _, i := range dutchOrder(ranked) {
+		opponent := ranked[i]
+		if !canMeet(first, opponent) {
+			continue
+		}
+
+		if s.attempts >= maxPairingAttempts {
+			return nil, false
+		}
+		s.attempts++
+
+		rest := append(append([]*Participant(nil), ranked[1:i]...), ranked[i+1:]...)
+		if pairs, ok := s.pair(rest, append(paired, [2]*Participant{first, opponent})); ok {
+			return pairs, true
+		}
+	}
+
+	return nil, false
+}
+
+// hasOddGroup tells whether some of the players can only meet each other and are an odd number, so one of them
+// is always left over. Checking this prunes most of the searches that can not succeed.
+func hasOddGroup(ranked []*Participant) bool {
+	group := make([]bool, len(ranked))
+
+	for start := range ranked {
+		if group[start] {
+			continue
+		}
+
+		size := 0
+		group[start] = true
+		next := []int{start}
+
+		for len(next) > 0 {
+			i := next[len(next)-1]
+			next = next[:len(next)-1]
+			size++
+
+			for j := range ranked {
+				if !group[j] && canMeet(ranked[i], ranked[j]) {
+					group[j] = true
+					next = append(next, j)
+				}
+			}
+		}
+
+		if size%2 == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dutchOrder lists the indexes of the possible opponents of the first player. The preferred opponent is the first
+// player of the bottom half of the score group, followed by the rest of the bottom half, the top half from the
+// bottom up and finally the players of the lower score groups.
+func dutchOrder(ranked []*Participant) []int {
+	groupSize := 1
+	for groupSize < len(ranked) && ranked[groupSize].score == ranked[0].score {
+		groupSize++
+	}
+
+	var order []int
+
+	// a player alone in their score group floats down right away
+	if groupSize > 1 {
+		half := groupSize / 2
+		for i := half; i < groupSize; i++ {
+			order = append(order, i)
+		}
+		for i := half - 1; i >= 1; i-- {
+			order = append(order, i)
+		}
+	}
+
+	for i := groupSize; i < len(ranked); i++ {
+		order = append(order, i)
+	}
+
+	return order
+}
+
+func canMeet(a *Participant, b *Participant) bool {
+	for _, opponent := range a.opponents {
+		if opponent == b {
+			return false
+		}
+	}
+
+	preferenceA, preferenceB := preferenceOf(a), preferenceOf(b)
+
+	return preferenceA.strength != absolutePreference || preferenceB.strength != absolutePreference ||
+		preferenceA.color != preferenceB.color
+}
+
+// preferenceOf derives the color a player should get next from the colors they had so far. A player who had
+// one color twice more often than the other, or twice in a row, has to get the other color.
+func preferenceOf(participant *Participant) colorPreference {
+	played := len(participant.colors)
+	if played == 0 {
+		return colorPreference{strength: noPreference}
+	}
+
+	difference := 0
+	for _, color := range participant.colors {
+		if color == constants.White {
+			difference++
+		} else {
+			difference--
+		}
+	}
+
+	last := participant.colors[played-1]
+	twiceInARow := played >= 2 && participant.colors[played-2] == last
+
+	switch {
+	case difference > 1 || twiceInARow && last == constants.White:
+		return colorPreference{color: constants.Black, strength: absolutePreference}
+	case difference < -1 || twiceInARow && last == constants.Black:
+		return colorPreference{color: constants.White, strength: absolutePreference}
+	case difference == 1:
+		return colorPreference{color: constants.Black, strength: strongPreference}
+	case difference == -1:
+		return colorPreference{color: constants.White, strength: strongPreference}
+	}
+
+	return colorPreference{color: constants.GetOppositeColor(last), strength: mildPreference}
+}
+
+// allocateColors returns white and black for a pair whose first player is ranked higher. The stronger color
+// preference wins and the higher ranked player wins ties. Without any preferences the higher ranked player
+// gets white on odd boards.
+func allocateColors(higher *Participant, lower *Participant, board int) (*Participant, *Participant) {
+	preferenceHigher, preferenceLower := preferenceOf(higher), preferenceOf(lower)
+
+	if preferenceHigher.strength == noPreference && preferenceLower.strength == noPreference {
+		if board%2 == 1 {
+			return higher, lower
+		}
+
+		return lower, higher
+	}
+
+	preference, preferring, other := preferenceHigher, higher, lower
+	if preferenceLower.strength > preferenceHigher.strength {
+		preference, preferring, other = preferenceLower, lower, higher
+	}
+
+	if preference.color == constants.White {
+		return preferring, other
+	}
+
+	return other, preferring
+}
@@ -0,0 +1,190 @@
+package tournaments
+
+import (
+	"fmt"
+	"github.com/racccoooon/chess-be/constants"
+	"sort"
+	"testing"
+)
+
+// newParticipants returns players with the ids p1, p2, ... seeded in that order.
+func newParticipants(count int) []*Participant {
+	participants := make([]*Participant, count)
+	for i := range participants {
+		participants[i] = &Participant{id: fmt.Sprintf("p%d", i+1), name: fmt.Sprintf("Player %d", i+1), seed: i + 1}
+	}
+
+	return participants
+}
+
+// describePairings lists the pairings as "white-black", or "white-bye" for a bye, sorted so the order of the
+// boards does not matter.
+func describePairings(pairings []*Pairing) []string {
+	var described []string
+	for _, pairing := range pairings {
+		if pairing.IsBye() {
+			described = append(described, pairing.white.id+"-bye")
+			continue
+		}
+
+		described = append(described, pairing.white.id+"-"+pairing.black.id)
+	}
+
+	sort.Strings(described)
+
+	return described
+}
+
+func equalPairings(got []string, want []string) bool {
+	sort.Strings(want)
+
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
+
+func TestPairSwiss(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		scores  []float64   // by seed, zero if empty
+		met     [][2]int    // seeds who played each other already
+		colors  map[int]int // seeds who had one color twice in a row
+		hadBye  []int
+		want    []string
+	}{
+		{
+			name:    "top half plays bottom half, alternating colors",
+			players: 6,
+			want:    []string{"p1-p4", "p5-p2", "p3-p6"},
+		},
+		{
+			name:    "the lowest ranked player gets the bye",
+			players: 5,
+			want:    []string{"p1-p3", "p4-p2", "p5-bye"},
+		},
+		{
+			name:    "nobody gets a second bye",
+			players: 5,
+			hadBye:  []int{5},
+			want:    []string{"p1-p3", "p5-p2", "p4-bye"},
+		},
+		{
+			name:    "score groups are paired first",
+			players: 4,
+			scores:  []float64{0, 1, 0, 1},
+			want:    []string{"p2-p4", "p3-p1"},
+		},
+		{
+			name:    "opponents do not meet twice",
+			players: 4,
+			met:     [][2]int{{1, 3}, {2, 4}},
+			want:    []string{"p4-p1", "p3-p2"},
+		},
+		{
+			name:    "players with the same absolute color preference do not meet",
+			players: 4,
+			colors:  map[int]int{1: constants.White, 3: constants.White},
+			want:    []string{"p4-p1", "p2-p3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			participants := newParticipants(test.players)
+
+			for i, score := range test.scores {
+				participants[i].score = score
+			}
+
+			for _, pair := range test.met {
+				recordGame(participants[pair[0]-1], participants[pair[1]-1], constants.White, 0.5)
+				recordGame(participants[pair[1]-1], participants[pair[0]-1], constants.Black, 0.5)
+			}
+
+			for seed, color := range test.colors {
+				participants[seed-1].colors = []int{color, color}
+			}
+
+			for _, seed := range test.hadBye {
+				participants[seed-1].hadBye = true
+			}
+
+			pairings, ok := pairSwiss(participants, 1)
+			if !ok {
+				t.Fatal("no pairing found")
+			}
+
+			if got := describePairings(pairings); !equalPairings(got, test.want) {
+				t.Errorf("pairings = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPairRankedWithoutPairing(t *testing.T) {
+	participants := newParticipants(2)
+	recordGame(participants[0], participants[1], constants.White, 1)
+	recordGame(participants[1], participants[0], constants.Black, 0)
+
+	if pairs, ok := pairRanked(participants); ok {
+		t.Errorf("pairs = %v, want none for players who met", pairs)
+	}
+}
+
+// playedAllOthers lets each of the top seeds play everyone else but not each other, so they can only be paired
+// among themselves.
+func playedAllOthers(participants []*Participant, topSeeds int) {
+	group, others := participants[:topSeeds], participants[topSeeds:]
+
+	// the colors alternate, so no color preference rules out a pairing
+	for _, a := range group {
+		for i, b := range others {
+			color := constants.White
+			if i%2 == 1 {
+				color = constants.Black
+			}
+
+			recordGame(a, b, color, 0.5)
+			recordGame(b, a, constants.GetOppositeColor(color), 0.5)
+		}
+	}
+
+	for _, participant := range participants {
+		participant.score = 0
+	}
+}
+
+// TestPairRankedWithOddGroup builds a round without a valid pairing that takes too long to find by trying:
+// the 13 top seeds would have to be paired among themselves, which their odd number prevents.
+func TestPairRankedWithOddGroup(t *testing.T) {
+	participants := newParticipants(24)
+	playedAllOthers(participants, 13)
+
+	if pairs, ok := pairRanked(participants); ok {
+		t.Errorf("pairs = %v, want none", pairs)
+	}
+}
+
+// TestPairSwissMovesTheBye has the lowest ranked players unable to take the bye, because the 13 top seeds
+// can only be paired if one of them gets it.
+func TestPairSwissMovesTheBye(t *testing.T) {
+	participants := newParticipants(23)
+	playedAllOthers(participants, 13)
+
+	pairings, ok := pairSwiss(participants, 1)
+	if !ok {
+		t.Fatal("no pairing found")
+	}
+
+	for _, pairing := range pairings {
+		if pairing.IsBye() {
+			if pairing.white.id != "p13" {
+				t.Errorf("%s got the bye, want p13", pairing.white.id)
+			}
+			continue
+		}
+
+		if !canMeet(pairing.white, pairing.black) {
+			t.Errorf("%s and %s met before", pairing.white.id, pairing.black.id)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package tournaments
+
+import (
+	"errors"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotRegistering    = errors.New("the tournament does not accept players anymore")
+	ErrAlreadyRegistered = errors.New("the player is already registered")
+	ErrNotRegistered     = errors.New("the player is not registered")
+	ErrNotEnoughPlayers  = errors.New("a tournament needs at least two players")
+	ErrAlreadyStarted    = errors.New("the tournament was already started")
+)
+
+type Id string
+
+// Settings describe a tournament when it is created.
+type Settings struct {
+	Name        string
+	Format      int
+	Rounds      int
+	TimeControl game.TimeControl
+	Rated       bool
+	OwnerId     string
+}
+
+type Tournament struct {
+	id       Id
+	settings Settings
+
+	status     int
+	round      int
+	createTime time.Time
+
+	participants []*Participant
+	rounds       [][]*Pairing
+	pairings     map[game.Id]*Pairing
+
+	manager *Manager
+	mutex   sync.Mutex
+}
+
+// Participant is a registered player and their progress in the tournament.
+type Participant struct {
+	id        string
+	name      string
+	rating    int
+	seed      int
+	score     float64
+	withdrawn bool
+
+	colors    []int
+	opponents []*Participant
+	results   []float64
+	hadBye    bool
+}
+
+func (p *Participant) Id() string {
+	return p.id
+}
+
+func (p *Participant) Name() string {
+	return p.name
+}
+
+func (p *Participant) Rating() int {
+	return p.rating
+}
+
+// Pairing is one board of a round. A pairing without a black player is a bye. In a knockout a pairing is
+// a match, which is decided by an armageddon game if the first game is drawn.
+type Pairing struct {
+	round  int
+	board  int
+	white  *Participant
+	black  *Participant
+	gameId game.Id
+	result int
+
+	armageddonGameId game.Id
+	winner           *Participant
+	forfeit          bool
+}
+
+func (p *Pairing) Round() int {
+	return p.round
+}
+
+func (p *Pairing) Board() int {
+	return p.board
+}
+
+func (p *Pairing) White() *Participant {
+	return p.white
+}
+
+func (p *Pairing) Black() *Participant {
+	return p.black
+}
+
+func (p *Pairing) IsBye() bool {
+	return p.black == nil
+}
+
+func (p *Pairing) GameId() game.Id {
+	return p.gameId
+}
+
+func (p *Pairing) Result() int {
+	return p.result
+}
+
+func (p *Pairing) ArmageddonGameId() game.Id {
+	return p.armageddonGameId
+}
+
+// Winner returns the player who advances from a knockout match or bye, or nil while the match is undecided.
+func (p *Pairing) Winner() *Participant {
+	return p.winner
+}
+
+// IsForfeit tells whether the pairing was scored without a game because a player withdrew.
+func (p *Pairing) IsForfeit() bool {
+	return p.forfeit
+}
+
+func (p *Pairing) IsFinished() bool {
+	return p.IsBye() || p.result != constants.InProgress
+}
+
+// Standing is the place of a participant in the tournament, including the tiebreaks.
+type Standing struct {
+	Rank            int
+	PlayerId        string
+	Name            string
+	Rating          int
+	Score           float64
+	Buchholz        float64
+	SonnebornBerger float64
+	Withdrawn       bool
+
+	seed int
+}
+
+func (t *Tournament) Id() Id {
+	return t.id
+}
+
+func (t *Tournament) Name() string {
+	return t.settings.Name
+}
+
+func (t *Tournament) Format() int {
+	return t.settings.Format
+}
+
+// Rounds is the number of rounds. Round-robins and knockouts know it once the players are known.
+func (t *Tournament) Rounds() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.settings.Rounds
+}
+
+func (t *Tournament) TimeControl() game.TimeControl {
+	return t.settings.TimeControl
+}
+
+func (t *Tournament) IsRated() bool {
+	return t.settings.Rated
+}
+
+func (t *Tournament) OwnerId() string {
+	return t.settings.OwnerId
+}
+
+func (t *Tournament) CreateTime() time.Time {
+	return t.createTime
+}
+
+func (t *Tournament) Status() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.status
+}
+
+func (t *Tournament) CurrentRound() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.round
+}
+
+func (t *Tournament) PlayerCount() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.participants)
+}
+
+// Pairings returns the pairings of a round, starting at round 1, and whether the round exists.
+func (t *Tournament) Pairings(round int) ([]Pairing, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if round < 1 || round > len(t.rounds) {
+		return nil, false
+	}
+
+	pairings := make([]Pairing, len(t.rounds[round-1]))
+	for i, pairing := range t.rounds[round-1] {
+		pairings[i] = *pairing
+	}
+
+	return pairings, true
+}
+
+// Join registers a player. The rating is used to seed the player.
+func (t *Tournament) Join(playerId string, name string, rating int) error {
+	t.mutex.Lock()
+	err := t.join(playerId, name, rating)
+	t.mutex.Unlock()
+
+	if err == nil {
+		t.notifyUpdated()
+	}
+
+	return err
+}
+
+func (t *Tournament) join(playerId string, name string, rating int) error {
+	if t.status != constants.Registration {
+		return ErrNotRegistering
+	}
+
+	if t.participant(playerId) != nil {
+		return ErrAlreadyRegistered
+	}
+
+	t.participants = append(t.participants, &Participant{
+		id:     playerId,
+		name:   name,
+		rating: rating,
+	})
+
+	return nil
+}
+
+// Withdraw removes a player before the start or stops pairing them once the tournament runs.
+// A game the player is still playing is not affected.
+func (t *Tournament) Withdraw(playerId string) error {
+	t.mutex.Lock()
+	err := t.withdraw(playerId)
+	t.mutex.Unlock()
+
+	if err == nil {
+		t.notifyUpdated()
+	}
+
+	return err
+}
+
+func (t *Tournament) withdraw(playerId string) error {
+	participant := t.participant(playerId)
+	if participant == nil || participant.withdrawn {
+		return ErrNotRegistered
+	}
+
+	if t.status == constants.Registration {
+		for i, p := range t.participants {
+			if p == participant {
+				t.participants = append(t.participants[:i], t.participants[i+1:]...)
+				break
+			}
+		}
+
+		return nil
+	}
+
+	participant.withdrawn = true
+
+	return nil
+}
+
+// Start seeds the players by rating and pairs the first round.
+func (t *Tournament) Start() error {
+	t.mutex.Lock()
+	err := t.start()
+	t.mutex.Unlock()
+
+	if err == nil {
+		t.notifyUpdated()
+	}
+
+	return err
+}
+
+func (t *Tournament) start() error {
+	if t.status != constants.Registration {
+		return ErrAlreadyStarted
+	}
+
+	if len(t.participants) < 2 {
+		return ErrNotEnoughPlayers
+	}
+
+	sort.SliceStable(t.participants, func(i, j int) bool {
+		return t.participants[i].rating > t.participants[j].rating
+	})
+
+	for i, participant := range t.participants {
+		participant.seed = i + 1
+	}
+
+	switch t.settings.Format {
+	case constants.RoundRobin:
+		t.settings.Rounds = bergerRounds(len(t.participants))
+	case constants.DoubleRoundRobin:
+		t.settings.Rounds = 2 * bergerRounds(len(t.participants))
+	case constants.Knockout:
+		t.settings.Rounds = knockoutRounds(len(t.participants))
+	}
+
+	t.status = constants.Running
+	t.startNextRound()
+
+	return nil
+}
+
+// Standings ranks the participants by score, then Buchholz, then Sonneborn-Berger and finally by seed.
+func (t *Tournament) Standings() []Standing {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	standings := make([]Standing, len(t.participants))
+	for i, participant := range t.participants {
+		standings[i] = Standing{
+			PlayerId:        participant.id,
+			Name:            participant.name,
+			Rating:          participant.rating,
+			Score:           participant.score,
+			Buchholz:        buchholz(participant),
+			SonnebornBerger: sonnebornBerger(participant),
+			Withdrawn:       participant.withdrawn,
+			seed:            participant.seed,
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		if a.SonnebornBerger != b.SonnebornBerger {
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+
+		return a.seed < b.seed
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+
+	return standings
+}
+
+// buchholz is the sum of the scores of all opponents the participant played.
+func buchholz(participant *Participant) float64 {
+	var sum float64
+	for _, opponent := range participant.opponents {
+		sum += opponent.score
+	}
+
+	return sum
+}
+
+// sonnebornBerger is the sum of the scores of the beaten opponents and half the scores of the opponents drawn against.
+func sonnebornBerger(participant *Participant) float64 {
+	var sum float64
+	for i, opponent := range participant.opponents {
+		sum += participant.results[i] * opponent.score
+	}
+
+	return sum
+}
+
+func (t *Tournament) participant(playerId string) *Participant {
+	for _, participant := range t.participants {
+		if participant.id == playerId {
+			return participant
+		}
+	}
+
+	return nil
+}
+
+// startNextRound pairs the next round and creates its games. The tournament finishes after the last
+// round or when no valid pairing exists anymore.
+func (t *Tournament) startNextRound() {
+	if t.round >= t.settings.Rounds {
+		t.finish()
+		return
+	}
+
+	var pairings []*Pairing
+	ok := true
+
+	switch t.settings.Format {
+	case constants.Swiss:
+		var active []*Participant
+		for _, participant := range t.participants {
+			if !participant.withdrawn {
+				active = append(active, participant)
+			}
+		}
+
+		pairings, ok = pairSwiss(active, t.round+1)
+	case constants.RoundRobin, constants.DoubleRoundRobin:
+		pairings = pairBerger(t.participants, t.round+1)
+	case constants.Knockout:
+		pairings = pairKnockout(t.participants, t.rounds, t.round+1)
+	}
+
+	if !ok || len(pairings) == 0 {
+		t.finish()
+		return
+	}
+
+	t.round++
+	t.rounds = append(t.rounds, pairings)
+
+	for _, pairing := range pairings {
+		switch {
+		case pairing.IsBye():
+			t.scoreBye(pairing)
+		case pairing.white.withdrawn || pairing.black.withdrawn:
+			t.forfeit(pairing)
+		default:
+			pairing.gameId = t.createGame(pairing.white, pairing.black, false)
+			t.pairings[pairing.gameId] = pairing
+		}
+	}
+
+	// a round without games to play is over right away
+	if t.isRoundFinished() {
+		t.startNextRound()
+	}
+}
+
+func (t *Tournament) createGame(white *Participant, black *Participant, armageddon bool) game.Id {
+	pairingGame := t.manager.games.NewGame(game.Settings{
+		FirstPlayerColor: constants.White,
+		StartingColor:    constants.White,
+		TimeControl:      t.settings.TimeControl,
+		Rated:            t.settings.Rated && !armageddon,
+		Armageddon:       armageddon,
+	})
+
+	// the first player gets FirstPlayerColor, the second one the other color
+	pairingGame.AddPlayer(white.name, white.id, "")
+	pairingGame.AddPlayer(black.name, black.id, "")
+
+	return pairingGame.Id()
+}
+
+// scoreBye gives the player without an opponent a point in a Swiss and lets them advance in a knockout.
+// In a round-robin everybody sits out equally often, so the bye is worth nothing.
+func (t *Tournament) scoreBye(pairing *Pairing) {
+	pairing.white.hadBye = true
+
+	if t.settings.Format == constants.RoundRobin || t.settings.Format == constants.DoubleRoundRobin {
+		return
+	}
+
+	pairing.winner = pairing.white
+	pairing.white.score += 1
+}
+
+// forfeit scores a pairing with a withdrawn player as a win for the other player without playing the game.
+// The players count as opponents, so they are not paired again, but no color is recorded.
+func (t *Tournament) forfeit(pairing *Pairing) {
+	switch {
+	case pairing.white.withdrawn && pairing.black.withdrawn:
+		forfeitBoth(pairing)
+		return
+	case pairing.white.withdrawn:
+		pairing.result = constants.BlackWon
+		pairing.winner = pairing.black
+	default:
+		pairing.result = constants.WhiteWon
+		pairing.winner = pairing.white
+	}
+
+	pairing.forfeit = true
+	pairing.winner.score += 1
+
+	whiteScore := scoreOf(pairing.result)
+	recordForfeit(pairing.white, pairing.black, whiteScore)
+	recordForfeit(pairing.black, pairing.white, 1-whiteScore)
+}
+
+// forfeitBoth scores a pairing that neither player played as a loss for both.
+func forfeitBoth(pairing *Pairing) {
+	pairing.result = constants.Drawn
+	// somebody has to fill the place in the next round of a knockout, where they forfeit again
+	pairing.winner = pairing.white
+	recordForfeit(pairing.white, pairing.black, 0)
+	recordForfeit(pairing.black, pairing.white, 0)
+}
+
+// abandon scores a pairing whose game was removed before it ended as a loss for both players, so the
+// round does not wait for it forever. A removed armageddon game counts as drawn, which black wins.
+func (t *Tournament) abandon(pairing *Pairing, gameId game.Id) {
+	if pairing.IsFinished() {
+		return
+	}
+
+	if gameId == pairing.armageddonGameId {
+		t.recordArmageddonResult(pairing, constants.Drawn)
+	} else {
+		forfeitBoth(pairing)
+	}
+
+	if pairing.round == t.round && t.isRoundFinished() {
+		t.startNextRound()
+	}
+}
+
+func (t *Tournament) isRoundFinished() bool {
+	for _, pairing := range t.rounds[len(t.rounds)-1] {
+		if !pairing.IsFinished() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// recordResult scores a finished game and starts the next round once all games of the round are over.
+func (t *Tournament) recordResult(pairing *Pairing, gameId game.Id, result int) {
+	if pairing.IsFinished() {
+		return
+	}
+
+	if gameId == pairing.armageddonGameId {
+		t.recordArmageddonResult(pairing, result)
+	} else {
+		whiteScore := scoreOf(result)
+		recordGame(pairing.white, pairing.black, constants.White, whiteScore)
+		recordGame(pairing.black, pairing.white, constants.Black, 1-whiteScore)
+
+		// a drawn knockout match is decided by an armageddon game, where the player who had black gets white
+		if t.settings.Format == constants.Knockout && result == constants.Drawn {
+			pairing.armageddonGameId = t.createGame(pairing.black, pairing.white, true)
+			t.pairings[pairing.armageddonGameId] = pairing
+			return
+		}
+
+		pairing.result = result
+
+		if t.settings.Format == constants.Knockout {
+			pairing.winner = pairing.white
+			if result == constants.BlackWon {
+				pairing.winner = pairing.black
+			}
+			pairing.winner.score += 1
+		} else {
+			pairing.white.score += whiteScore
+			pairing.black.score += 1 - whiteScore
+		}
+	}
+
+	if pairing.round == t.round && t.isRoundFinished() {
+		t.startNextRound()
+	}
+}
+
+// recordArmageddonResult decides a knockout match. The armageddon game is played with swapped colors
+// and black, the white player of the pairing, wins it with a draw.
+func (t *Tournament) recordArmageddonResult(pairing *Pairing, result int) {
+	pairing.winner = pairing.white
+	pairing.result = constants.WhiteWon
+	if result == constants.WhiteWon {
+		pairing.winner = pairing.black
+		pairing.result = constants.BlackWon
+	}
+
+	pairing.winner.score += 1
+}
+
+func scoreOf(result int) float64 {
+	switch result {
+	case constants.WhiteWon:
+		return 1
+	case constants.BlackWon:
+		return 0
+	}
+
+	return 0.5
+}
+
+// recordGame remembers the opponent and color of a played game for the color preferences and the tiebreaks.
+func recordGame(participant *Participant, opponent *Participant, color int, score float64) {
+	participant.colors = append(participant.colors, color)
+	participant.opponents = append(participant.opponents, opponent)
+	participant.results = append(participant.results, score)
+}
+
+// recordForfeit remembers the opponent of a game that was not played for the pairings and the tiebreaks.
+func recordForfeit(participant *Participant, opponent *Participant, score float64) {
+	participant.opponents = append(participant.opponents, opponent)
+	participant.results = append(participant.results, score)
+}
+
+func (t *Tournament) finish() {
+	t.status = constants.Finished
+}
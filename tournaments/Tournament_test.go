@@ -0,0 +1,107 @@
+package tournaments
+
+import (
+	"fmt"
+	"github.com/racccoooon/chess-be/constants"
+	"github.com/racccoooon/chess-be/game"
+	"testing"
+	"time"
+)
+
+func TestForfeitRecordsOpponents(t *testing.T) {
+	tests := []struct {
+		name           string
+		whiteWithdrawn bool
+		blackWithdrawn bool
+		wantResult     int
+		wantWhiteScore float64
+		wantBlackScore float64
+	}{
+		{name: "white withdrew", whiteWithdrawn: true, wantResult: constants.BlackWon, wantBlackScore: 1},
+		{name: "black withdrew", blackWithdrawn: true, wantResult: constants.WhiteWon, wantWhiteScore: 1},
+		{name: "both withdrew", whiteWithdrawn: true, blackWithdrawn: true, wantResult: constants.Drawn},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			participants := newParticipants(2)
+			white, black := participants[0], participants[1]
+			white.withdrawn, black.withdrawn = test.whiteWithdrawn, test.blackWithdrawn
+
+			pairing := &Pairing{round: 1, board: 1, white: white, black: black}
+			(&Tournament{}).forfeit(pairing)
+
+			if pairing.result != test.wantResult {
+				t.Errorf("result = %d, want %d", pairing.result, test.wantResult)
+			}
+
+			if white.score != test.wantWhiteScore || black.score != test.wantBlackScore {
+				t.Errorf("scores = %v and %v, want %v and %v", white.score, black.score, test.wantWhiteScore, test.wantBlackScore)
+			}
+
+			if canMeet(white, black) || canMeet(black, white) {
+				t.Error("the players of a forfeit may meet again")
+			}
+
+			if len(white.colors) != 0 || len(black.colors) != 0 {
+				t.Error("a forfeit must not count for the colors")
+			}
+		})
+	}
+}
+
+func startTournament(t *testing.T, format int, players int) (*Manager, *game.Manager, *Tournament) {
+	games := game.NewGameManager()
+	m := NewManager(games)
+	games.OnGameEnded(m.RecordResult)
+
+	tournament := m.NewTournament(Settings{Format: format, Rounds: 3, TimeControl: game.NewTimeControl(5*time.Minute, 0)})
+	for i := 1; i <= players; i++ {
+		if err := tournament.Join(fmt.Sprintf("p%d", i), fmt.Sprintf("Player %d", i), 2000-i); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+	}
+
+	if err := tournament.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	return m, games, tournament
+}
+
+func TestRemovedGameIsForfeitedByBoth(t *testing.T) {
+	tests := []struct {
+		name   string
+		format int
+	}{
+		{name: "swiss", format: constants.Swiss},
+		{name: "round robin", format: constants.RoundRobin},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, games, tournament := startTournament(t, test.format, 4)
+
+			pairings, _ := tournament.Pairings(1)
+			played, removed := pairings[0], pairings[1]
+
+			games.GetGame(played.GameId()).Resign(constants.Black)
+			m.HandleGameRemoved(games.GetGame(removed.GameId()))
+			// a removed game that ended was already scored
+			m.HandleGameRemoved(games.GetGame(played.GameId()))
+
+			if tournament.CurrentRound() != 2 {
+				t.Fatalf("round = %d, want the next round to start", tournament.CurrentRound())
+			}
+
+			pairings, _ = tournament.Pairings(1)
+			if pairings[1].Result() != constants.Drawn || removed.White().score != 0 || removed.Black().score != 0 {
+				t.Errorf("removed game scored %d with %v and %v, want a loss for both", pairings[1].Result(), removed.White().score, removed.Black().score)
+			}
+
+			if played.White().score != 1 || played.Black().score != 0 {
+				t.Errorf("played game scored %v and %v, want 1 and 0", played.White().score, played.Black().score)
+			}
+		})
+	}
+}